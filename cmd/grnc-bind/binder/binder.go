@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/graniticio/granitic/v2/config"
 	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/ioc"
 	"github.com/graniticio/granitic/v2/logging"
 	"github.com/graniticio/granitic/v2/types"
 	"os"
@@ -28,6 +29,10 @@ const (
 	templateFieldAlias  = "ct"
 	typeField           = "type"
 	typeFieldAlias      = "t"
+	scopeField          = "scope"
+	autowireField       = "autowire"
+	conditionField      = "if"
+	conditionNegation   = "!"
 	nestedName          = "name"
 
 	protoSuffix = "Proto"
@@ -110,6 +115,12 @@ func SettingsFromArgs() (Settings, error) {
 
 }
 
+// The Go expressions written to bindings files for each non-singleton scope
+var scopeConstants = map[ioc.Scope]string{
+	ioc.PrototypeScope: "ioc.PrototypeScope",
+	ioc.RequestScope:   "ioc.RequestScope",
+}
+
 const defaultValuePattern = "(.*)\\((.*)\\)"
 
 // Binder translates the components defined in component definition files into Go source code.
@@ -479,12 +490,18 @@ func (b *Binder) writeComponent(w *bufio.Writer, name string, component map[stri
 		return
 	}
 
-	b.writeComponentNameComment(w, name, baseIndent)
-	b.writeInstanceVar(w, name, component[typeField].(string), baseIndent)
-	b.writeProto(w, name, index, baseIndent)
+	scope, ok := b.componentScope(component, name)
+
+	if !ok {
+		return
+	}
 
 	for field, value := range component {
 
+		if b.reservedFieldName(field) {
+			continue
+		}
+
 		if b.isPromise(value) {
 
 			log.LogDebugf("%s.%s has a config promise %v", name, field, value)
@@ -506,7 +523,18 @@ func (b *Binder) writeComponent(w *bufio.Writer, name string, component map[stri
 
 	}
 
-	b.writeValues(w, name, values, baseIndent)
+	b.writeComponentNameComment(w, name, baseIndent)
+
+	if scope == ioc.SingletonScope {
+		b.writeInstanceVar(w, name, component[typeField].(string), baseIndent)
+		b.writeProto(w, name, index, baseIndent)
+		b.writeValues(w, name, values, baseIndent)
+	} else {
+		b.writeScopedProto(w, name, component[typeField].(string), scope, values, index, baseIndent)
+	}
+
+	b.writeCondition(w, name, component, baseIndent)
+	b.writeAutowire(w, name, component, baseIndent)
 	b.writeConfPromises(w, name, confPromises, baseIndent)
 	b.writeDependencies(w, name, refs, baseIndent)
 
@@ -846,6 +874,126 @@ func (b *Binder) writeProto(w *bufio.Writer, n string, index int, tabs int) {
 	w.WriteString(b.tabIndent(s, tabs))
 }
 
+// writeScopedProto writes a factory function that creates and sets the direct values on new instances of a prototype
+// or request scoped component, then uses that factory to create the component's proto
+func (b *Binder) writeScopedProto(w *bufio.Writer, n string, ct string, scope ioc.Scope, values map[string]interface{}, index int, tabs int) {
+
+	p := b.protoName(n)
+
+	s := fmt.Sprintf("%s := ioc.CreateScopedProtoComponent(func() interface{} {\n", p)
+	w.WriteString(b.tabIndent(s, tabs))
+
+	b.writeInstanceVar(w, n, ct, tabs+1)
+	b.writeValues(w, n, values, tabs+1)
+
+	s = fmt.Sprintf("return %s\n", n)
+	w.WriteString(b.tabIndent(s, tabs+1))
+
+	s = fmt.Sprintf("}, %s, %s)\n", b.quoteString(n), scopeConstants[scope])
+	w.WriteString(b.tabIndent(s, tabs))
+
+	s = fmt.Sprintf("%s[%d] = %s\n", protoArrayVar, index, p)
+	w.WriteString(b.tabIndent(s, tabs))
+}
+
+// writeCondition makes the component's inclusion in the container conditional on configuration, if the component definition
+// has an 'if' field
+func (b *Binder) writeCondition(w *bufio.Writer, cName string, component map[string]interface{}, tabs int) {
+
+	v := component[conditionField]
+
+	if v == nil {
+		return
+	}
+
+	path, negated, err := b.parseCondition(v)
+
+	if err != nil {
+		b.Log.LogErrorf("Component %s has an invalid '%s' field: %s\n", cName, conditionField, err.Error())
+		b.fail()
+		return
+	}
+
+	s := fmt.Sprintf("%s.SetCondition(%s, %t)\n", b.protoName(cName), b.quoteString(path), negated)
+	w.WriteString(newline)
+	w.WriteString(b.tabIndent(s, tabs))
+}
+
+// parseCondition extracts the config path from a condition of the form [!]conf:path
+func (b *Binder) parseCondition(v interface{}) (path string, negated bool, err error) {
+
+	s, found := v.(string)
+
+	if !found {
+		return "", false, fmt.Errorf("the value must be a string, but is %T", v)
+	}
+
+	if strings.HasPrefix(s, conditionNegation) {
+		negated = true
+		s = s[len(conditionNegation):]
+	}
+
+	if !b.isPromise(s) {
+		return "", false, fmt.Errorf("%s is not a reference to a config path (e.g. conf:Features.Enabled)", s)
+	}
+
+	return b.stripRepOrConffMarker(s), negated, nil
+}
+
+// writeAutowire requests autowiring by type if the component definition has enabled it
+func (b *Binder) writeAutowire(w *bufio.Writer, cName string, component map[string]interface{}, tabs int) {
+
+	v := component[autowireField]
+
+	if v == nil {
+		return
+	}
+
+	enabled, found := v.(bool)
+
+	if !found {
+		b.Log.LogErrorf("Component %s has an '%s' field defined but the value of the field is not a bool.\n", cName, autowireField)
+		b.fail()
+		return
+	}
+
+	if !enabled {
+		return
+	}
+
+	s := fmt.Sprintf("%s.AutowireByType = true\n", b.protoName(cName))
+	w.WriteString(newline)
+	w.WriteString(b.tabIndent(s, tabs))
+}
+
+// componentScope finds the scope declared for a component (singleton if no scope is declared).
+func (b *Binder) componentScope(component map[string]interface{}, name string) (ioc.Scope, bool) {
+
+	v := component[scopeField]
+
+	if v == nil {
+		return ioc.SingletonScope, true
+	}
+
+	label, found := v.(string)
+
+	if !found {
+		b.Log.LogErrorf("Component %s has a '%s' field defined but the value of the field is not a string.\n", name, scopeField)
+		b.fail()
+		return ioc.SingletonScope, false
+	}
+
+	scope, err := ioc.ScopeFromLabel(label)
+
+	if err != nil {
+		b.Log.LogErrorf("Component %s has an invalid '%s' field: %s\n", name, scopeField, err.Error())
+		b.fail()
+		return ioc.SingletonScope, false
+	}
+
+	return scope, true
+}
+
 func (b *Binder) writeEntryFunctionClose(w *bufio.Writer) {
 	a := fmt.Sprintf("\treturn ioc.NewProtoComponents(%s, %s, &%s)\n}\n", protoArrayVar, modifierVar, serialisedVar)
 	w.WriteString(a)
@@ -878,7 +1026,7 @@ func (b *Binder) isRef(v interface{}) bool {
 }
 
 func (b *Binder) reservedFieldName(f string) bool {
-	return f == templateField || f == templateFieldAlias || f == typeField || f == typeFieldAlias
+	return f == templateField || f == templateFieldAlias || f == typeField || f == typeFieldAlias || f == scopeField || f == autowireField || f == conditionField
 }
 
 func (b *Binder) validateTypeAvailable(v map[string]interface{}, name string) bool {
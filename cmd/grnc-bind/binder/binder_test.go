@@ -2,6 +2,8 @@ package binder
 
 import (
 	"fmt"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
 	"testing"
 )
 
@@ -112,3 +114,50 @@ func TestCheckConfDetection(t *testing.T) {
 	}
 
 }
+
+func TestComponentScope(t *testing.T) {
+
+	b := new(Binder)
+	b.Log = new(logging.ConsoleErrorLogger)
+
+	if s, ok := b.componentScope(map[string]interface{}{}, "c"); !ok || s != ioc.SingletonScope {
+		t.Errorf("Expected singleton scope by default")
+	}
+
+	if s, ok := b.componentScope(map[string]interface{}{"scope": "prototype"}, "c"); !ok || s != ioc.PrototypeScope {
+		t.Errorf("Expected prototype scope")
+	}
+
+	if s, ok := b.componentScope(map[string]interface{}{"scope": "request"}, "c"); !ok || s != ioc.RequestScope {
+		t.Errorf("Expected request scope")
+	}
+
+	if _, ok := b.componentScope(map[string]interface{}{"scope": "session"}, "c"); ok || !b.Failed() {
+		t.Errorf("Expected invalid scope to fail")
+	}
+}
+
+func TestConditionParsing(t *testing.T) {
+
+	b := new(Binder)
+
+	p, n, err := b.parseCondition("conf:Features.UseStub")
+
+	if err != nil || p != "Features.UseStub" || n {
+		t.Errorf("Unexpected result %s %t %v", p, n, err)
+	}
+
+	p, n, err = b.parseCondition("!$Features.UseStub")
+
+	if err != nil || p != "Features.UseStub" || !n {
+		t.Errorf("Unexpected result %s %t %v", p, n, err)
+	}
+
+	if _, _, err = b.parseCondition("ref:other"); err == nil {
+		t.Errorf("Expected error for a condition that is not a config path")
+	}
+
+	if _, _, err = b.parseCondition(true); err == nil {
+		t.Errorf("Expected error for a condition that is not a string")
+	}
+}
@@ -173,13 +173,29 @@ func renderOutput(res *ctlResponse) {
 		return
 	}
 
-	if co.RenderHint == "COLUMNS" {
+	switch co.RenderHint {
+	case "COLUMNS":
 		columnOutput(co)
-	} else {
+	case "RAW":
+		rawOutput(co)
+	default:
 		paragraphOutput(co)
 	}
 }
 
+func rawOutput(co *commandOutcome) {
+
+	if co.OutputHeader != "" {
+		fmt.Printf("\n%s\n\n", co.OutputHeader)
+	}
+
+	for _, l := range co.OutputBody {
+		for _, s := range l {
+			fmt.Println(s)
+		}
+	}
+}
+
 func columnOutput(co *commandOutcome) {
 
 	tWidth := termWidth
@@ -0,0 +1,416 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Struct tags recognised when binding configuration to a struct
+const (
+	// The name of the configuration key that populates a field (defaults to the name of the field). A value of - means
+	// the field is never populated.
+	ConfigTag = "config"
+
+	// A value used if the configuration does not contain a value for the field and the field has its zero value. The
+	// default is interpreted as JSON if possible (e.g. 5, true, [1, 2]), otherwise it is used as a string (e.g. 5s).
+	DefaultTag = "default"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// BindError is returned when configuration cannot be bound to a struct or field and lists every path that could not be bound.
+type BindError struct {
+	Problems []string
+}
+
+// Error lists all of the paths that could not be bound
+func (be BindError) Error() string {
+	return fmt.Sprintf("Unable to bind configuration (%d problem(s) found): %s", len(be.Problems), strings.Join(be.Problems, "; "))
+}
+
+// binder populates Go values from configuration, recording every problem it encounters
+type binder struct {
+	problems []string
+
+	// Paths of strings resolved from placeholders that may be converted to bools or numbers
+	convertible map[string]bool
+}
+
+func (b *binder) problem(path string, format string, a ...interface{}) {
+	b.problems = append(b.problems, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, a...)))
+}
+
+func (b *binder) err() error {
+
+	if len(b.problems) == 0 {
+		return nil
+	}
+
+	return BindError{Problems: b.problems}
+}
+
+// bind sets the supplied (settable) value using the configuration value found at path
+func (b *binder) bind(path string, v interface{}, target reflect.Value) {
+
+	t := target.Type()
+
+	if s, found := v.(string); found && b.convertible[path] && scalarKind(t) {
+		v = convertPlaceholderText(s)
+	}
+
+	switch {
+	case t == durationType:
+		b.bindDuration(path, v, target)
+		return
+	case t == urlType:
+		b.bindURL(path, v, target)
+		return
+	case reflect.PtrTo(t).Implements(textUnmarshalerType) && JSONType(v) == JSONString:
+		if err := target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(v.(string))); err != nil {
+			b.problem(path, "%s", err)
+		}
+		return
+	case reflect.PtrTo(t).Implements(jsonUnmarshalerType):
+		b.bindJSON(path, v, target)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if s, found := v.(string); found {
+			target.SetString(s)
+		} else {
+			b.mismatch(path, v, t)
+		}
+
+	case reflect.Bool:
+		if bv, found := v.(bool); found {
+			target.SetBool(bv)
+		} else {
+			b.mismatch(path, v, t)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.bindInt(path, v, target)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b.bindUint(path, v, target)
+
+	case reflect.Float32, reflect.Float64:
+		if f, found := v.(float64); found && !target.OverflowFloat(f) {
+			target.SetFloat(f)
+		} else {
+			b.mismatch(path, v, t)
+		}
+
+	case reflect.Struct:
+		if o, found := v.(map[string]interface{}); found {
+			b.bindStruct(path, o, target)
+		} else {
+			b.mismatch(path, v, t)
+		}
+
+	case reflect.Ptr:
+		if target.IsNil() {
+			target.Set(reflect.New(t.Elem()))
+		}
+
+		b.bind(path, v, target.Elem())
+
+	case reflect.Slice:
+		if a, found := v.([]interface{}); found {
+			b.bindSlice(path, a, target)
+		} else {
+			b.bindJSON(path, v, target)
+		}
+
+	case reflect.Map:
+		if o, found := v.(map[string]interface{}); found && t.Key().Kind() == reflect.String {
+			b.bindMap(path, o, target)
+		} else {
+			b.bindJSON(path, v, target)
+		}
+
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			target.Set(reflect.ValueOf(v))
+		} else {
+			b.problem(path, "cannot populate a field of interface type %s", t)
+		}
+
+	default:
+		b.bindJSON(path, v, target)
+	}
+}
+
+// bindStruct populates the exported fields of a struct from a configuration object. Fields are matched to keys using
+// the config tag, then the json tag, then the field's name. If no key matches exactly, a case-insensitive match is
+// used (as encoding/json does).
+func (b *binder) bindStruct(path string, o map[string]interface{}, target reflect.Value) {
+
+	t := target.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+
+		sf := t.Field(i)
+
+		if sf.PkgPath != "" {
+			// Unexported
+			continue
+		}
+
+		key, tagged := fieldKey(sf)
+
+		if key == "-" {
+			continue
+		}
+
+		fv := target.Field(i)
+
+		if sf.Anonymous && !tagged && sf.Type.Kind() == reflect.Struct {
+			// Fields of embedded structs are populated from the same object
+			b.bindStruct(path, o, fv)
+			continue
+		}
+
+		fieldPath := childPath(path, key)
+		v := lookupKey(o, key)
+
+		if v == nil {
+			b.applyDefault(fieldPath, sf, fv)
+			continue
+		}
+
+		b.bind(fieldPath, v, fv)
+	}
+}
+
+// applyDefault sets a field with no configuration value to the value of its default tag (if it has one and the field
+// has its zero value). Nested structs are visited so that their defaults are applied.
+func (b *binder) applyDefault(path string, sf reflect.StructField, fv reflect.Value) {
+
+	d, found := sf.Tag.Lookup(DefaultTag)
+
+	if !found {
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != urlType && !reflect.PtrTo(sf.Type).Implements(jsonUnmarshalerType) {
+			b.bindStruct(path, map[string]interface{}{}, fv)
+		}
+
+		return
+	}
+
+	if !fv.IsZero() {
+		return
+	}
+
+	var v interface{} = d
+
+	if fv.Kind() != reflect.String {
+
+		var parsed interface{}
+
+		if err := json.Unmarshal([]byte(d), &parsed); err == nil {
+			v = parsed
+		}
+	}
+
+	nb := new(binder)
+	nb.bind(path, v, fv)
+
+	for _, p := range nb.problems {
+		b.problems = append(b.problems, p+" (from the default tag)")
+	}
+}
+
+func (b *binder) bindSlice(path string, a []interface{}, target reflect.Value) {
+
+	s := reflect.MakeSlice(target.Type(), len(a), len(a))
+
+	for i, e := range a {
+
+		ep := fmt.Sprintf("%s[%d]", path, i)
+
+		if e == nil {
+			continue
+		}
+
+		b.bind(ep, e, s.Index(i))
+	}
+
+	target.Set(s)
+}
+
+func (b *binder) bindMap(path string, o map[string]interface{}, target reflect.Value) {
+
+	t := target.Type()
+
+	if target.IsNil() {
+		target.Set(reflect.MakeMap(t))
+	}
+
+	for k, e := range o {
+
+		ev := reflect.New(t.Elem()).Elem()
+
+		if e != nil {
+			b.bind(childPath(path, k), e, ev)
+		}
+
+		target.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), ev)
+	}
+}
+
+func (b *binder) bindInt(path string, v interface{}, target reflect.Value) {
+
+	f, found := v.(float64)
+
+	// Consistent with IntVal, non-integral numbers are truncated
+	if !found || f > math.MaxInt64 || f < math.MinInt64 || target.OverflowInt(int64(f)) {
+		b.mismatch(path, v, target.Type())
+		return
+	}
+
+	target.SetInt(int64(f))
+}
+
+func (b *binder) bindUint(path string, v interface{}, target reflect.Value) {
+
+	f, found := v.(float64)
+
+	if !found || f < 0 || f > math.MaxUint64 || target.OverflowUint(uint64(f)) {
+		b.mismatch(path, v, target.Type())
+		return
+	}
+
+	target.SetUint(uint64(f))
+}
+
+// bindDuration accepts strings in the format understood by time.ParseDuration (e.g. 250ms, 1h30m) or a number of nanoseconds
+func (b *binder) bindDuration(path string, v interface{}, target reflect.Value) {
+
+	switch d := v.(type) {
+	case string:
+		pd, err := time.ParseDuration(d)
+
+		if err != nil {
+			b.problem(path, "%q is not a valid duration (e.g. 250ms, 5s, 1h30m)", d)
+			return
+		}
+
+		target.SetInt(int64(pd))
+
+	case float64:
+		b.bindInt(path, v, target)
+
+	default:
+		b.mismatch(path, v, target.Type())
+	}
+}
+
+func (b *binder) bindURL(path string, v interface{}, target reflect.Value) {
+
+	s, found := v.(string)
+
+	if !found {
+		b.mismatch(path, v, target.Type())
+		return
+	}
+
+	u, err := url.Parse(s)
+
+	if err != nil {
+		b.problem(path, "%q is not a valid URL: %s", s, err)
+		return
+	}
+
+	target.Set(reflect.ValueOf(*u))
+}
+
+// bindJSON populates types without specific support by converting the configuration value back to JSON and unmarshalling it
+func (b *binder) bindJSON(path string, v interface{}, target reflect.Value) {
+
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		b.problem(path, "%s", err)
+		return
+	}
+
+	if err := json.Unmarshal(data, target.Addr().Interface()); err != nil {
+
+		if _, found := err.(*json.UnmarshalTypeError); found {
+			b.mismatch(path, v, target.Type())
+		} else {
+			b.problem(path, "%s", err)
+		}
+	}
+}
+
+func (b *binder) mismatch(path string, v interface{}, t reflect.Type) {
+	b.problem(path, "cannot use %s as %s", describeValue(v), t)
+}
+
+// fieldKey returns the configuration key for a struct field and whether the key was set with a tag
+func fieldKey(sf reflect.StructField) (string, bool) {
+
+	if k, found := sf.Tag.Lookup(ConfigTag); found && k != "" {
+		return k, true
+	}
+
+	if j, found := sf.Tag.Lookup("json"); found {
+
+		if k := strings.Split(j, ",")[0]; k != "" {
+			return k, true
+		}
+	}
+
+	return sf.Name, false
+}
+
+// scalarKind returns true if the type is a bool or number that a string from a placeholder may be converted to
+func scalarKind(t reflect.Type) bool {
+
+	if t == durationType {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// lookupKey finds the value for a key in a configuration object, falling back to a case-insensitive match
+func lookupKey(o map[string]interface{}, key string) interface{} {
+
+	if v, found := o[key]; found {
+		return v
+	}
+
+	for _, k := range sortedKeys(o) {
+		if strings.EqualFold(k, key) {
+			return o[k]
+		}
+	}
+
+	return nil
+}
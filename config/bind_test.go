@@ -0,0 +1,199 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type clientConfig struct {
+	Endpoint url.URL
+	Timeout  time.Duration `config:"RequestTimeout" default:"5s"`
+	Started  time.Time
+	Attempts uint8  `default:"3"`
+	Mode     string `default:"fast"`
+	Retry    *retryConfig
+	Backends []backendConfig
+	Labels   map[string]string
+	Limits   map[string]time.Duration
+	Nested   nestedConfig
+	Ignored  string `config:"-"`
+}
+
+type retryConfig struct {
+	Interval time.Duration
+	Max      int `default:"10"`
+}
+
+type backendConfig struct {
+	Name   string
+	Weight int `default:"1"`
+}
+
+type nestedConfig struct {
+	Enabled bool
+	Depth   int `default:"2"`
+}
+
+func bindAccessor(t *testing.T) *Accessor {
+
+	b, err := ioutil.ReadFile(test.FilePath("bind/bind.json"))
+	test.ExpectNil(t, err)
+
+	var data map[string]interface{}
+	test.ExpectNil(t, json.Unmarshal(b, &data))
+
+	return &Accessor{JSONData: data}
+}
+
+func TestPopulateWithTagsAndDefaults(t *testing.T) {
+
+	ca := bindAccessor(t)
+
+	var cc clientConfig
+	cc.Ignored = "unchanged"
+
+	test.ExpectNil(t, ca.Populate("Client", &cc))
+
+	test.ExpectString(t, cc.Endpoint.Host, "config.example.com")
+	test.ExpectString(t, cc.Endpoint.Query().Get("env"), "prod")
+
+	if cc.Timeout != 250*time.Millisecond {
+		t.Errorf("Expected timeout of 250ms, got %v", cc.Timeout)
+	}
+
+	if !cc.Started.Equal(time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected start time %v", cc.Started)
+	}
+
+	test.ExpectInt(t, int(cc.Attempts), 3)
+	test.ExpectString(t, cc.Mode, "fast")
+	test.ExpectString(t, cc.Ignored, "unchanged")
+
+	if cc.Retry == nil || cc.Retry.Interval != 90*time.Second {
+		t.Fatalf("Expected retry interval of 90s, got %v", cc.Retry)
+	}
+
+	test.ExpectInt(t, cc.Retry.Max, 10)
+
+	test.ExpectInt(t, len(cc.Backends), 2)
+	test.ExpectString(t, cc.Backends[1].Name, "secondary")
+	test.ExpectInt(t, cc.Backends[0].Weight, 3)
+	test.ExpectInt(t, cc.Backends[1].Weight, 1)
+
+	test.ExpectString(t, cc.Labels["region"], "eu-west-1")
+
+	if cc.Limits["write"] != time.Minute {
+		t.Errorf("Expected write limit of 1m, got %v", cc.Limits["write"])
+	}
+
+	test.ExpectBool(t, cc.Nested.Enabled, true)
+	test.ExpectInt(t, cc.Nested.Depth, 2)
+}
+
+func TestDefaultsDoNotReplaceSetValues(t *testing.T) {
+
+	ca := bindAccessor(t)
+
+	cc := clientConfig{Mode: "careful"}
+
+	test.ExpectNil(t, ca.Populate("Client", &cc))
+
+	test.ExpectString(t, cc.Mode, "careful")
+}
+
+func TestBindErrorsAreAggregated(t *testing.T) {
+
+	ca := bindAccessor(t)
+
+	var cc clientConfig
+
+	err := ca.Populate("Broken", &cc)
+
+	be, found := err.(BindError)
+
+	if !found {
+		t.Fatalf("Expected a BindError, got %v", err)
+	}
+
+	expected := []string{"Broken.Endpoint", "Broken.RequestTimeout", "Broken.Started", "Broken.Attempts", "Broken.Backends[0].Weight"}
+
+	test.ExpectInt(t, len(be.Problems), len(expected))
+
+	for i, p := range expected {
+		if !strings.HasPrefix(be.Problems[i], p+":") {
+			t.Errorf("Expected problem %d to be about %s, got %s", i, p, be.Problems[i])
+		}
+	}
+
+	// Values without problems are still bound
+	test.ExpectString(t, cc.Backends[0].Name, "primary")
+}
+
+func TestInvalidDefault(t *testing.T) {
+
+	ca := bindAccessor(t)
+
+	var target struct {
+		Timeout time.Duration `default:"often"`
+	}
+
+	err := ca.Populate("Client", &target)
+
+	if err == nil || !strings.Contains(err.Error(), "Client.Timeout") || !strings.Contains(err.Error(), "default tag") {
+		t.Errorf("Expected an error about the default value, got %v", err)
+	}
+}
+
+type unmarshalingConfig struct {
+	Raw string
+}
+
+func (uc *unmarshalingConfig) UnmarshalJSON(b []byte) error {
+	uc.Raw = string(b)
+	return nil
+}
+
+func TestPopulateTargetsOtherThanStructs(t *testing.T) {
+
+	ca := bindAccessor(t)
+
+	m := make(map[string]interface{})
+	test.ExpectNil(t, ca.Populate("Client.Labels", &m))
+	test.ExpectString(t, m["region"].(string), "eu-west-1")
+
+	uc := new(unmarshalingConfig)
+	test.ExpectNil(t, ca.Populate("Client.Labels", uc))
+	test.ExpectString(t, uc.Raw, `{"region":"eu-west-1"}`)
+
+	if ca.Populate("Client.Labels", m) == nil {
+		t.Errorf("Expected an error when the target is not a pointer")
+	}
+}
+
+func TestSetFieldWithDuration(t *testing.T) {
+
+	ca := bindAccessor(t)
+
+	var cc clientConfig
+
+	test.ExpectNil(t, ca.SetField("Timeout", "Client.RequestTimeout", &cc))
+
+	if cc.Timeout != 250*time.Millisecond {
+		t.Errorf("Expected timeout of 250ms, got %v", cc.Timeout)
+	}
+
+	test.ExpectNil(t, ca.SetField("Backends", "Client.Backends", &cc))
+	test.ExpectInt(t, cc.Backends[1].Weight, 1)
+
+	test.ExpectNotNil(t, ca.SetField("Timeout", "Broken.RequestTimeout", &cc))
+	test.ExpectNotNil(t, ca.SetField("Missing", "Client.RequestTimeout", &cc))
+}
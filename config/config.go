@@ -85,7 +85,6 @@ eventual merged configuration file with a dot-delimited notation. E.g "database.
 package config
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/graniticio/granitic/v2/logging"
@@ -125,12 +124,20 @@ type Accessor struct {
 
 	// Logger used by Granitic framework components. Automatically injected.
 	FrameworkLogger logging.Logger
+
+	// Paths to values that were resolved from placeholders (see ResolvePlaceholders). These values are redacted when
+	// configuration is displayed and are converted to numbers or bools when bound to fields of those types.
+	PlaceholderPaths []string
+
+	// The Provenance of each non-object value, keyed by configuration path (see JSONMerger.Sources).
+	Sources map[string]*Provenance
 }
 
 // Flush removes internal references to the (potentially very large) merged JSON data so the associated
 // memory can be recovered during the next garbage collection.
 func (ac *Accessor) Flush() {
 	ac.JSONData = nil
+	ac.Sources = nil
 }
 
 // PathExists check to see whether the supplied dot-delimited path exists in the configuration and points to a non-null JSON value.
@@ -191,7 +198,7 @@ func (ac *Accessor) StringVal(path string) (string, error) {
 // or cannot be converted to an int.
 func (ac *Accessor) IntVal(path string) (int, error) {
 
-	v := ac.Value(path)
+	v := ac.convertedValue(path)
 
 	if v == nil {
 		return 0, errors.New("No such path " + path)
@@ -206,7 +213,7 @@ func (ac *Accessor) IntVal(path string) (int, error) {
 // Float64Val returns the float64 value of the JSON number at the supplied path. An error will be returned if the value is not a JSON number.
 func (ac *Accessor) Float64Val(path string) (float64, error) {
 
-	v := ac.Value(path)
+	v := ac.convertedValue(path)
 
 	if v == nil {
 		return 0, errors.New("No such path " + path)
@@ -237,7 +244,7 @@ func (ac *Accessor) Array(path string) ([]interface{}, error) {
 // Note this method only suports the JSON definition of bools (true, false) not the Go definition (true, false, 1, 0 etc).
 func (ac *Accessor) BoolVal(path string) (bool, error) {
 
-	v := ac.Value(path)
+	v := ac.convertedValue(path)
 
 	if v == nil {
 		return false, errors.New("No such path " + path)
@@ -251,6 +258,31 @@ func (ac *Accessor) BoolVal(path string) (bool, error) {
 
 }
 
+// convertedValue returns the value at the supplied path, converting text that was resolved from a placeholder to a number
+// or bool if it represents one.
+func (ac *Accessor) convertedValue(path string) interface{} {
+
+	v := ac.Value(path)
+
+	if s, found := v.(string); found && ac.placeholderSet()[path] {
+		return convertPlaceholderText(s)
+	}
+
+	return v
+}
+
+// placeholderSet returns PlaceholderPaths as a set
+func (ac *Accessor) placeholderSet() map[string]bool {
+
+	set := make(map[string]bool, len(ac.PlaceholderPaths))
+
+	for _, p := range ac.PlaceholderPaths {
+		set[p] = true
+	}
+
+	return set
+}
+
 // JSONType determines the apparent JSONType of the supplied Go interface.
 func JSONType(value interface{}) int {
 
@@ -286,9 +318,9 @@ func (ac *Accessor) configVal(path []string, jsonMap map[string]interface{}) int
 }
 
 // SetField takes a target Go interface and uses the data a the supplied path to populated the named field on the
-// target. The target must be a pointer to a struct. The field must be a string, bool, int, float63, string[interface{}] map
-// or a slice of one of those types. An eror will be returned if the target field, is missing, not settable or incompatible
-// with the JSON value at the supplied path.
+// target. The target must be a pointer to a struct. See Populate for the types of field that are supported. Maps are
+// limited to values that are JSON strings, numbers, bools or objects or non-empty arrays of strings. An error will be returned
+// if the target field is missing, not settable or incompatible with the JSON value at the supplied path.
 func (ac *Accessor) SetField(fieldName string, path string, target interface{}) error {
 
 	if !ac.PathExists(path) {
@@ -298,58 +330,26 @@ func (ac *Accessor) SetField(fieldName string, path string, target interface{})
 	targetReflect := reflect.ValueOf(target).Elem()
 	targetField := targetReflect.FieldByName(fieldName)
 
-	k := targetField.Type().Kind()
-
-	switch k {
-	case reflect.String:
-		s, _ := ac.StringVal(path)
-		targetField.SetString(s)
-	case reflect.Bool:
-		b, _ := ac.BoolVal(path)
-		targetField.SetBool(b)
-	case reflect.Int:
-		i, _ := ac.IntVal(path)
-		targetField.SetInt(int64(i))
-	case reflect.Float64:
-		f, _ := ac.Float64Val(path)
-		targetField.SetFloat(f)
-	case reflect.Map:
-
-		if v, err := ac.ObjectVal(path); err == nil {
-			if err = ac.populateMapField(targetField, v); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
-	case reflect.Slice:
-		ac.populateSlice(targetField, path, target)
-
-	default:
-		m := fmt.Sprintf("Unable to use value at path %s as target field %s is not a suppported type (%s)", path, fieldName, k)
-		ac.FrameworkLogger.LogErrorf(m)
-
+	if !targetField.IsValid() || !targetField.CanSet() {
+		m := fmt.Sprintf("Unable to use value at path %s as %T does not have a settable field %s", path, target, fieldName)
 		return errors.New(m)
 	}
 
-	return nil
-}
-
-func (ac *Accessor) populateSlice(targetField reflect.Value, path string, target interface{}) {
-
-	v := ac.Value(path)
+	if targetField.Kind() == reflect.Map {
 
-	data, _ := json.Marshal(v)
+		v, err := ac.ObjectVal(path)
 
-	vt := targetField.Type()
-	nt := reflect.New(vt)
+		if err != nil {
+			return err
+		}
 
-	jTarget := nt.Interface()
-	json.Unmarshal(data, &jTarget)
+		return ac.populateMapField(targetField, v)
+	}
 
-	vr := reflect.ValueOf(jTarget)
-	targetField.Set(vr.Elem())
+	b := &binder{convertible: ac.placeholderSet()}
+	b.bind(path, ac.Value(path), targetField)
 
+	return b.err()
 }
 
 func (ac *Accessor) populateMapField(targetField reflect.Value, contents map[string]interface{}) error {
@@ -408,9 +408,33 @@ func (ac *Accessor) arrayVal(a reflect.Value) (reflect.Value, error) {
 	return s, nil
 }
 
-// Populate sets the fields on the supplied target object using the JSON data
-// at the supplied path. This is achieved using Go's json.Marshal to convert the data
-// back into text JSON and then json.Unmarshal to unmarshal back into the target.
+/*
+Populate sets the fields on the supplied target object (normally a pointer to a struct) using the JSON object at the supplied path.
+Each exported field is populated from the key named by the field's config tag, json tag or name (in that order of
+preference). If no key matches exactly, a case-insensitive match is used. A field with no value in configuration is set
+to the value of its default tag (if it has one and the field has its zero value). For example:
+
+	type ClientConfig struct {
+		Endpoint url.URL
+		Timeout  time.Duration `config:"RequestTimeout" default:"5s"`
+		Retries  int           `default:"3"`
+		Backends []Backend
+	}
+
+As well as strings, bools, numbers, maps, slices, structs and pointers to those types, the following are supported:
+
+	time.Duration: a string understood by time.ParseDuration (e.g. "250ms") or a number of nanoseconds
+	time.Time: an RFC 3339 string (e.g. "2020-01-02T15:04:05Z")
+	url.URL: a string that can be parsed as a URL
+	Types implementing encoding.TextUnmarshaler (from a string) or json.Unmarshaler
+
+Numbers are truncated if bound to an integer field (consistent with IntVal). Strings resolved from placeholders (see
+ResolvePlaceholders) are converted if they are bound to bool or numeric fields. If any values cannot be bound, every other
+value is still bound and a BindError listing the path of each problem is returned.
+
+If the target is a pointer to a type other than a struct (e.g. a map) or implements json.Unmarshaler, the JSON object is
+converted back to JSON text and unmarshalled into the target with encoding/json.
+*/
 func (ac *Accessor) Populate(path string, target interface{}) error {
 	exists := ac.PathExists(path)
 
@@ -418,16 +442,27 @@ func (ac *Accessor) Populate(path string, target interface{}) error {
 		return errors.New("No such path: " + path)
 	}
 
-	//Already check if path exists
-	object, _ := ac.ObjectVal(path)
+	object, err := ac.ObjectVal(path)
 
-	if data, err := json.Marshal(object); err != nil {
-		m := fmt.Sprintf("%T cannot be marshalled to JSON", object)
+	if err != nil {
+		return err
+	}
+
+	tv := reflect.ValueOf(target)
+
+	if tv.Kind() != reflect.Ptr || tv.IsNil() {
+		m := fmt.Sprintf("Unable to populate %T from configuration at %s. Target must be a non-nil pointer", target, path)
 		return errors.New(m)
-	} else if json.Unmarshal(data, target); err != nil {
-		return fmt.Errorf("%T cannot be populated with %v to JSON", object, data)
 	}
 
-	return nil
+	b := &binder{convertible: ac.placeholderSet()}
+
+	if tv.Elem().Kind() == reflect.Struct && !tv.Type().Implements(jsonUnmarshalerType) {
+		b.bindStruct(path, object, tv.Elem())
+	} else {
+		// Maps, other types and types with their own UnmarshalJSON are populated with a JSON round-trip
+		b.bindJSON(path, object, tv.Elem())
+	}
 
+	return b.err()
 }
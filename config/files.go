@@ -105,9 +105,10 @@ func FileListFromPath(path string) ([]string, error) {
 	return files, nil
 }
 
-// WriteJSONConfig writes the contents of a config.Accessor to a file
+// WriteJSONConfig writes the contents of a config.Accessor to a file. Values at any of the Accessor's PlaceholderPaths are
+// replaced with RedactedValue.
 func WriteJSONConfig(a *Accessor, f string) error {
-	if content, err := json.MarshalIndent(a.JSONData, "", " "); err != nil {
+	if content, err := json.MarshalIndent(redact(a.JSONData, a.PlaceholderPaths), "", " "); err != nil {
 		return err
 	} else if err = ioutil.WriteFile(f, content, 0644); err != nil {
 		return err
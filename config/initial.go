@@ -27,6 +27,15 @@ type InitialSettings struct {
 	// Files, directories and URLs from which JSON configuration should be loaded and merged.
 	Configuration []string
 
+	// Controls authentication, timeouts, retries and caching when loading configuration from URLs.
+	RemoteConfig *RemoteSettings
+
+	// The profiles (e.g. dev, prod) that were used to select configuration files, in the order in which they were applied.
+	Profiles []string
+
+	// Files and folders in layered configuration folders that were not loaded because their profile was not selected.
+	IgnoredConfiguration []string
+
 	// The time at which the application was started (to allow accurate timing of the IoC container start process).
 	StartTime time.Time
 
@@ -36,9 +45,13 @@ type InitialSettings struct {
 	// A base 64 serialised version of Granitic's built-in configuration files
 	BuiltInConfig *string
 
-	// Additional parsers to support config files in a format other than JSON
+	// Additional parsers to support config files in a format other than JSON, YAML or TOML
 	ConfigParsers []ContentParser
 
+	// Schemas describing the application's own configuration. Merged configuration is checked against these schemas
+	// (and the schemas of enabled facilities) before facilities are built.
+	ConfigSchemas []*Schema
+
 	// Exit immediately after container has successfully started
 	DryRun bool
 
@@ -48,8 +61,16 @@ type InitialSettings struct {
 	// A path to write the merged view of configuration to (then exit)
 	MergedConfigPath string
 
+	// Whether each value in the merged view of configuration should be annotated with the file or URL it came from and
+	// the values it overrode
+	AnnotateMergedConfig bool
+
 	// Create a UUID and use it as the instance ID
 	GenerateInstanceUUID bool
+
+	// A path to write a graph of the populated IoC container to (then exit). Written as Graphviz DOT if the path
+	// ends in .dot or .gv, otherwise as JSON.
+	ComponentGraphPath string
 }
 
 // InitialSettingsFromEnvironment builds an InitialSettings and populates it with defaults or the values of command line
@@ -78,7 +99,14 @@ func processCommandLineArgs(is *InitialSettings) {
 	instanceID := flag.String("i", "", "A unique identifier for this instance of the application")
 	deferLogging := flag.Bool("d", false, "Defer logging messages from until application logging is configured")
 	mergeFile := flag.String("m", "", "Path to a file to write merged view of config then exit")
+	annotateMerged := flag.Bool("a", false, "Annotate each value written with -m with the file or URL it came from and the values it overrode")
 	uuidInstanceID := flag.Bool("u", false, "Use a generated UUID as the instance ID for this application")
+	graphFile := flag.String("g", "", "Path to a file to write a graph of populated components to (DOT if the path ends in .dot or .gv, otherwise JSON) then exit")
+	remoteTimeout := flag.Duration("remote-timeout", DefaultRemoteTimeout, "Maximum time to wait for each request for configuration from a URL")
+	remoteRetries := flag.Int("remote-retries", 0, "Number of times to retry a request for configuration from a URL if the server is unavailable")
+	remoteRetryInterval := flag.Duration("remote-retry-interval", DefaultRemoteRetryInterval, "Time to wait before the first retry of a request for configuration from a URL (doubles after each retry)")
+	remoteCache := flag.String("remote-cache", "", "Directory in which to keep copies of configuration loaded from URLs, used if a URL is unavailable")
+	profileList := flag.String("p", "", "Comma separated list of configuration profiles to apply (overrides "+ProfileEnvVar+")")
 
 	flag.Parse()
 
@@ -98,29 +126,90 @@ func processCommandLineArgs(is *InitialSettings) {
 		instance.ExitError()
 	}
 
+	if *profileList == "" {
+		*profileList = os.Getenv(ProfileEnvVar)
+	}
+
+	profiles, err := ParseProfiles(*profileList)
+
+	if err != nil {
+		fmt.Println(err)
+		instance.ExitError()
+	}
+
 	paths := strings.Split(*configFilePtr, ",")
-	userConfig, err := ExpandToFilesAndURLs(paths)
+	userConfig, ignored, err := expandToFilesAndURLs(paths, profiles)
 
 	if err != nil {
 		fmt.Println(err)
 		instance.ExitError()
 	}
 
+	token := os.Getenv(RemoteTokenEnvVar)
+	tokenHost := os.Getenv(RemoteTokenHostEnvVar)
+
+	if token != "" && tokenHost == "" {
+
+		if tokenHost, err = defaultTokenHost(userConfig); err != nil {
+			fmt.Println(err)
+			instance.ExitError()
+		}
+	}
+
 	is.Configuration = append(is.Configuration, userConfig...)
+	is.Profiles = profiles
+	is.IgnoredConfiguration = ignored
+	is.RemoteConfig = &RemoteSettings{
+		BearerToken:     token,
+		BearerTokenHost: tokenHost,
+		Timeout:         *remoteTimeout,
+		Retries:         *remoteRetries,
+		RetryInterval:   *remoteRetryInterval,
+		CacheDir:        *remoteCache,
+	}
 	is.FrameworkLogLevel = ll
 	is.InstanceID = *instanceID
 	is.DeferBootstrapLogging = *deferLogging
 	is.MergedConfigPath = *mergeFile
+	is.AnnotateMergedConfig = *annotateMerged
 	is.GenerateInstanceUUID = *uuidInstanceID
+	is.ComponentGraphPath = *graphFile
 
 }
 
-// ExpandToFilesAndURLs takes a slice that may be a mixture of URLs, file paths
-// and directory paths and returns a list of URLs and file paths, recursively expanding any non-empty
-// directories into a list of files. Returns an error if there is a problem traversing directories of if any of the
-// supplied file paths does not exist.
-func ExpandToFilesAndURLs(paths []string) ([]string, error) {
+/*
+ExpandToFilesAndURLs takes a slice that may be a mixture of URLs, file paths
+and directory paths and returns a list of URLs and file paths, recursively expanding any non-empty
+directories into a list of files. Returns an error if there is a problem traversing directories of if any of the
+supplied file paths does not exist.
+
+If one or more profiles are supplied and a supplied directory contains files or folders named base, local or
+profile-<name> (files may have any extension) the directory is treated as layered and its contents are returned in
+this order:
+
+	1. Any other files and folders in the directory
+	2. base
+	3. profile-<name> for each of the supplied profiles, in the order the profiles were supplied
+	4. local
+
+profile-<name> files and folders for profiles that were not supplied are ignored. An error is returned if a supplied
+profile does not have a profile-<name> file or folder in any of the supplied directories.
+
+If no profiles are supplied, directories are never treated as layered and all of their files are returned.
+*/
+func ExpandToFilesAndURLs(paths []string, profiles ...string) ([]string, error) {
+
+	files, _, err := expandToFilesAndURLs(paths, profiles)
+
+	return files, err
+}
+
+// expandToFilesAndURLs behaves as ExpandToFilesAndURLs, but also returns the files and folders in layered directories
+// that were ignored because their profile was not supplied.
+func expandToFilesAndURLs(paths []string, profiles []string) ([]string, []string, error) {
 	files := make([]string, 0)
+	ignored := make([]string, 0)
+	found := make(map[string]bool)
 
 	for _, path := range paths {
 
@@ -129,17 +218,42 @@ func ExpandToFilesAndURLs(paths []string) ([]string, error) {
 			continue
 		}
 
-		expanded, err := FileListFromPath(path)
+		var expanded []string
+		var err error
+
+		if len(profiles) > 0 && folderExists(path) && isLayered(path) {
+
+			var skipped []string
+			var layers map[string]bool
+
+			expanded, skipped, layers, err = layeredFiles(path, profiles)
+
+			for p := range layers {
+				found[p] = true
+			}
+
+			ignored = append(ignored, skipped...)
+
+		} else {
+			expanded, err = FileListFromPath(path)
+		}
 
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		files = append(files, expanded...)
 
 	}
 
-	return files, nil
+	for _, p := range profiles {
+
+		if !found[p] {
+			return nil, nil, fmt.Errorf("profile %s was selected but none of %s contain a file or folder named %s%s", p, strings.Join(paths, ", "), profileLayerPrefix, p)
+		}
+	}
+
+	return files, ignored, nil
 }
 
 func isURL(u string) bool {
@@ -4,14 +4,12 @@
 package config
 
 import (
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/graniticio/granitic/v2/instance"
 	"github.com/graniticio/granitic/v2/logging"
 	"io/ioutil"
-	"net/http"
+	neturl "net/url"
 	"strings"
 )
 
@@ -38,9 +36,20 @@ func (efe EmptyFileError) Error() string {
 type JSONContentParser struct {
 }
 
-// ParseInto takes a byte array that is assumed to be serialised JSON and attempts to parse that into the supplied target object
+// ParseInto takes a byte array that is assumed to be serialised JSON and attempts to parse that into the supplied target object.
+// Syntax and type errors are returned as a ParseError including the line on which the problem was found.
 func (jcp *JSONContentParser) ParseInto(data []byte, target interface{}) error {
-	return json.Unmarshal(data, &target)
+
+	err := json.Unmarshal(data, &target)
+
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return newParseError(lineOfOffset(data, e.Offset), e.Error())
+	case *json.UnmarshalTypeError:
+		return newParseError(lineOfOffset(data, e.Offset), e.Error())
+	}
+
+	return err
 }
 
 // Extensions returns the list of filename extensions (lowercase, without leading dot) that will be considered to be JSON files.
@@ -89,8 +98,37 @@ type JSONMerger struct {
 
 	DefaultParser ContentParser
 
+	// Controls how configuration is loaded from URLs. If nil, default timeouts are used with no authentication,
+	// retries or caching.
+	Remote *RemoteSettings
+
+	// Configuration merged, in order, over the base configuration passed to LoadAndMergeConfigWithBase before any files or
+	// URLs are loaded.
+	Layers []*Layer
+
+	// A description of where the base configuration passed to LoadAndMergeConfigWithBase came from, recorded as the
+	// source of values in the base configuration.
+	BaseSource string
+
 	parserByFile    map[string]ContentParser
 	parserByContent map[string]ContentParser
+	sources         map[string]*Provenance
+}
+
+// A Layer is configuration that is not loaded from a file or URL (for example the default configuration of a facility
+// that is not built in to Granitic).
+type Layer struct {
+	// A description of where the configuration came from, recorded as the source of each of its values.
+	Source string
+
+	// The configuration in object form.
+	Config map[string]interface{}
+}
+
+// Sources returns the Provenance of every non-object value in the configuration created by the most recent
+// call to LoadAndMergeConfig or LoadAndMergeConfigWithBase, keyed by configuration path.
+func (jm *JSONMerger) Sources() map[string]*Provenance {
+	return jm.sources
 }
 
 // LoadAndMergeConfig takes a list of file paths or URIs to JSON files and merges them into a single in-memory object representation.
@@ -126,6 +164,16 @@ func (jm *JSONMerger) LoadAndMergeConfigWithBase(config map[string]interface{},
 	var jsonData []byte
 	var err error
 
+	jm.sources = make(map[string]*Provenance)
+
+	for k, v := range config {
+		recordSources(jm.sources, k, v, jm.BaseSource)
+	}
+
+	for _, l := range jm.Layers {
+		config = jm.merge(config, l.Config, "", l.Source)
+	}
+
 	for _, fileName := range files {
 
 		var cp ContentParser
@@ -154,6 +202,10 @@ func (jm *JSONMerger) LoadAndMergeConfigWithBase(config map[string]interface{},
 		}
 
 		if err != nil {
+			if isURL(fileName) {
+				return nil, fmt.Errorf("Problem loading configuration from URL %s: %s", fileName, err)
+			}
+
 			return nil, fmt.Errorf("Problem reading data from file/URL %s: %s", fileName, err)
 		}
 
@@ -165,14 +217,19 @@ func (jm *JSONMerger) LoadAndMergeConfigWithBase(config map[string]interface{},
 
 			if _, found := err.(EmptyFileError); found {
 				jm.Logger.LogWarnf("Config file/URL %s is empty", fileName)
-			} else {
-				return nil, fmt.Errorf("Problem parsing data from a file or URL (%s) as JSON : %s", fileName, err)
+				continue
 			}
+
+			return nil, fmt.Errorf("Problem parsing data from a file or URL (%s): %s", fileName, err)
 		}
 
-		additionalConfig := loadedConfig.(map[string]interface{})
+		additionalConfig, found := loadedConfig.(map[string]interface{})
+
+		if !found {
+			return nil, fmt.Errorf("Problem parsing data from a file or URL (%s): the top level of a configuration file must be an object/mapping of keys to values", fileName)
+		}
 
-		config = jm.merge(config, additionalConfig)
+		config = jm.merge(config, additionalConfig, "", fileName)
 
 	}
 
@@ -192,15 +249,28 @@ func (jm *JSONMerger) extractExtension(path string) string {
 
 func (jm *JSONMerger) loadFromURL(url string) ([]byte, ContentParser, error) {
 
-	r, err := http.Get(url)
+	r, err := jm.fetchRemote(url)
 
 	if err != nil {
-		return nil, nil, err
+
+		if re, found := err.(remoteError); !found || !re.Unavailable || jm.Remote == nil || jm.Remote.CacheDir == "" {
+			return nil, nil, err
+		}
+
+		data, cp, cerr := jm.readCache(url)
+
+		if cerr != nil {
+			return nil, nil, fmt.Errorf("%s and unable to use cached copy: %s", err, cerr)
+		}
+
+		jm.Logger.LogWarnf("Using cached copy of configuration from %s: %s", url, err)
+
+		return data, cp, nil
 	}
 
-	cp := jm.DefaultParser
+	var cp ContentParser
 
-	if ct := r.Header.Get("content-type"); ct != "" {
+	if ct := r.ContentType; ct != "" {
 		ct = strings.Split(ct, ";")[0]
 		ct = strings.TrimSpace(ct)
 		ct = strings.ToLower(ct)
@@ -212,39 +282,88 @@ func (jm *JSONMerger) loadFromURL(url string) ([]byte, ContentParser, error) {
 
 	}
 
-	if r.StatusCode >= 400 {
-		m := fmt.Sprintf("HTTP %d", r.StatusCode)
-		return nil, nil, errors.New(m)
+	// Servers often return a generic content type for YAML and TOML, so fall back to the URL's extension
+	if ext := jm.extractExtension(urlPath(url)); cp == nil && jm.parserByFile[ext] != nil {
+		jm.Logger.LogDebugf("Found content parser for extension %s", ext)
+		cp = jm.parserByFile[ext]
 	}
 
-	var b bytes.Buffer
+	if cp == nil {
+		cp = jm.DefaultParser
+	}
+
+	if jm.Remote != nil && jm.Remote.CacheDir != "" {
+
+		var parsed interface{}
+
+		// Only store configuration that can be understood
+		if cp.ParseInto(r.Body, &parsed) == nil {
 
-	b.ReadFrom(r.Body)
-	r.Body.Close()
+			if err := jm.writeCache(url, r.Body, cp); err != nil {
+				jm.Logger.LogWarnf("Unable to store cached copy of configuration from %s: %s", url, err)
+			}
+		}
+	}
 
-	return b.Bytes(), cp, nil
+	return r.Body, cp, nil
 }
 
-func (jm *JSONMerger) merge(base, additional map[string]interface{}) map[string]interface{} {
+// urlPath extracts the path component of the supplied URL
+func urlPath(u string) string {
+
+	if p, err := neturl.Parse(u); err == nil {
+		return p.Path
+	}
+
+	return u
+}
+
+// merge merges additional into base, recording the supplied source as the origin of each value from additional. path
+// is the configuration path of base.
+func (jm *JSONMerger) merge(base, additional map[string]interface{}, path, source string) map[string]interface{} {
 
 	for key, value := range additional {
 
+		p := childPath(path, key)
+
 		if existingEntry, ok := base[key]; ok {
 
 			existingEntryType := JSONType(existingEntry)
 			newEntryType := JSONType(value)
 
 			if existingEntryType == JSONMap && newEntryType == JSONMap {
-				jm.merge(existingEntry.(map[string]interface{}), value.(map[string]interface{}))
-			} else if jm.MergeArrays && existingEntryType == JSONArray && newEntryType == JSONArray {
+				jm.merge(existingEntry.(map[string]interface{}), value.(map[string]interface{}), p, source)
+				continue
+			}
+
+			previous := jm.sources[p]
+
+			if existingEntryType == JSONMap || newEntryType == JSONMap {
+				forgetSources(jm.sources, p)
+			}
+
+			if jm.MergeArrays && existingEntryType == JSONArray && newEntryType == JSONArray {
 				base[key] = jm.mergeArrays(existingEntry.([]interface{}), value.([]interface{}))
 			} else {
 				base[key] = value
 			}
+
+			recordSources(jm.sources, p, value, source)
+
+			// Objects are not recorded as overridden values, only the individual values within them
+			if previous != nil {
+				current := jm.sources[p]
+
+				if current != nil {
+					current.Overridden = append(previous.Overridden, OverriddenValue{Value: existingEntry, Source: previous.Source})
+				}
+			}
+
 		} else {
 			jm.Logger.LogTracef("Adding %s", key)
 
 			base[key] = value
+			recordSources(jm.sources, p, value, source)
 		}
 
 	}
@@ -0,0 +1,109 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func formatsMerger() *JSONMerger {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	jm := NewJSONMergerWithManagedLogging(lm, new(JSONContentParser))
+	jm.RegisterContentParser(new(YAMLContentParser))
+	jm.RegisterContentParser(new(TOMLContentParser))
+
+	return jm
+}
+
+func formatFiles(names ...string) []string {
+
+	files := make([]string, len(names))
+
+	for i, n := range names {
+		files[i] = test.FilePath(filepath.Join("formats", n))
+	}
+
+	return files
+}
+
+func TestYAMLAndTOMLMergeLikeJSON(t *testing.T) {
+
+	expected, err := formatsMerger().LoadAndMergeConfig(formatFiles("base.json", "override.json"))
+
+	if err != nil {
+		t.Fatalf("Unexpected error merging JSON: %s", err.Error())
+	}
+
+	yaml, err := formatsMerger().LoadAndMergeConfig(formatFiles("base.json", "override.yaml"))
+
+	if err != nil {
+		t.Fatalf("Unexpected error merging YAML: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(expected, yaml) {
+		t.Errorf("YAML merged differently to JSON. Expected %v, got %v", expected, yaml)
+	}
+
+	toml, err := formatsMerger().LoadAndMergeConfig(formatFiles("base.json", "override.toml"))
+
+	if err != nil {
+		t.Fatalf("Unexpected error merging TOML: %s", err.Error())
+	}
+
+	// TOML cannot represent null
+	delete(expected["App"].(map[string]interface{}), "Debug")
+
+	if !reflect.DeepEqual(expected, toml) {
+		t.Errorf("TOML merged differently to JSON. Expected %v, got %v", expected, toml)
+	}
+}
+
+func TestParseErrorsIncludeFileAndLine(t *testing.T) {
+
+	for _, n := range []string{"invalid.json", "invalid.yaml", "invalid.toml"} {
+
+		files := formatFiles(n)
+
+		_, err := formatsMerger().LoadAndMergeConfig(files)
+
+		if err == nil {
+			t.Errorf("Expected an error parsing %s", n)
+			continue
+		}
+
+		m := err.Error()
+
+		if !strings.Contains(m, files[0]) {
+			t.Errorf("Expected error to include file name %s: %s", files[0], m)
+		}
+
+		line := map[string]string{"invalid.json": "line 4:", "invalid.yaml": "line 3:", "invalid.toml": "line 4:"}[n]
+
+		if !strings.Contains(m, line) {
+			t.Errorf("Expected error to include %s: %s", line, m)
+		}
+	}
+}
+
+func TestEmptyAndNonMappingFiles(t *testing.T) {
+
+	c, err := formatsMerger().LoadAndMergeConfig(formatFiles("base.json", "empty.yaml"))
+
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, len(c), 2)
+
+	_, err = formatsMerger().LoadAndMergeConfig(formatFiles("list.yaml"))
+
+	if err == nil {
+		t.Errorf("Expected an error loading a file whose top level is a list")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// ParseError describes a problem parsing a configuration file and the line on which it occurred.
+type ParseError struct {
+	// The line (starting at 1) where the problem was found
+	Line int
+
+	// A description of the problem
+	Message string
+}
+
+// Error returns the line number and description of the problem
+func (pe ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", pe.Line, pe.Message)
+}
+
+func newParseError(line int, format string, a ...interface{}) ParseError {
+	return ParseError{Line: line, Message: fmt.Sprintf(format, a...)}
+}
+
+// assignParsed stores a document parsed by a non-JSON ContentParser in the supplied target. The document consists only
+// of the types produced by encoding/json (map[string]interface{}, []interface{}, string, float64, bool and nil) so it can
+// be assigned directly if the target is a pointer to an empty interface, otherwise it is marshalled to JSON and unmarshalled
+// into the target.
+func assignParsed(doc map[string]interface{}, target interface{}) error {
+
+	switch t := target.(type) {
+	case *interface{}:
+		*t = doc
+		return nil
+	case *map[string]interface{}:
+		*t = doc
+		return nil
+	}
+
+	b, err := json.Marshal(doc)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, target)
+}
+
+// jsonNumber converts a parsed number to the float64 representation used for JSON numbers, returning an error if the
+// number is infinite or not a number, as these cannot be represented in JSON.
+func jsonNumber(f float64, line int) (float64, error) {
+
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, newParseError(line, "infinite and NaN numbers cannot be used in configuration")
+	}
+
+	return f, nil
+}
+
+// lineOfOffset returns the line (starting at 1) that contains the supplied byte offset
+func lineOfOffset(data []byte, offset int64) int {
+
+	line := 1
+
+	for i := int64(0); i < offset && i < int64(len(data)); i++ {
+		if data[i] == '\n' {
+			line++
+		}
+	}
+
+	return line
+}
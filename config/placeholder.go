@@ -0,0 +1,241 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces the values of secrets when merged configuration is written to a file.
+const RedactedValue = "[REDACTED]"
+
+const (
+	envSource      = "env"
+	fileSource     = "file"
+	defaultMarker  = ":-"
+	escapedOpening = "$${"
+)
+
+var placeholderPattern = regexp.MustCompile(`\$\$\{|\$\{([^}:]*):([^}]*)\}`)
+
+// lookupEnv and readFile are variables to allow tests to substitute their own environment and files
+var lookupEnv = os.LookupEnv
+var readFile = ioutil.ReadFile
+
+/*
+ResolvePlaceholders replaces placeholders in the string values of merged configuration with values from environment variables
+or files. Supported placeholders are:
+
+	${env:NAME}          The value of the environment variable NAME, which must be set
+	${env:NAME:-default} The value of the environment variable NAME or 'default' if NAME is unset or empty
+	${file:/path}        The contents of the file at /path with any trailing line breaks removed
+
+Placeholders may be embedded in a longer string. Resolved values are always strings; they are only converted to numbers
+or bools when they are bound to (or validated as) a field of that type. $${ is replaced with a literal ${ and is not
+treated as the start of a placeholder.
+
+Every value containing a resolved placeholder is treated as a secret. The sorted paths of those values are returned so that
+they can be redacted if configuration is displayed and converted if they are bound to non-string fields. An error
+describing every placeholder that could not be resolved is returned if any placeholders were invalid or referred to
+missing environment variables or unreadable files.
+*/
+func ResolvePlaceholders(data map[string]interface{}) ([]string, error) {
+
+	pr := new(placeholderResolver)
+	pr.resolved = make(map[string]bool)
+
+	pr.resolveObject("", data)
+
+	if len(pr.problems) > 0 {
+		message := fmt.Sprintf("Unable to resolve placeholders in configuration: %s", strings.Join(pr.problems, "; "))
+		return nil, errors.New(message)
+	}
+
+	paths := make([]string, 0, len(pr.resolved))
+
+	for p := range pr.resolved {
+		paths = append(paths, p)
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+type placeholderResolver struct {
+	resolved map[string]bool
+	problems []string
+}
+
+func (pr *placeholderResolver) resolveObject(path string, o map[string]interface{}) {
+
+	keys := make([]string, 0, len(o))
+
+	for k := range o {
+		keys = append(keys, k)
+	}
+
+	// Sorted so that problems are reported in a predictable order
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		o[k] = pr.resolveValue(childPath(path, k), o[k])
+	}
+}
+
+func (pr *placeholderResolver) resolveValue(path string, v interface{}) interface{} {
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		pr.resolveObject(path, t)
+	case []interface{}:
+		for i, e := range t {
+			t[i] = pr.resolveValue(fmt.Sprintf("%s[%d]", path, i), e)
+		}
+	case string:
+		return pr.resolveString(path, t)
+	}
+
+	return v
+}
+
+func (pr *placeholderResolver) resolveString(path string, s string) string {
+
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+
+		if match == escapedOpening {
+			return "${"
+		}
+
+		parts := placeholderPattern.FindStringSubmatch(match)
+
+		value, err := lookupPlaceholder(parts[1], parts[2])
+
+		if err != nil {
+			pr.problems = append(pr.problems, fmt.Sprintf("%s: %s", path, err.Error()))
+			return match
+		}
+
+		pr.resolved[path] = true
+
+		return value
+	})
+}
+
+// lookupPlaceholder finds the value of a single placeholder
+func lookupPlaceholder(source, ref string) (string, error) {
+
+	switch source {
+	case envSource:
+		name := ref
+		def := ""
+		hasDefault := false
+
+		if i := strings.Index(ref, defaultMarker); i >= 0 {
+			name = ref[:i]
+			def = ref[i+len(defaultMarker):]
+			hasDefault = true
+		}
+
+		value, set := lookupEnv(name)
+
+		if hasDefault && value == "" {
+			return def, nil
+		}
+
+		if !set {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+
+		return value, nil
+
+	case fileSource:
+		content, err := readFile(ref)
+
+		if err != nil {
+			return "", fmt.Errorf("unable to read file %s: %s", ref, err.Error())
+		}
+
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+
+	return "", fmt.Errorf("%s is not a supported placeholder source. Must be %s or %s", source, envSource, fileSource)
+}
+
+// convertPlaceholderText converts text resolved from a placeholder to a number or bool if it is the JSON representation
+// of one. Any other text is returned unchanged.
+func convertPlaceholderText(s string) interface{} {
+
+	var converted interface{}
+
+	if json.Unmarshal([]byte(s), &converted) == nil {
+
+		switch converted.(type) {
+		case float64, bool:
+			return converted
+		}
+	}
+
+	return s
+}
+
+func childPath(parent, key string) string {
+
+	if parent == "" {
+		return key
+	}
+
+	return parent + JSONPathSeparator + key
+}
+
+// redact returns a copy of the supplied configuration with the values at the supplied paths replaced with RedactedValue
+func redact(data map[string]interface{}, paths []string) map[string]interface{} {
+
+	secret := make(map[string]bool)
+
+	for _, p := range paths {
+		secret[p] = true
+	}
+
+	return redactValue("", data, secret).(map[string]interface{})
+}
+
+func redactValue(path string, v interface{}, secret map[string]bool) interface{} {
+
+	if secret[path] {
+		return RedactedValue
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(t))
+
+		for k, e := range t {
+			c[k] = redactValue(childPath(path, k), e, secret)
+		}
+
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(t))
+
+		for i, e := range t {
+			c[i] = redactValue(fmt.Sprintf("%s[%d]", path, i), e, secret)
+		}
+
+		return c
+	}
+
+	return v
+}
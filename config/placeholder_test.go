@@ -0,0 +1,205 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func withEnv(env map[string]string) func() {
+
+	lookupEnv = func(name string) (string, bool) {
+		v, found := env[name]
+		return v, found
+	}
+
+	return func() {
+		lookupEnv = os.LookupEnv
+	}
+}
+
+func TestPlaceholderResolution(t *testing.T) {
+
+	defer withEnv(map[string]string{"DB_HOST": "db.local", "DB_PASSWORD": "hunter2", "DEBUG": "true", "EMPTY": "", "AGENT": "007"})()
+
+	secretFile := test.FilePath(filepath.Join("secrets", "db-password"))
+
+	data := map[string]interface{}{
+		"Database": map[string]interface{}{
+			"Host":     "${env:DB_HOST}",
+			"URL":      "postgres://${env:DB_HOST}:${env:DB_PORT:-5432}/app",
+			"Port":     "${env:DB_PORT:-5432}",
+			"User":     "${env:EMPTY:-admin}",
+			"Password": "${env:DB_PASSWORD}",
+			"Key":      "${file:" + secretFile + "}",
+		},
+		"Debug":    "${env:DEBUG}",
+		"Agent":    "${env:AGENT}",
+		"Literal":  "$${env:DB_HOST}",
+		"Replicas": []interface{}{"${env:DB_HOST}", 1.0},
+	}
+
+	paths, err := ResolvePlaceholders(data)
+
+	test.ExpectNil(t, err)
+
+	db := data["Database"].(map[string]interface{})
+
+	test.ExpectString(t, db["Host"].(string), "db.local")
+	test.ExpectString(t, db["URL"].(string), "postgres://db.local:5432/app")
+	test.ExpectString(t, db["User"].(string), "admin")
+	test.ExpectString(t, db["Key"].(string), "s3cret")
+	test.ExpectString(t, data["Literal"].(string), "${env:DB_HOST}")
+	test.ExpectString(t, data["Replicas"].([]interface{})[0].(string), "db.local")
+
+	// Resolved values remain strings until they are bound to a field of another type
+	test.ExpectString(t, db["Port"].(string), "5432")
+	test.ExpectString(t, db["Password"].(string), "hunter2")
+	test.ExpectString(t, data["Debug"].(string), "true")
+	test.ExpectString(t, data["Agent"].(string), "007")
+
+	test.ExpectString(t, strings.Join(paths, ","), "Agent,Database.Host,Database.Key,Database.Password,Database.Port,Database.URL,Database.User,Debug,Replicas[0]")
+}
+
+func TestUnresolvablePlaceholders(t *testing.T) {
+
+	defer withEnv(map[string]string{})()
+
+	data := map[string]interface{}{
+		"Database": map[string]interface{}{
+			"Password": "${env:DB_PASSWORD}",
+			"Key":      "${file:/no/such/file}",
+		},
+		"Other": []interface{}{"${vault:thing}"},
+	}
+
+	_, err := ResolvePlaceholders(data)
+
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+
+	for _, expected := range []string{"Database.Password: environment variable DB_PASSWORD is not set", "Database.Key: unable to read file /no/such/file", "Other[0]: vault is not a supported"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, was %s", expected, err.Error())
+		}
+	}
+}
+
+func TestMergedConfigRedaction(t *testing.T) {
+
+	defer withEnv(map[string]string{"API_AUTH": "abc", "DB_PASS": "1234"})()
+
+	data := map[string]interface{}{
+		"Api":     map[string]interface{}{"Token": "${env:API_AUTH}", "Region": "eu"},
+		"Servers": []interface{}{map[string]interface{}{"Token": "${env:API_AUTH}", "Password": "${env:DB_PASS}"}},
+	}
+
+	secrets, err := ResolvePlaceholders(data)
+	test.ExpectNil(t, err)
+
+	f, err := ioutil.TempFile("", "merged")
+	test.ExpectNil(t, err)
+	f.Close()
+
+	defer os.Remove(f.Name())
+
+	test.ExpectNil(t, WriteJSONConfig(&Accessor{JSONData: data, PlaceholderPaths: secrets}, f.Name()))
+
+	content, _ := ioutil.ReadFile(f.Name())
+
+	written := make(map[string]interface{})
+	test.ExpectNil(t, json.Unmarshal(content, &written))
+
+	api := written["Api"].(map[string]interface{})
+
+	test.ExpectString(t, api["Token"].(string), RedactedValue)
+	test.ExpectString(t, api["Region"].(string), "eu")
+
+	server := written["Servers"].([]interface{})[0].(map[string]interface{})
+
+	test.ExpectString(t, server["Token"].(string), RedactedValue)
+	test.ExpectString(t, server["Password"].(string), RedactedValue)
+
+	// The original data is unchanged
+	test.ExpectString(t, data["Api"].(map[string]interface{})["Token"].(string), "abc")
+}
+
+type placeholderTarget struct {
+	Port    int
+	Ratio   float64
+	Debug   bool
+	Agent   string
+	Timeout time.Duration
+}
+
+func TestPlaceholderConversionToTargetTypes(t *testing.T) {
+
+	defer withEnv(map[string]string{"PORT": "8080", "RATIO": "0.5", "DEBUG": "true", "AGENT": "007", "TIMEOUT": "10"})()
+
+	data := map[string]interface{}{
+		"Target": map[string]interface{}{
+			"Port":    "${env:PORT}",
+			"Ratio":   "${env:RATIO}",
+			"Debug":   "${env:DEBUG}",
+			"Agent":   "${env:AGENT}",
+			"Timeout": "${env:TIMEOUT}s",
+		},
+		"Literal": "8080",
+	}
+
+	paths, err := ResolvePlaceholders(data)
+	test.ExpectNil(t, err)
+
+	ca := &Accessor{JSONData: data, PlaceholderPaths: paths}
+
+	var target placeholderTarget
+
+	test.ExpectNil(t, ca.Populate("Target", &target))
+
+	test.ExpectInt(t, target.Port, 8080)
+	test.ExpectBool(t, target.Debug, true)
+	test.ExpectString(t, target.Agent, "007")
+
+	if target.Ratio != 0.5 || target.Timeout != 10*time.Second {
+		t.Errorf("Unexpected values bound %v", target)
+	}
+
+	port, err := ca.IntVal("Target.Port")
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, port, 8080)
+
+	debug, err := ca.BoolVal("Target.Debug")
+	test.ExpectNil(t, err)
+	test.ExpectBool(t, debug, true)
+
+	agent, err := ca.StringVal("Target.Agent")
+	test.ExpectNil(t, err)
+	test.ExpectString(t, agent, "007")
+
+	// Only values resolved from placeholders are converted
+	_, err = ca.IntVal("Literal")
+
+	if err == nil {
+		t.Errorf("Expected a literal string not to be converted to an int")
+	}
+
+	schema := &Schema{Path: "Target", Fields: map[string]*SchemaField{
+		"Port":  {Type: IntValue, Max: Limit(65535)},
+		"Ratio": {Type: NumberValue},
+		"Debug": {Type: BoolValue},
+		"Agent": {Type: StringValue},
+	}, AllowUnknown: true}
+
+	_, err = ca.Validate(schema)
+	test.ExpectNil(t, err)
+}
@@ -0,0 +1,162 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ProfileEnvVar is the environment variable used to select profiles if profiles are not specified with the -p command line argument.
+const ProfileEnvVar = "GRANITIC_PROFILE"
+
+// Names of the files or folders that make up the layers of a configuration folder
+const (
+	baseLayer          = "base"
+	localLayer         = "local"
+	profileLayerPrefix = "profile-"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ParseProfiles converts a comma separated list of profile names into a slice, returning an error if any of the names
+// are invalid (names may only contain letters, numbers, - and _) or appear more than once. An empty list results in an
+// empty slice.
+func ParseProfiles(list string) ([]string, error) {
+
+	profiles := make([]string, 0)
+	seen := make(map[string]bool)
+
+	if strings.TrimSpace(list) == "" {
+		return profiles, nil
+	}
+
+	for _, p := range strings.Split(list, ",") {
+
+		p = strings.TrimSpace(p)
+
+		if !profileNamePattern.MatchString(p) {
+			return nil, fmt.Errorf("%q is not a valid profile name. Profile names may only contain letters, numbers, - and _", p)
+		}
+
+		if seen[p] {
+			return nil, fmt.Errorf("profile %s has been selected more than once", p)
+		}
+
+		seen[p] = true
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+// layeredFiles returns the files in a folder that contains base, profile-<name> or local files or folders. Files and
+// folders that are not layers are returned first (in the same order as FileListFromPath) followed by the base layer,
+// the layer for each of the supplied profiles (in the order supplied) and finally the local layer. Layers for profiles that
+// have not been supplied are ignored and returned separately. The returned map records which of the supplied profiles
+// had a layer in the folder.
+func layeredFiles(dir string, profiles []string) ([]string, []string, map[string]bool, error) {
+
+	contents, err := ioutil.ReadDir(dir)
+
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to read contents of directory %s", dir)
+	}
+
+	var other, base, local, profileOrder []string
+	byProfile := make(map[string][]string)
+
+	for _, info := range contents {
+
+		p := filepath.Join(dir, info.Name())
+
+		switch layer := layerName(info.Name(), info.IsDir()); {
+		case layer == baseLayer:
+			base = append(base, p)
+		case layer == localLayer:
+			local = append(local, p)
+		case strings.HasPrefix(layer, profileLayerPrefix):
+			profile := strings.TrimPrefix(layer, profileLayerPrefix)
+
+			if byProfile[profile] == nil {
+				profileOrder = append(profileOrder, profile)
+			}
+
+			byProfile[profile] = append(byProfile[profile], p)
+		default:
+			other = append(other, p)
+		}
+	}
+
+	ordered := append(other, base...)
+	found := make(map[string]bool)
+
+	for _, profile := range profiles {
+
+		if layer, present := byProfile[profile]; present {
+			ordered = append(ordered, layer...)
+			found[profile] = true
+		}
+	}
+
+	ordered = append(ordered, local...)
+
+	var ignored []string
+
+	for _, profile := range profileOrder {
+
+		if !found[profile] {
+			ignored = append(ignored, byProfile[profile]...)
+		}
+	}
+
+	files := make([]string, 0)
+
+	for _, p := range ordered {
+
+		expanded, err := FileListFromPath(p)
+
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		files = append(files, expanded...)
+	}
+
+	return files, ignored, found, nil
+}
+
+// isLayered checks whether a folder contains any base, profile-<name> or local files or folders
+func isLayered(dir string) bool {
+
+	contents, err := ioutil.ReadDir(dir)
+
+	if err != nil {
+		return false
+	}
+
+	for _, info := range contents {
+
+		layer := layerName(info.Name(), info.IsDir())
+
+		if layer == baseLayer || layer == localLayer || strings.HasPrefix(layer, profileLayerPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// layerName returns the name of a file without its extension, or the name of a folder
+func layerName(name string, dir bool) string {
+
+	if dir {
+		return name
+	}
+
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,122 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestParseProfiles(t *testing.T) {
+
+	p, err := ParseProfiles(" prod, eu-west ")
+
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, len(p), 2)
+	test.ExpectString(t, p[0], "prod")
+	test.ExpectString(t, p[1], "eu-west")
+
+	p, err = ParseProfiles("")
+
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, len(p), 0)
+
+	for _, invalid := range []string{"prod,", "prod,prod", "../prod", "dev.eu"} {
+		if _, err := ParseProfiles(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestLayeredProfileOrder(t *testing.T) {
+
+	dir := test.FilePath("profiles")
+
+	expected := func(names ...string) []string {
+
+		paths := make([]string, len(names))
+
+		for i, n := range names {
+			paths[i] = filepath.Join(dir, n)
+		}
+
+		return paths
+	}
+
+	check := func(actual, expected []string) {
+
+		test.ExpectInt(t, len(actual), len(expected))
+
+		for i := range expected {
+			if i < len(actual) {
+				test.ExpectString(t, actual[i], expected[i])
+			}
+		}
+	}
+
+	files, err := ExpandToFilesAndURLs([]string{dir}, "prod", "dev")
+	test.ExpectNil(t, err)
+	check(files, expected("other.json", "base/a.json", "base/b.json", "profile-prod/p.json", "profile-dev.json", "local.yaml"))
+
+	files, err = ExpandToFilesAndURLs([]string{dir}, "dev", "prod")
+	test.ExpectNil(t, err)
+	check(files, expected("other.json", "base/a.json", "base/b.json", "profile-dev.json", "profile-prod/p.json", "local.yaml"))
+
+	files, ignored, err := expandToFilesAndURLs([]string{dir}, []string{"dev"})
+	test.ExpectNil(t, err)
+	check(files, expected("other.json", "base/a.json", "base/b.json", "profile-dev.json", "local.yaml"))
+	check(ignored, expected("profile-prod"))
+}
+
+func TestFoldersNotLayeredWithoutProfiles(t *testing.T) {
+
+	dir := test.FilePath("profiles")
+
+	files, err := ExpandToFilesAndURLs([]string{dir})
+	test.ExpectNil(t, err)
+
+	unlayered, err := FileListFromPath(dir)
+	test.ExpectNil(t, err)
+
+	test.ExpectInt(t, len(files), len(unlayered))
+
+	for i := range unlayered {
+		test.ExpectString(t, files[i], unlayered[i])
+	}
+}
+
+func TestExistingBaseFileMergesAsBefore(t *testing.T) {
+
+	files, err := ExpandToFilesAndURLs([]string{test.FilePath("prelayered")})
+	test.ExpectNil(t, err)
+
+	test.ExpectInt(t, len(files), 2)
+	test.ExpectString(t, filepath.Base(files[0]), "base.json")
+	test.ExpectString(t, filepath.Base(files[1]), "defaults.json")
+
+	merged, err := formatsMerger().LoadAndMergeConfig(files)
+	test.ExpectNil(t, err)
+
+	test.ExpectString(t, merged["Value"].(string), "defaults")
+	test.ExpectBool(t, merged["FromBase"].(bool), true)
+}
+
+func TestMissingProfile(t *testing.T) {
+
+	_, err := ExpandToFilesAndURLs([]string{test.FilePath("profiles"), test.FilePath("folders")}, "dev", "staging")
+
+	if err == nil {
+		t.Errorf("Expected an error when a selected profile has no configuration")
+	}
+}
+
+func TestUnlayeredFoldersIgnoreProfiles(t *testing.T) {
+
+	files, err := ExpandToFilesAndURLs([]string{test.FilePath("folders"), test.FilePath("profiles")}, "dev")
+
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, len(files), 10)
+}
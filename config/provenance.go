@@ -0,0 +1,140 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+)
+
+// Provenance records where the value at a configuration path was set and the values it overrode.
+type Provenance struct {
+	// The file or URL that set the current value.
+	Source string
+
+	// Values set at the same path by earlier files or URLs, oldest first.
+	Overridden []OverriddenValue
+}
+
+// OverriddenValue is a value that was replaced during merging by a value from a later file or URL.
+type OverriddenValue struct {
+	// The value that was replaced.
+	Value interface{}
+
+	// The file or URL that set the replaced value.
+	Source string
+}
+
+// Source returns the file or URL that set the value at the supplied path or an empty string if the path is not set, refers
+// to an object or was not set by a file or URL.
+func (ac *Accessor) Source(path string) string {
+
+	if p := ac.Provenance(path); p != nil {
+		return p.Source
+	}
+
+	return ""
+}
+
+// Provenance returns the file or URL that set the value at the supplied path and the values it overrode. Returns nil
+// if the path is not set, refers to an object or was not set by a file or URL.
+func (ac *Accessor) Provenance(path string) *Provenance {
+
+	if ac.Sources == nil {
+		return nil
+	}
+
+	return ac.Sources[path]
+}
+
+// recordSources records the supplied source as the origin of every non-object value in the supplied value
+func recordSources(sources map[string]*Provenance, path string, value interface{}, source string) {
+
+	if m, found := value.(map[string]interface{}); found {
+
+		for k, v := range m {
+			recordSources(sources, childPath(path, k), v, source)
+		}
+
+		return
+	}
+
+	sources[path] = &Provenance{Source: source}
+}
+
+// forgetSources removes the provenance of every value under the supplied path
+func forgetSources(sources map[string]*Provenance, path string) {
+
+	prefix := path + JSONPathSeparator
+
+	for p := range sources {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(sources, p)
+		}
+	}
+}
+
+// WriteAnnotatedJSONConfig writes the merged view of configuration to the supplied file as JSON, but with every
+// non-object value replaced by an object recording the value, the file or URL that set it and the values it overrode.
+// Values resolved from placeholders are redacted.
+func WriteAnnotatedJSONConfig(a *Accessor, f string) error {
+
+	secret := a.placeholderSet()
+
+	annotated := annotate("", redact(a.JSONData, a.PlaceholderPaths), a.Sources, secret)
+
+	content, err := json.MarshalIndent(annotated, "", " ")
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(f, content, 0644)
+}
+
+func annotate(path string, v interface{}, sources map[string]*Provenance, secret map[string]bool) interface{} {
+
+	if m, found := v.(map[string]interface{}); found {
+
+		a := make(map[string]interface{}, len(m))
+
+		for k, e := range m {
+			a[k] = annotate(childPath(path, k), e, sources, secret)
+		}
+
+		return a
+	}
+
+	entry := map[string]interface{}{"Value": v}
+
+	p := sources[path]
+
+	if p == nil {
+		return entry
+	}
+
+	entry["Source"] = p.Source
+
+	if len(p.Overridden) > 0 {
+
+		overrode := make([]interface{}, len(p.Overridden))
+
+		for i, o := range p.Overridden {
+
+			value := o.Value
+
+			// Earlier values at a path that is now a secret may themselves have been secrets
+			if secret[path] {
+				value = RedactedValue
+			}
+
+			overrode[i] = map[string]interface{}{"Value": value, "Source": o.Source}
+		}
+
+		entry["Overrode"] = overrode
+	}
+
+	return entry
+}
@@ -0,0 +1,137 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestProvenanceRecordedDuringMerge(t *testing.T) {
+
+	files := formatFiles("base.json", "override.yaml")
+
+	jm := formatsMerger()
+	jm.BaseSource = "built-in"
+
+	base := map[string]interface{}{"App": map[string]interface{}{"Name": "default", "Version": 1.0}}
+
+	merged, err := jm.LoadAndMergeConfigWithBase(base, files)
+	test.ExpectNil(t, err)
+
+	ca := &Accessor{JSONData: merged, Sources: jm.Sources()}
+
+	test.ExpectString(t, ca.Source("App.Version"), "built-in")
+	test.ExpectString(t, ca.Source("App.Name"), files[0])
+	test.ExpectString(t, ca.Source("HTTPServer.Port"), files[1])
+	test.ExpectString(t, ca.Source("HTTPServer.Hosts"), files[1])
+	test.ExpectString(t, ca.Source("HTTPServer"), "")
+	test.ExpectString(t, ca.Source("Missing.Path"), "")
+
+	p := ca.Provenance("App.Name")
+	test.ExpectInt(t, len(p.Overridden), 1)
+	test.ExpectString(t, p.Overridden[0].Source, "built-in")
+	test.ExpectString(t, p.Overridden[0].Value.(string), "default")
+
+	p = ca.Provenance("HTTPServer.Port")
+	test.ExpectInt(t, len(p.Overridden), 1)
+	test.ExpectString(t, p.Overridden[0].Source, files[0])
+
+	if p.Overridden[0].Value.(float64) != 8080 {
+		t.Errorf("Expected overridden port to be 8080, got %v", p.Overridden[0].Value)
+	}
+
+	test.ExpectInt(t, len(ca.Provenance("HTTPServer.AccessLog.Fields").Overridden), 1)
+	test.ExpectInt(t, len(ca.Provenance("App.Description").Overridden), 0)
+}
+
+func TestProvenanceWhenObjectReplacedByValue(t *testing.T) {
+
+	jm := formatsMerger()
+	jm.BaseSource = "base"
+
+	base := map[string]interface{}{"A": map[string]interface{}{"B": "b", "C": map[string]interface{}{"D": "d"}}, "E": "e"}
+
+	merged, err := jm.LoadAndMergeConfigWithBase(base, nil)
+	test.ExpectNil(t, err)
+
+	jm.sources = jm.Sources()
+	jm.merge(merged, map[string]interface{}{"A": "replaced", "E": map[string]interface{}{"F": "f"}}, "", "later")
+
+	sources := jm.Sources()
+
+	test.ExpectInt(t, len(sources), 2)
+	test.ExpectString(t, sources["A"].Source, "later")
+	test.ExpectInt(t, len(sources["A"].Overridden), 0)
+	test.ExpectString(t, sources["E.F"].Source, "later")
+}
+
+func TestAnnotatedMergedConfig(t *testing.T) {
+
+	files := formatFiles("base.json", "override.json")
+
+	jm := formatsMerger()
+
+	merged, err := jm.LoadAndMergeConfig(files)
+	test.ExpectNil(t, err)
+
+	ca := &Accessor{JSONData: merged, Sources: jm.Sources(), PlaceholderPaths: []string{"App.Name"}}
+
+	dir, err := ioutil.TempDir("", "granitic-provenance")
+	test.ExpectNil(t, err)
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "annotated.json")
+
+	test.ExpectNil(t, WriteAnnotatedJSONConfig(ca, out))
+
+	b, err := ioutil.ReadFile(out)
+	test.ExpectNil(t, err)
+
+	var written map[string]interface{}
+	test.ExpectNil(t, json.Unmarshal(b, &written))
+
+	port := written["HTTPServer"].(map[string]interface{})["Port"].(map[string]interface{})
+
+	if port["Value"].(float64) != 9090 {
+		t.Errorf("Expected annotated port value to be 9090, got %v", port["Value"])
+	}
+
+	test.ExpectString(t, port["Source"].(string), files[1])
+
+	overrode := port["Overrode"].([]interface{})[0].(map[string]interface{})
+	test.ExpectString(t, overrode["Source"].(string), files[0])
+
+	name := written["App"].(map[string]interface{})["Name"].(map[string]interface{})
+	test.ExpectString(t, name["Value"].(string), RedactedValue)
+}
+
+func TestLayersMergedBeforeFiles(t *testing.T) {
+
+	files := formatFiles("base.json")
+
+	jm := formatsMerger()
+	jm.BaseSource = "built-in"
+	jm.Layers = []*Layer{{Source: "facility:Extra", Config: map[string]interface{}{
+		"App":   map[string]interface{}{"Name": "layer", "Version": 2.0},
+		"Extra": map[string]interface{}{"Enabled": true},
+	}}}
+
+	base := map[string]interface{}{"App": map[string]interface{}{"Version": 1.0}}
+
+	merged, err := jm.LoadAndMergeConfigWithBase(base, files)
+	test.ExpectNil(t, err)
+
+	ca := &Accessor{JSONData: merged, Sources: jm.Sources()}
+
+	test.ExpectString(t, ca.Source("Extra.Enabled"), "facility:Extra")
+	test.ExpectString(t, ca.Source("App.Version"), "facility:Extra")
+	test.ExpectString(t, ca.Source("App.Name"), files[0])
+	test.ExpectString(t, ca.Provenance("App.Name").Overridden[0].Source, "facility:Extra")
+}
@@ -0,0 +1,255 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RemoteTokenEnvVar is the environment variable used to supply a bearer token for requests to configuration URLs.
+const RemoteTokenEnvVar = "GRANITIC_REMOTE_CONFIG_TOKEN"
+
+// RemoteTokenHostEnvVar is the environment variable used to supply the host that the bearer token may be sent to.
+const RemoteTokenHostEnvVar = "GRANITIC_REMOTE_CONFIG_TOKEN_HOST"
+
+// Default values used when loading configuration from URLs
+const (
+	DefaultRemoteTimeout       = 30 * time.Second
+	DefaultRemoteRetryInterval = time.Second
+)
+
+// RemoteSettings controls how configuration is loaded from URLs.
+type RemoteSettings struct {
+	// If set, sent as a bearer token in the Authorization header of requests for configuration from BearerTokenHost.
+	BearerToken string
+
+	// The host (including the port, if the URL has one) that BearerToken is sent to. The token is not sent with requests
+	// to any other host and is not sent at all if this is not set.
+	BearerTokenHost string
+
+	// The maximum time to wait for a single request to complete (including reading the response body). Defaults
+	// to DefaultRemoteTimeout if zero.
+	Timeout time.Duration
+
+	// The number of times a request will be retried if the server cannot be reached, the request times out or the
+	// server responds with a 5xx status code. 4xx responses are not retried.
+	Retries int
+
+	// The time to wait before the first retry. The wait doubles before each subsequent retry. Defaults
+	// to DefaultRemoteRetryInterval if zero.
+	RetryInterval time.Duration
+
+	// If set, a copy of the configuration successfully loaded from each URL is stored in this directory. If a URL cannot
+	// be loaded after all retries (and the failure was not a 4xx response), the stored copy is used instead.
+	CacheDir string
+}
+
+// sendsToken returns true if the bearer token should be sent with a request to the supplied host
+func (rs *RemoteSettings) sendsToken(host string) bool {
+	return rs != nil && rs.BearerToken != "" && rs.BearerTokenHost != "" && strings.EqualFold(host, rs.BearerTokenHost)
+}
+
+func (rs *RemoteSettings) timeout() time.Duration {
+	if rs == nil || rs.Timeout <= 0 {
+		return DefaultRemoteTimeout
+	}
+
+	return rs.Timeout
+}
+
+func (rs *RemoteSettings) retryInterval() time.Duration {
+	if rs == nil || rs.RetryInterval <= 0 {
+		return DefaultRemoteRetryInterval
+	}
+
+	return rs.RetryInterval
+}
+
+// defaultTokenHost finds the host a bearer token should be sent to when one has not been explicitly set. If all of the
+// URLs in the supplied configuration are on the same host, that host is returned. An error is returned if there is more
+// than one host, as it is not safe to assume the token is meant for all of them.
+func defaultTokenHost(configuration []string) (string, error) {
+
+	host := ""
+
+	for _, c := range configuration {
+
+		if !isURL(c) {
+			continue
+		}
+
+		u, err := url.Parse(c)
+
+		if err != nil {
+			return "", err
+		}
+
+		if host == "" {
+			host = u.Host
+		} else if !strings.EqualFold(host, u.Host) {
+			return "", fmt.Errorf("configuration is loaded from more than one host (%s and %s). Set %s to the host that the %s token should be sent to",
+				host, u.Host, RemoteTokenHostEnvVar, RemoteTokenEnvVar)
+		}
+	}
+
+	return host, nil
+}
+
+// remoteError is a problem loading configuration from a URL. Unavailable is set if the problem was with the availability of
+// the server (rather than the request being rejected) so a retry or a cached copy is appropriate.
+type remoteError struct {
+	Message     string
+	Unavailable bool
+}
+
+func (re remoteError) Error() string {
+	return re.Message
+}
+
+// remoteResponse is the body and content type of a successful request for configuration
+type remoteResponse struct {
+	Body        []byte
+	ContentType string
+}
+
+// fetchRemote requests the supplied URL, retrying if the server is unavailable
+func (jm *JSONMerger) fetchRemote(url string) (*remoteResponse, error) {
+
+	rs := jm.Remote
+
+	client := &http.Client{Timeout: rs.timeout()}
+
+	attempts := 1
+	wait := rs.retryInterval()
+
+	if rs != nil && rs.Retries > 0 {
+		attempts += rs.Retries
+	}
+
+	var err error
+	var r *remoteResponse
+
+	made := 0
+
+	for made < attempts {
+
+		if made > 0 {
+			jm.Logger.LogWarnf("Retrying %s in %v (attempt %d of %d): %s", url, wait, made+1, attempts, err)
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		made++
+
+		r, err = jm.requestRemote(client, url)
+
+		if err == nil {
+			return r, nil
+		}
+
+		if !err.(remoteError).Unavailable {
+			break
+		}
+	}
+
+	if made > 1 {
+		return nil, remoteError{Message: fmt.Sprintf("%s (after %d attempts)", err, made), Unavailable: true}
+	}
+
+	return nil, err
+}
+
+func (jm *JSONMerger) requestRemote(client *http.Client, url string) (*remoteResponse, error) {
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+
+	if err != nil {
+		return nil, remoteError{Message: err.Error()}
+	}
+
+	if jm.Remote.sendsToken(req.URL.Host) {
+		req.Header.Set("Authorization", "Bearer "+jm.Remote.BearerToken)
+	}
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return nil, remoteError{Message: fmt.Sprintf("unable to reach server: %s", err), Unavailable: true}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, remoteError{Message: fmt.Sprintf("server responded with HTTP %s", resp.Status), Unavailable: resp.StatusCode >= 500}
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, remoteError{Message: fmt.Sprintf("unable to read response: %s", err), Unavailable: true}
+	}
+
+	return &remoteResponse{Body: b, ContentType: resp.Header.Get("content-type")}, nil
+}
+
+// cacheFileStem is the path (without extension) of the file used to store a copy of the configuration from the supplied URL
+func (jm *JSONMerger) cacheFileStem(url string) string {
+
+	h := sha256.Sum256([]byte(url))
+
+	return filepath.Join(jm.Remote.CacheDir, hex.EncodeToString(h[:]))
+}
+
+// writeCache stores a copy of configuration loaded from a URL. The parser used to understand the configuration is
+// recorded as the extension of the file.
+func (jm *JSONMerger) writeCache(url string, data []byte, cp ContentParser) error {
+
+	if len(cp.Extensions()) == 0 {
+		return fmt.Errorf("the parser for %s does not declare a file extension", url)
+	}
+
+	if err := os.MkdirAll(jm.Remote.CacheDir, 0700); err != nil {
+		return err
+	}
+
+	stem := jm.cacheFileStem(url)
+
+	// Remove copies stored in a different format
+	old, _ := filepath.Glob(stem + ".*")
+
+	for _, o := range old {
+		os.Remove(o)
+	}
+
+	return ioutil.WriteFile(stem+"."+strings.ToLower(cp.Extensions()[0]), data, 0600)
+}
+
+// readCache loads the stored copy of configuration from a URL
+func (jm *JSONMerger) readCache(url string) ([]byte, ContentParser, error) {
+
+	stem := jm.cacheFileStem(url)
+
+	cached, _ := filepath.Glob(stem + ".*")
+
+	for _, c := range cached {
+
+		if cp := jm.parserByFile[jm.extractExtension(c)]; cp != nil {
+
+			data, err := ioutil.ReadFile(c)
+
+			return data, cp, err
+		}
+	}
+
+	return nil, nil, fmt.Errorf("no cached copy found in %s", jm.Remote.CacheDir)
+}
@@ -0,0 +1,218 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestBearerTokenSentToConfigServer(t *testing.T) {
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"A": "a"}`))
+	}))
+	defer s.Close()
+
+	jm := formatsMerger()
+
+	_, err := jm.LoadAndMergeConfig([]string{s.URL})
+
+	if err == nil || !strings.Contains(err.Error(), s.URL) || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("Expected an error naming the URL and the 401 status, got %v", err)
+	}
+
+	jm.Remote = &RemoteSettings{BearerToken: "s3cret", BearerTokenHost: strings.TrimPrefix(s.URL, "http://")}
+
+	merged, err := jm.LoadAndMergeConfig([]string{s.URL})
+	test.ExpectNil(t, err)
+
+	test.ExpectString(t, merged["A"].(string), "a")
+}
+
+func TestBearerTokenOnlySentToItsHost(t *testing.T) {
+
+	var received []string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"A": "a"}`))
+	})
+
+	trusted := httptest.NewServer(handler)
+	defer trusted.Close()
+
+	other := httptest.NewServer(handler)
+	defer other.Close()
+
+	jm := formatsMerger()
+	jm.Remote = &RemoteSettings{BearerToken: "s3cret", BearerTokenHost: strings.TrimPrefix(trusted.URL, "http://")}
+
+	_, err := jm.LoadAndMergeConfig([]string{trusted.URL, other.URL})
+	test.ExpectNil(t, err)
+
+	test.ExpectInt(t, len(received), 2)
+	test.ExpectString(t, received[0], "Bearer s3cret")
+	test.ExpectString(t, received[1], "")
+
+	// Without a host, the token is not sent at all
+	received = nil
+	jm.Remote.BearerTokenHost = ""
+
+	_, err = jm.LoadAndMergeConfig([]string{trusted.URL})
+	test.ExpectNil(t, err)
+
+	test.ExpectString(t, received[0], "")
+}
+
+func TestDefaultTokenHost(t *testing.T) {
+
+	host, err := defaultTokenHost([]string{"config/base.json", "https://config.example.com/a.json", "https://config.example.com:443/b.json"})
+
+	if err == nil || !strings.Contains(err.Error(), RemoteTokenHostEnvVar) {
+		t.Errorf("Expected an error naming %s, got %v", RemoteTokenHostEnvVar, err)
+	}
+
+	host, err = defaultTokenHost([]string{"config/base.json", "https://config.example.com/a.json", "https://CONFIG.example.com/b.json"})
+	test.ExpectNil(t, err)
+	test.ExpectString(t, host, "config.example.com")
+
+	host, err = defaultTokenHost([]string{"config/base.json"})
+	test.ExpectNil(t, err)
+	test.ExpectString(t, host, "")
+}
+
+func TestConfigServerRetries(t *testing.T) {
+
+	calls := 0
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		calls++
+
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{"A": "a"}`))
+	}))
+	defer s.Close()
+
+	jm := formatsMerger()
+	jm.Remote = &RemoteSettings{Retries: 1, RetryInterval: time.Millisecond}
+
+	_, err := jm.LoadAndMergeConfig([]string{s.URL})
+
+	if err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Fatalf("Expected an error after 2 attempts, got %v", err)
+	}
+
+	calls = 0
+	jm.Remote.Retries = 2
+
+	merged, err := jm.LoadAndMergeConfig([]string{s.URL})
+	test.ExpectNil(t, err)
+
+	test.ExpectString(t, merged["A"].(string), "a")
+	test.ExpectInt(t, calls, 3)
+}
+
+func TestConfigServerNotFoundNotRetried(t *testing.T) {
+
+	calls := 0
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+
+	jm := formatsMerger()
+	jm.Remote = &RemoteSettings{Retries: 3, RetryInterval: time.Millisecond}
+
+	_, err := jm.LoadAndMergeConfig([]string{s.URL})
+
+	if err == nil || strings.Contains(err.Error(), "attempts") {
+		t.Fatalf("Expected an error without retries, got %v", err)
+	}
+
+	test.ExpectInt(t, calls, 1)
+}
+
+func TestConfigServerTimeout(t *testing.T) {
+
+	done := make(chan bool)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer s.Close()
+	defer close(done)
+
+	jm := formatsMerger()
+	jm.Remote = &RemoteSettings{Timeout: 50 * time.Millisecond}
+
+	_, err := jm.LoadAndMergeConfig([]string{s.URL})
+
+	if err == nil || !strings.Contains(err.Error(), "unable to reach server") {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestFallbackToCachedConfig(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "granitic-remote")
+	test.ExpectNil(t, err)
+	defer os.RemoveAll(dir)
+
+	available := true
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if !available {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("A: a\n"))
+	}))
+	defer s.Close()
+
+	url := s.URL + "/conf.yaml"
+
+	jm := formatsMerger()
+	jm.Remote = &RemoteSettings{CacheDir: dir}
+
+	_, err = jm.LoadAndMergeConfig([]string{url})
+	test.ExpectNil(t, err)
+
+	available = false
+
+	merged, err := jm.LoadAndMergeConfig([]string{url})
+	test.ExpectNil(t, err)
+
+	test.ExpectString(t, merged["A"].(string), "a")
+
+	_, err = jm.LoadAndMergeConfig([]string{s.URL + "/other.yaml"})
+
+	if err == nil || !strings.Contains(err.Error(), "unable to use cached copy") {
+		t.Fatalf("Expected an error explaining no cached copy was available, got %v", err)
+	}
+}
@@ -0,0 +1,481 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaType identifies the type of value expected at a configuration path
+type SchemaType string
+
+// The types of value that can be declared in a Schema
+const (
+	AnyValue    SchemaType = "any value"
+	StringValue SchemaType = "string"
+	IntValue    SchemaType = "integer"
+	NumberValue SchemaType = "number"
+	BoolValue   SchemaType = "bool"
+	ObjectValue SchemaType = "object"
+	ArrayValue  SchemaType = "array"
+)
+
+/*
+Schema describes the expected structure of a subtree of configuration (for example all the configuration under the path
+HTTPServer). Merged configuration can be checked against one or more schemas with Accessor.Validate, which reports:
+
+	Problems: values of the wrong type, missing required values, values not in an enumeration or outside of a range
+	Warnings: keys that are not declared in the schema (with a suggestion if the key looks like a typo of a declared key)
+*/
+type Schema struct {
+	// The path (e.g. HTTPServer or RuntimeCtl.Server) of the object described by this schema. An empty path describes
+	// the root of the merged configuration.
+	Path string
+
+	// The keys expected in the object at Path.
+	Fields map[string]*SchemaField
+
+	// Set to true if keys that are not declared in Fields should not generate warnings.
+	AllowUnknown bool
+}
+
+// SchemaField describes the value expected for a single configuration key.
+type SchemaField struct {
+	// The type of the value. Defaults to AnyValue if not set.
+	Type SchemaType
+
+	// Whether a (non-null) value must be present.
+	Required bool
+
+	// If set, the value must be equal to one of these values. Numbers must be expressed as float64.
+	Enum []interface{}
+
+	// If set, the inclusive lower limit of a numeric value or of the length of a string or array.
+	Min *float64
+
+	// If set, the inclusive upper limit of a numeric value or of the length of a string or array.
+	Max *float64
+
+	// For ObjectValue fields, the keys expected in the object.
+	Fields map[string]*SchemaField
+
+	// For ObjectValue fields, set to true if keys that are not declared in Fields should not generate warnings.
+	AllowUnknown bool
+
+	// For ArrayValue fields, the definition of each element of the array. For ObjectValue fields without Fields (objects
+	// used as maps with arbitrary keys), the definition of each value in the object.
+	Elements *SchemaField
+
+	// An optional function to perform additional checks on the value. A returned error is reported as a problem.
+	Check func(value interface{}) error
+}
+
+// Limit is a convenience function for declaring the Min and Max of a SchemaField.
+func Limit(l float64) *float64 {
+	return &l
+}
+
+// SchemaError is returned by Accessor.Validate and lists every problem found with the configuration.
+type SchemaError struct {
+	Problems []string
+}
+
+// Error lists all of the problems found
+func (se SchemaError) Error() string {
+	return fmt.Sprintf("Configuration is invalid (%d problem(s) found): %s", len(se.Problems), strings.Join(se.Problems, "; "))
+}
+
+/*
+Validate checks the merged configuration against the supplied schemas. Warnings about keys that are not declared in the
+schemas are returned as human readable messages. If any problems are found, the returned error will be a SchemaError
+listing every problem.
+*/
+func (ac *Accessor) Validate(schemas ...*Schema) (warnings []string, err error) {
+
+	sv := new(schemaValidator)
+	sv.seen = make(map[string]bool)
+	sv.convertible = ac.placeholderSet()
+
+	for _, s := range schemas {
+
+		if s == nil {
+			continue
+		}
+
+		root := &SchemaField{Type: ObjectValue, Fields: s.Fields, AllowUnknown: s.AllowUnknown}
+
+		var v interface{} = ac.JSONData
+
+		if s.Path != "" {
+			v = ac.Value(s.Path)
+		}
+
+		if v == nil {
+			// An absent subtree is only a problem if the schema requires values within it
+			sv.checkRequired(s.Path, s.Fields)
+			continue
+		}
+
+		sv.validate(s.Path, v, root)
+	}
+
+	if len(sv.problems) > 0 {
+		return sv.warnings, SchemaError{Problems: sv.problems}
+	}
+
+	return sv.warnings, nil
+}
+
+type schemaValidator struct {
+	problems []string
+	warnings []string
+
+	// Messages already recorded, as more than one schema may describe the same path
+	seen map[string]bool
+
+	// Paths of strings resolved from placeholders that may be converted to bools or numbers
+	convertible map[string]bool
+}
+
+func (sv *schemaValidator) problem(path string, format string, a ...interface{}) {
+
+	m := fmt.Sprintf("%s %s", path, fmt.Sprintf(format, a...))
+
+	if !sv.seen[m] {
+		sv.seen[m] = true
+		sv.problems = append(sv.problems, m)
+	}
+}
+
+func (sv *schemaValidator) warn(m string) {
+
+	if !sv.seen[m] {
+		sv.seen[m] = true
+		sv.warnings = append(sv.warnings, m)
+	}
+}
+
+func (sv *schemaValidator) checkRequired(path string, fields map[string]*SchemaField) {
+
+	for _, k := range sortedFieldNames(fields) {
+
+		if fields[k].Required {
+			sv.problem(childPath(path, k), "is required but has not been set")
+		}
+	}
+}
+
+func (sv *schemaValidator) validate(path string, v interface{}, f *SchemaField) {
+
+	displayPath := path
+
+	if displayPath == "" {
+		displayPath = "Configuration"
+	}
+
+	if s, found := v.(string); found && sv.convertible[path] && (f.Type == BoolValue || f.Type == NumberValue || f.Type == IntValue) {
+		v = convertPlaceholderText(s)
+	}
+
+	if !sv.checkType(displayPath, v, f.Type) {
+		return
+	}
+
+	if len(f.Enum) > 0 && !inEnum(v, f.Enum) {
+		sv.problem(displayPath, "is %s but must be one of %s", describeValue(v), describeEnum(f.Enum))
+	}
+
+	sv.checkRange(displayPath, v, f)
+
+	if f.Check != nil {
+		if err := f.Check(v); err != nil {
+			sv.problem(displayPath, "is invalid: %s", err.Error())
+		}
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		sv.validateObject(path, t, f)
+	case []interface{}:
+		if f.Elements != nil {
+			for i, e := range t {
+				if e != nil {
+					sv.validate(fmt.Sprintf("%s[%d]", path, i), e, f.Elements)
+				}
+			}
+		}
+	}
+}
+
+func (sv *schemaValidator) validateObject(path string, o map[string]interface{}, f *SchemaField) {
+
+	if f.Fields == nil {
+
+		if f.Elements != nil {
+			for _, k := range sortedKeys(o) {
+				if o[k] != nil {
+					sv.validate(childPath(path, k), o[k], f.Elements)
+				}
+			}
+		}
+
+		return
+	}
+
+	for _, k := range sortedFieldNames(f.Fields) {
+
+		field := f.Fields[k]
+		v := o[k]
+
+		if v == nil {
+
+			if field.Required {
+				sv.problem(childPath(path, k), "is required but has not been set")
+			}
+
+			continue
+		}
+
+		sv.validate(childPath(path, k), v, field)
+	}
+
+	if f.AllowUnknown {
+		return
+	}
+
+	for _, k := range sortedKeys(o) {
+
+		if _, declared := f.Fields[k]; declared {
+			continue
+		}
+
+		m := fmt.Sprintf("%s is not a recognised configuration key", childPath(path, k))
+
+		if s := suggestKey(k, f.Fields); s != "" {
+			m += fmt.Sprintf(" (did you mean %s?)", childPath(path, s))
+		}
+
+		sv.warn(m)
+	}
+}
+
+func (sv *schemaValidator) checkType(path string, v interface{}, t SchemaType) bool {
+
+	valid := true
+
+	switch t {
+	case StringValue:
+		_, valid = v.(string)
+	case BoolValue:
+		_, valid = v.(bool)
+	case NumberValue:
+		_, valid = v.(float64)
+	case IntValue:
+		n, isNumber := v.(float64)
+		valid = isNumber && n == math.Trunc(n)
+	case ObjectValue:
+		_, valid = v.(map[string]interface{})
+	case ArrayValue:
+		_, valid = v.([]interface{})
+	}
+
+	if !valid {
+		sv.problem(path, "should be %s %s but is %s", article(t), t, describeValue(v))
+	}
+
+	return valid
+}
+
+func (sv *schemaValidator) checkRange(path string, v interface{}, f *SchemaField) {
+
+	if f.Min == nil && f.Max == nil {
+		return
+	}
+
+	var n float64
+	var what string
+
+	switch t := v.(type) {
+	case float64:
+		n = t
+		what = "a value"
+	case string:
+		n = float64(len(t))
+		what = "a length"
+	case []interface{}:
+		n = float64(len(t))
+		what = "a length"
+	default:
+		return
+	}
+
+	if f.Min != nil && n < *f.Min || f.Max != nil && n > *f.Max {
+		sv.problem(path, "has %s of %v but must be %s", what, n, describeRange(f.Min, f.Max))
+	}
+}
+
+func inEnum(v interface{}, enum []interface{}) bool {
+
+	for _, e := range enum {
+		if reflect.DeepEqual(v, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func describeValue(v interface{}) string {
+
+	switch t := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", t)
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+func describeEnum(enum []interface{}) string {
+
+	d := make([]string, len(enum))
+
+	for i, e := range enum {
+		d[i] = describeValue(e)
+	}
+
+	return strings.Join(d, ", ")
+}
+
+func describeRange(min, max *float64) string {
+
+	switch {
+	case min != nil && max != nil:
+		return fmt.Sprintf("between %v and %v", *min, *max)
+	case min != nil:
+		return fmt.Sprintf("at least %v", *min)
+	}
+
+	return fmt.Sprintf("at most %v", *max)
+}
+
+func article(t SchemaType) string {
+
+	switch t {
+	case IntValue, ObjectValue, ArrayValue, AnyValue:
+		return "an"
+	}
+
+	return "a"
+}
+
+func sortedKeys(o map[string]interface{}) []string {
+
+	keys := make([]string, 0, len(o))
+
+	for k := range o {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedFieldNames(fields map[string]*SchemaField) []string {
+
+	keys := make([]string, 0, len(fields))
+
+	for k := range fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// suggestKey finds the declared key that the supplied unknown key is most likely to be a misspelling of, or returns an
+// empty string if no declared key is similar enough.
+func suggestKey(unknown string, fields map[string]*SchemaField) string {
+
+	best := ""
+	bestDistance := 0
+
+	for _, k := range sortedFieldNames(fields) {
+
+		d := editDistance(strings.ToLower(unknown), strings.ToLower(k))
+
+		// Allow roughly one edit for every five characters, up to a maximum of three
+		limit := 1 + len(k)/5
+
+		if limit > 3 {
+			limit = 3
+		}
+
+		if d <= limit && (best == "" || d < bestDistance) {
+			best = k
+			bestDistance = d
+		}
+	}
+
+	return best
+}
+
+// editDistance calculates the number of insertions, deletions, substitutions and transpositions of adjacent characters
+// required to change a into b.
+func editDistance(a, b string) int {
+
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+
+			cost := 1
+
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(first int, others ...int) int {
+
+	m := first
+
+	for _, o := range others {
+		if o < m {
+			m = o
+		}
+	}
+
+	return m
+}
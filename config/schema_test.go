@@ -0,0 +1,112 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestSchemaValidation(t *testing.T) {
+
+	ca := new(Accessor)
+	ca.JSONData = map[string]interface{}{
+		"Server": map[string]interface{}{
+			"Port":    8080.5,
+			"Mode":    "fast",
+			"Hosts":   []interface{}{"a", 1.0},
+			"Headers": map[string]interface{}{"X-A": "1", "X-B": true},
+			"Name":    "",
+			"Timeout": nil,
+			"Retries": 3.0,
+			"Colour":  "red",
+		},
+	}
+
+	s := &Schema{
+		Path: "Server",
+		Fields: map[string]*SchemaField{
+			"Port":     {Type: IntValue},
+			"Mode":     {Type: StringValue, Enum: []interface{}{"slow", "normal"}},
+			"Hosts":    {Type: ArrayValue, Elements: &SchemaField{Type: StringValue}},
+			"Headers":  {Type: ObjectValue, Elements: &SchemaField{Type: StringValue}},
+			"Name":     {Type: StringValue, Min: Limit(1)},
+			"Timeout":  {Type: IntValue, Required: true},
+			"Retries":  {Type: IntValue, Max: Limit(2), Check: func(v interface{}) error { return errors.New("never valid") }},
+			"Color":    {Type: StringValue},
+			"Optional": {Type: BoolValue},
+		},
+	}
+
+	warnings, err := ca.Validate(s)
+
+	test.ExpectInt(t, len(warnings), 1)
+	test.ExpectString(t, warnings[0], "Server.Colour is not a recognised configuration key (did you mean Server.Color?)")
+
+	se, found := err.(SchemaError)
+
+	if !found {
+		t.Fatalf("Expected a SchemaError, got %v", err)
+	}
+
+	expected := []string{
+		"Server.Headers.X-B should be a string but is true",
+		"Server.Hosts[1] should be a string but is 1",
+		"Server.Mode is \"fast\" but must be one of \"slow\", \"normal\"",
+		"Server.Name has a length of 0 but must be at least 1",
+		"Server.Port should be an integer but is 8080.5",
+		"Server.Retries has a value of 3 but must be at most 2",
+		"Server.Retries is invalid: never valid",
+		"Server.Timeout is required but has not been set",
+	}
+
+	test.ExpectInt(t, len(se.Problems), len(expected))
+
+	for i, e := range expected {
+		if i < len(se.Problems) {
+			test.ExpectString(t, se.Problems[i], e)
+		}
+	}
+}
+
+func TestSchemaForMissingPath(t *testing.T) {
+
+	ca := new(Accessor)
+	ca.JSONData = map[string]interface{}{"Other": "value"}
+
+	optional := &Schema{Path: "Missing", Fields: map[string]*SchemaField{"A": {Type: StringValue}}}
+	required := &Schema{Path: "Missing", Fields: map[string]*SchemaField{"A": {Type: StringValue, Required: true}}}
+
+	warnings, err := ca.Validate(optional)
+
+	test.ExpectInt(t, len(warnings), 0)
+	test.ExpectNil(t, err)
+
+	_, err = ca.Validate(required)
+
+	if err == nil {
+		t.Errorf("Expected an error when a required value is missing")
+	}
+
+	ca.JSONData["Missing"] = "not an object"
+
+	_, err = ca.Validate(optional)
+
+	if err == nil {
+		t.Errorf("Expected an error when the schema's path is not an object")
+	}
+}
+
+func TestKeySuggestions(t *testing.T) {
+
+	fields := map[string]*SchemaField{"Port": {}, "Address": {}, "AccessLogging": {}}
+
+	test.ExpectString(t, suggestKey("Prot", fields), "Port")
+	test.ExpectString(t, suggestKey("port", fields), "Port")
+	test.ExpectString(t, suggestKey("Adress", fields), "Address")
+	test.ExpectString(t, suggestKey("AccessLoging", fields), "AccessLogging")
+	test.ExpectString(t, suggestKey("Timeout", fields), "")
+}
@@ -0,0 +1,744 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+TOMLContentParser supports the loading and parsing of TOML (v1.0) configuration files, including tables, arrays of tables,
+dotted keys, inline tables, multi-line strings and arrays.
+
+Integers and floats are stored as JSON numbers and offset date-times, local date-times, local dates and local times are
+stored as strings (in the format they were written), so the parsed document is identical to the equivalent JSON document.
+*/
+type TOMLContentParser struct {
+}
+
+// ParseInto parses the supplied TOML document and stores the result in the supplied target. Errors are of type ParseError
+// and include the line on which the problem was found.
+func (tcp *TOMLContentParser) ParseInto(data []byte, target interface{}) error {
+
+	doc, err := parseTOML(data)
+
+	if err != nil {
+		return err
+	}
+
+	return assignParsed(doc, target)
+}
+
+// Extensions returns the list of filename extensions (lowercase, without leading dot) that will be considered to be TOML files.
+func (tcp *TOMLContentParser) Extensions() []string {
+	return []string{"toml"}
+}
+
+// ContentTypes returns the MIME media types/HTTP content-types that will be considered to represent TOML
+func (tcp *TOMLContentParser) ContentTypes() []string {
+	return []string{"application/toml", "text/toml", "text/x-toml"}
+}
+
+var (
+	tomlDateTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?([Zz]|[-+]\d{2}:\d{2})?)?$`)
+	tomlTimePattern     = regexp.MustCompile(`^\d{2}:\d{2}(:\d{2}(\.\d+)?)?$`)
+	tomlIntPattern      = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)$`)
+	tomlRadixPattern    = regexp.MustCompile(`^0(x[0-9a-fA-F](_?[0-9a-fA-F])*|o[0-7](_?[0-7])*|b[01](_?[01])*)$`)
+	tomlFloatPattern    = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][-+]?[0-9](_?[0-9])*)?$`)
+	tomlInfNaNPattern   = regexp.MustCompile(`^[-+]?(inf|nan)$`)
+)
+
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+
+	root map[string]interface{}
+
+	// The table that key/value pairs are currently being added to and its path from the root
+	current     map[string]interface{}
+	currentPath []string
+
+	// Tables that have been explicitly defined with a [header] or implicitly defined by dotted keys
+	defined map[string]bool
+
+	// Tables and arrays created from inline tables and arrays, which cannot be extended
+	fixed map[string]bool
+
+	// How deeply nested in inline tables the parser currently is
+	inline int
+}
+
+func parseTOML(data []byte) (map[string]interface{}, error) {
+
+	if !utf8.Valid(data) {
+		return nil, newParseError(1, "TOML documents must be UTF-8 encoded")
+	}
+
+	tp := new(tomlParser)
+	tp.src = strings.TrimPrefix(string(data), "\uFEFF")
+	tp.line = 1
+	tp.root = make(map[string]interface{})
+	tp.current = tp.root
+	tp.currentPath = []string{}
+	tp.defined = make(map[string]bool)
+	tp.fixed = make(map[string]bool)
+
+	if strings.TrimSpace(tp.src) == "" {
+		return nil, EmptyFileError{Message: "TOML document has no content"}
+	}
+
+	for {
+		tp.skipWhitespaceAndComments(true)
+
+		if tp.eof() {
+			return tp.root, nil
+		}
+
+		var err error
+
+		if tp.peek() == '[' {
+			err = tp.parseTableHeader()
+		} else {
+			err = tp.parseKeyValue(tp.current, tp.currentPath)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err = tp.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (tp *tomlParser) eof() bool {
+	return tp.pos >= len(tp.src)
+}
+
+func (tp *tomlParser) peek() byte {
+
+	if tp.eof() {
+		return 0
+	}
+
+	return tp.src[tp.pos]
+}
+
+func (tp *tomlParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(tp.src[tp.pos:], s)
+}
+
+func (tp *tomlParser) errorf(format string, a ...interface{}) error {
+	return newParseError(tp.line, format, a...)
+}
+
+// advance moves forward n bytes, counting any line breaks that are passed
+func (tp *tomlParser) advance(n int) {
+
+	for i := 0; i < n && !tp.eof(); i++ {
+
+		if tp.src[tp.pos] == '\n' {
+			tp.line++
+		}
+
+		tp.pos++
+	}
+}
+
+// skipWhitespaceAndComments skips spaces, tabs and comments and, if newlines is true, line breaks.
+func (tp *tomlParser) skipWhitespaceAndComments(newlines bool) {
+
+	for !tp.eof() {
+
+		switch c := tp.peek(); {
+		case c == ' ' || c == '\t':
+			tp.advance(1)
+		case newlines && (c == '\n' || tp.hasPrefix("\r\n")):
+			tp.advance(1)
+		case newlines && c == '\r':
+			tp.advance(1)
+		case c == '#':
+			for !tp.eof() && tp.peek() != '\n' && !tp.hasPrefix("\r\n") {
+				tp.advance(1)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine checks that nothing other than whitespace or a comment follows a key/value pair or table header
+func (tp *tomlParser) endOfLine() error {
+
+	tp.skipWhitespaceAndComments(false)
+
+	switch {
+	case tp.eof():
+		return nil
+	case tp.peek() == '\n':
+		tp.advance(1)
+		return nil
+	case tp.hasPrefix("\r\n"):
+		tp.advance(2)
+		return nil
+	}
+
+	return tp.errorf("expected the end of the line but found %q", tp.restOfLine())
+}
+
+func (tp *tomlParser) restOfLine() string {
+
+	rest := tp.src[tp.pos:]
+
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+
+	return strings.TrimRight(rest, "\r")
+}
+
+// parseTableHeader handles [table] and [[array.of.tables]] headers
+func (tp *tomlParser) parseTableHeader() error {
+
+	array := tp.hasPrefix("[[")
+
+	if array {
+		tp.advance(2)
+	} else {
+		tp.advance(1)
+	}
+
+	keys, err := tp.parseKey()
+
+	if err != nil {
+		return err
+	}
+
+	tp.skipWhitespaceAndComments(false)
+
+	closing := "]"
+
+	if array {
+		closing = "]]"
+	}
+
+	if !tp.hasPrefix(closing) {
+		return tp.errorf("expected %s to close table header", closing)
+	}
+
+	tp.advance(len(closing))
+
+	parent, err := tp.navigate(tp.root, nil, keys[:len(keys)-1])
+
+	if err != nil {
+		return err
+	}
+
+	last := keys[len(keys)-1]
+	id := tomlPathID(keys)
+
+	if array {
+
+		existing, found := parent[last]
+
+		var tables []interface{}
+
+		if found {
+
+			if tables, found = existing.([]interface{}); !found || tp.fixed[id] {
+				return tp.errorf("cannot define %s as an array of tables as it has already been defined as another type", strings.Join(keys, "."))
+			}
+		}
+
+		t := make(map[string]interface{})
+		parent[last] = append(tables, t)
+		tp.current = t
+		tp.currentPath = keys
+
+		return nil
+	}
+
+	if tp.defined[id] || tp.fixed[id] {
+		return tp.errorf("table %s has already been defined", strings.Join(keys, "."))
+	}
+
+	tp.defined[id] = true
+	tp.currentPath = keys
+
+	existing, found := parent[last]
+
+	if !found {
+		t := make(map[string]interface{})
+		parent[last] = t
+		tp.current = t
+
+		return nil
+	}
+
+	t, isTable := existing.(map[string]interface{})
+
+	if !isTable {
+		return tp.errorf("cannot define %s as a table as it has already been defined as a value", strings.Join(keys, "."))
+	}
+
+	tp.current = t
+
+	return nil
+}
+
+// navigate finds (creating if necessary) the table reached by following the supplied keys from the supplied table, whose
+// path from the root is base. The last table in an array of tables is used when an array is encountered. Tables created
+// by dotted keys are recorded as defined so they cannot later be redefined by a table header.
+func (tp *tomlParser) navigate(from map[string]interface{}, base []string, keys []string) (map[string]interface{}, error) {
+
+	t := from
+
+	for i, k := range keys {
+
+		id := tomlPathID(append(append([]string{}, base...), keys[:i+1]...))
+
+		if tp.inline == 0 && tp.fixed[id] {
+			return nil, tp.errorf("%s was defined inline and cannot be extended", strings.Join(keys[:i+1], "."))
+		}
+
+		existing, found := t[k]
+
+		if !found {
+			n := make(map[string]interface{})
+			t[k] = n
+			t = n
+
+			if tp.inline == 0 && base != nil {
+				tp.defined[id] = true
+			}
+
+			continue
+		}
+
+		switch v := existing.(type) {
+		case map[string]interface{}:
+			t = v
+		case []interface{}:
+			if len(v) == 0 {
+				return nil, tp.errorf("%s is not a table", strings.Join(keys[:i+1], "."))
+			}
+
+			last, isTable := v[len(v)-1].(map[string]interface{})
+
+			if !isTable {
+				return nil, tp.errorf("%s is not a table", strings.Join(keys[:i+1], "."))
+			}
+
+			t = last
+		default:
+			return nil, tp.errorf("%s has already been defined as a value and cannot be used as a table", strings.Join(keys[:i+1], "."))
+		}
+	}
+
+	return t, nil
+}
+
+// parseKeyValue parses a key = value pair and adds it to the supplied table
+func (tp *tomlParser) parseKeyValue(table map[string]interface{}, path []string) error {
+
+	keys, err := tp.parseKey()
+
+	if err != nil {
+		return err
+	}
+
+	tp.skipWhitespaceAndComments(false)
+
+	if tp.peek() != '=' {
+		return tp.errorf("expected = after key %s", strings.Join(keys, "."))
+	}
+
+	tp.advance(1)
+	tp.skipWhitespaceAndComments(false)
+
+	line := tp.line
+
+	v, err := tp.parseValue()
+
+	if err != nil {
+		return err
+	}
+
+	parent, err := tp.navigate(table, path, keys[:len(keys)-1])
+
+	if err != nil {
+		return err
+	}
+
+	last := keys[len(keys)-1]
+
+	if _, duplicate := parent[last]; duplicate {
+		return newParseError(line, "key %s has already been defined", strings.Join(keys, "."))
+	}
+
+	parent[last] = v
+
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		if tp.inline == 0 {
+			tp.fixed[tomlPathID(append(append([]string{}, path...), keys...))] = true
+		}
+	}
+
+	return nil
+}
+
+// parseKey parses a bare, quoted or dotted key
+func (tp *tomlParser) parseKey() ([]string, error) {
+
+	keys := make([]string, 0)
+
+	for {
+		tp.skipWhitespaceAndComments(false)
+
+		var k string
+
+		switch c := tp.peek(); {
+		case c == '"' || c == '\'':
+			s, err := tp.parseString()
+
+			if err != nil {
+				return nil, err
+			}
+
+			k = s
+		case isTOMLBareKeyChar(c):
+			start := tp.pos
+
+			for !tp.eof() && isTOMLBareKeyChar(tp.peek()) {
+				tp.advance(1)
+			}
+
+			k = tp.src[start:tp.pos]
+		default:
+			return nil, tp.errorf("expected a key but found %q", tp.restOfLine())
+		}
+
+		keys = append(keys, k)
+
+		tp.skipWhitespaceAndComments(false)
+
+		if tp.peek() != '.' {
+			return keys, nil
+		}
+
+		tp.advance(1)
+	}
+}
+
+// tomlPathID converts a path of keys to a string suitable for use as a map key
+func tomlPathID(keys []string) string {
+	return strings.Join(keys, "\x00")
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' || c == '-'
+}
+
+func (tp *tomlParser) parseValue() (interface{}, error) {
+
+	switch c := tp.peek(); {
+	case c == '"' || c == '\'':
+		return tp.parseString()
+	case c == '[':
+		return tp.parseArray()
+	case c == '{':
+		return tp.parseInlineTable()
+	case tp.eof() || c == '\n' || c == '\r' || c == '#':
+		return nil, tp.errorf("expected a value")
+	}
+
+	start := tp.pos
+
+	for !tp.eof() {
+
+		c := tp.peek()
+
+		if c == ',' || c == ']' || c == '}' || c == '\n' || c == '\r' || c == '#' {
+			break
+		}
+
+		// A space is only permitted within a value as the separator between a date and a time
+		if c == ' ' {
+
+			token := tp.src[start:tp.pos]
+			next := tp.src[tp.pos+1:]
+
+			if !(len(token) == 10 && tomlDateTimePattern.MatchString(token) && len(next) >= 2 && next[0] >= '0' && next[0] <= '9') {
+				break
+			}
+		}
+
+		tp.advance(1)
+	}
+
+	return tp.resolveScalar(tp.src[start:tp.pos])
+}
+
+// resolveScalar converts a bool, number or date/time token to its value
+func (tp *tomlParser) resolveScalar(token string) (interface{}, error) {
+
+	switch {
+	case token == "true":
+		return true, nil
+	case token == "false":
+		return false, nil
+	case tomlDateTimePattern.MatchString(token), tomlTimePattern.MatchString(token):
+		return token, nil
+	case tomlIntPattern.MatchString(token):
+		f, err := strconv.ParseFloat(strings.Replace(token, "_", "", -1), 64)
+
+		if err != nil {
+			return nil, tp.errorf("%s cannot be converted to a number", token)
+		}
+
+		return f, nil
+	case tomlRadixPattern.MatchString(token):
+		i, err := strconv.ParseInt(strings.Replace(token, "_", "", -1), 0, 64)
+
+		if err != nil {
+			return nil, tp.errorf("%s cannot be converted to a number", token)
+		}
+
+		return float64(i), nil
+	case tomlFloatPattern.MatchString(token):
+		f, err := strconv.ParseFloat(strings.Replace(token, "_", "", -1), 64)
+
+		if err != nil {
+			return nil, tp.errorf("%s cannot be converted to a number", token)
+		}
+
+		return jsonNumber(f, tp.line)
+	case tomlInfNaNPattern.MatchString(token):
+		return nil, tp.errorf("infinite and NaN numbers cannot be used in configuration")
+	case token == "":
+		return nil, tp.errorf("expected a value")
+	}
+
+	return nil, tp.errorf("%q is not a valid value (strings must be quoted)", token)
+}
+
+func (tp *tomlParser) parseArray() (interface{}, error) {
+
+	tp.advance(1)
+
+	a := make([]interface{}, 0)
+
+	for {
+		tp.skipWhitespaceAndComments(true)
+
+		if tp.eof() {
+			return nil, tp.errorf("unterminated array")
+		}
+
+		if tp.peek() == ']' {
+			tp.advance(1)
+			return a, nil
+		}
+
+		v, err := tp.parseValue()
+
+		if err != nil {
+			return nil, err
+		}
+
+		a = append(a, v)
+
+		tp.skipWhitespaceAndComments(true)
+
+		switch tp.peek() {
+		case ',':
+			tp.advance(1)
+		case ']':
+		case 0:
+			return nil, tp.errorf("unterminated array")
+		default:
+			return nil, tp.errorf("expected , or ] in array but found %q", tp.restOfLine())
+		}
+	}
+}
+
+func (tp *tomlParser) parseInlineTable() (interface{}, error) {
+
+	tp.advance(1)
+
+	t := make(map[string]interface{})
+
+	tp.skipWhitespaceAndComments(false)
+
+	if tp.peek() == '}' {
+		tp.advance(1)
+		return t, nil
+	}
+
+	for {
+		tp.inline++
+		err := tp.parseKeyValue(t, nil)
+		tp.inline--
+
+		if err != nil {
+			return nil, err
+		}
+
+		tp.skipWhitespaceAndComments(false)
+
+		switch tp.peek() {
+		case ',':
+			tp.advance(1)
+		case '}':
+			tp.advance(1)
+			return t, nil
+		default:
+			return nil, tp.errorf("expected , or } in inline table but found %q (inline tables must be on a single line)", tp.restOfLine())
+		}
+	}
+}
+
+// parseString parses basic, literal and multi-line strings
+func (tp *tomlParser) parseString() (string, error) {
+
+	switch {
+	case tp.hasPrefix(`"""`):
+		return tp.parseMultiLineString(`"""`, true)
+	case tp.hasPrefix(`'''`):
+		return tp.parseMultiLineString(`'''`, false)
+	}
+
+	quote := tp.peek()
+	tp.advance(1)
+
+	var b strings.Builder
+
+	for {
+		if tp.eof() || tp.peek() == '\n' {
+			return "", tp.errorf("unterminated string")
+		}
+
+		c := tp.peek()
+
+		if c == quote {
+			tp.advance(1)
+			return b.String(), nil
+		}
+
+		if c == '\\' && quote == '"' {
+
+			if err := tp.parseEscape(&b); err != nil {
+				return "", err
+			}
+
+			continue
+		}
+
+		b.WriteByte(c)
+		tp.advance(1)
+	}
+}
+
+func (tp *tomlParser) parseMultiLineString(delimiter string, escapes bool) (string, error) {
+
+	start := tp.line
+
+	tp.advance(3)
+
+	// A line break immediately after the opening delimiter is trimmed
+	if tp.hasPrefix("\r\n") {
+		tp.advance(2)
+	} else if tp.peek() == '\n' {
+		tp.advance(1)
+	}
+
+	var b strings.Builder
+
+	for {
+		if tp.eof() {
+			return "", newParseError(start, "unterminated multi-line string")
+		}
+
+		// Up to two quotes may appear immediately before the closing delimiter
+		if tp.hasPrefix(delimiter) && !strings.HasPrefix(tp.src[tp.pos+1:], delimiter+string(delimiter[0])) {
+			tp.advance(3)
+			return b.String(), nil
+		}
+
+		c := tp.peek()
+
+		if c == '\\' && escapes {
+
+			rest := tp.src[tp.pos+1:]
+			trimmed := strings.TrimLeft(rest, " \t")
+
+			// A line ending backslash trims the line break and any whitespace that follows
+			if strings.HasPrefix(trimmed, "\n") || strings.HasPrefix(trimmed, "\r\n") {
+				tp.advance(1)
+				tp.skipWhitespaceAndComments(true)
+
+				continue
+			}
+
+			if err := tp.parseEscape(&b); err != nil {
+				return "", err
+			}
+
+			continue
+		}
+
+		if tp.hasPrefix("\r\n") {
+			b.WriteString("\n")
+			tp.advance(2)
+
+			continue
+		}
+
+		b.WriteByte(c)
+		tp.advance(1)
+	}
+}
+
+// parseEscape decodes the escape sequence starting at the current backslash
+func (tp *tomlParser) parseEscape(b *strings.Builder) error {
+
+	if tp.pos+1 >= len(tp.src) {
+		return tp.errorf("incomplete escape sequence")
+	}
+
+	e := tp.src[tp.pos+1]
+
+	simple := map[byte]string{'b': "\b", 't': "\t", 'n': "\n", 'f': "\f", 'r': "\r", 'e': "\x1b", '"': "\"", '\\': "\\"}
+
+	if s, found := simple[e]; found {
+		b.WriteString(s)
+		tp.advance(2)
+
+		return nil
+	}
+
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+
+	if digits == 0 || tp.pos+2+digits > len(tp.src) {
+		return tp.errorf("invalid escape sequence \\%c", e)
+	}
+
+	hex := tp.src[tp.pos+2 : tp.pos+2+digits]
+	r, err := strconv.ParseUint(hex, 16, 32)
+
+	if err != nil || !utf8.ValidRune(rune(r)) {
+		return tp.errorf("invalid escape sequence \\%c%s", e, hex)
+	}
+
+	b.WriteRune(rune(r))
+	tp.advance(2 + digits)
+
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestTOMLTablesAndValues(t *testing.T) {
+
+	doc := `
+# Comment
+Title = "basic \"string\"" # trailing comment
+Literal = 'C:\path'
+Int = 1_000
+Hex = 0xff
+Float = -2.5e-1
+Bool = false
+Date = 1979-05-27
+DateTime = 1979-05-27 07:32:00
+Dotted.Key = "d"
+Array = [
+  1,
+  2, # comment
+]
+
+[Server]
+Inline = { Port = 80, Hosts = ["a", "b"] }
+
+[Server.TLS]
+Enabled = true
+
+[[Routes]]
+Path = "/a"
+
+[[Routes]]
+Path = "/b"
+
+[Routes.Limits]
+Max = 3
+
+["Quoted key"]
+Multi = """
+one \
+  two"""
+Raw = '''
+raw\n'''
+`
+
+	var parsed interface{}
+
+	err := new(TOMLContentParser).ParseInto([]byte(doc), &parsed)
+	test.ExpectNil(t, err)
+
+	expected := map[string]interface{}{
+		"Title":    `basic "string"`,
+		"Literal":  `C:\path`,
+		"Int":      float64(1000),
+		"Hex":      float64(255),
+		"Float":    float64(-0.25),
+		"Bool":     false,
+		"Date":     "1979-05-27",
+		"DateTime": "1979-05-27 07:32:00",
+		"Dotted":   map[string]interface{}{"Key": "d"},
+		"Array":    []interface{}{float64(1), float64(2)},
+		"Server": map[string]interface{}{
+			"Inline": map[string]interface{}{"Port": float64(80), "Hosts": []interface{}{"a", "b"}},
+			"TLS":    map[string]interface{}{"Enabled": true},
+		},
+		"Routes": []interface{}{
+			map[string]interface{}{"Path": "/a"},
+			map[string]interface{}{"Path": "/b", "Limits": map[string]interface{}{"Max": float64(3)}},
+		},
+		"Quoted key": map[string]interface{}{
+			"Multi": "one two",
+			"Raw":   `raw\n`,
+		},
+	}
+
+	if !reflect.DeepEqual(expected, parsed) {
+		t.Errorf("Expected %v, got %v", expected, parsed)
+	}
+}
+
+func TestTOMLErrors(t *testing.T) {
+
+	docs := map[string]int{
+		"A = 1\nA = 2\n":                  2,
+		"[A]\nB = 1\n[A]\n":               3,
+		"A = {B = 1}\n[A.C]\n":            2,
+		"A.B = 1\n[A]\n":                  2,
+		"A = [1]\n[[A]]\n":                2,
+		"A = hello\n":                     1,
+		"A = nan\n":                       1,
+		"A = 1 B = 2\n":                   1,
+		"\n\nA = [1,\n2\n":                5,
+		"A = \"unterminated\n":            1,
+		"A = 1\nB = \"\"\"\nunterminated": 2,
+	}
+
+	for doc, line := range docs {
+
+		var parsed interface{}
+
+		err := new(TOMLContentParser).ParseInto([]byte(doc), &parsed)
+
+		pe, found := err.(ParseError)
+
+		if !found {
+			t.Errorf("Expected a ParseError for %q, got %v", doc, err)
+			continue
+		}
+
+		if pe.Line != line {
+			t.Errorf("Expected error for %q on line %d, got %s", doc, line, pe.Error())
+		}
+	}
+}
@@ -0,0 +1,977 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+YAMLContentParser supports the loading and parsing of YAML configuration files. The parser supports the subset of YAML 1.2
+that is useful for configuration:
+
+	Block mappings and sequences (including sequences of mappings)
+	Flow mappings and sequences ({a: 1} and [1, 2]), which may span several lines
+	Plain, single-quoted and double-quoted scalars, including plain scalars continued on more indented lines
+	Literal (|) and folded (>) block scalars with optional chomping (- or +) and indentation indicators
+	Comments and a single document optionally started with --- and ended with ...
+
+Scalars are converted using the YAML 1.2 core schema (null, bool, int and float), so the parsed document is identical to the
+equivalent JSON document. Anchors, aliases, tags, complex keys and multiple documents are not supported and are reported as errors.
+*/
+type YAMLContentParser struct {
+}
+
+// ParseInto parses the supplied YAML document and stores the result in the supplied target. Errors are of type ParseError
+// and include the line on which the problem was found.
+func (ycp *YAMLContentParser) ParseInto(data []byte, target interface{}) error {
+
+	doc, err := parseYAML(data)
+
+	if err != nil {
+		return err
+	}
+
+	return assignParsed(doc, target)
+}
+
+// Extensions returns the list of filename extensions (lowercase, without leading dot) that will be considered to be YAML files.
+func (ycp *YAMLContentParser) Extensions() []string {
+	return []string{"yaml", "yml"}
+}
+
+// ContentTypes returns the MIME media types/HTTP content-types that will be considered to represent YAML
+func (ycp *YAMLContentParser) ContentTypes() []string {
+	return []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"}
+}
+
+var (
+	yamlIntPattern      = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlRadixIntPattern = regexp.MustCompile(`^[-+]?(0x[0-9a-fA-F]+|0o[0-7]+)$`)
+	yamlFloatPattern    = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+	yamlInfNaNPattern   = regexp.MustCompile(`^([-+]?\.(inf|Inf|INF)|\.(nan|NaN|NAN))$`)
+)
+
+// yamlLine is a single line of a YAML document
+type yamlLine struct {
+	// Line number (starting at 1)
+	num int
+
+	// The line without its line ending
+	raw string
+
+	// The number of leading spaces
+	indent int
+
+	// The content of the line after the indentation, with any comment and trailing whitespace removed. Empty for blank
+	// and comment-only lines
+	text string
+
+	// True if the indentation includes a tab
+	tabbed bool
+}
+
+type yamlParser struct {
+	lines []*yamlLine
+	pos   int
+}
+
+func parseYAML(data []byte) (map[string]interface{}, error) {
+
+	p, err := newYAMLParser(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := p.peek()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if first == nil {
+		return nil, EmptyFileError{Message: "YAML document has no content"}
+	}
+
+	v, err := p.parseBlock(-1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if l, err := p.peek(); err != nil {
+		return nil, err
+	} else if l != nil {
+		return nil, newParseError(l.num, "unexpected content %q", l.text)
+	}
+
+	doc, found := v.(map[string]interface{})
+
+	if !found {
+		return nil, newParseError(first.num, "the top level of a configuration file must be a mapping of keys to values")
+	}
+
+	return doc, nil
+}
+
+// newYAMLParser splits the document into lines, handling directives and document start and end markers
+func newYAMLParser(data []byte) (*yamlParser, error) {
+
+	if !utf8.Valid(data) {
+		return nil, newParseError(1, "YAML documents must be UTF-8 encoded")
+	}
+
+	p := new(yamlParser)
+
+	content := strings.TrimPrefix(string(data), "\uFEFF")
+	started := false
+
+	for i, raw := range strings.Split(content, "\n") {
+
+		raw = strings.TrimSuffix(raw, "\r")
+		num := i + 1
+
+		if raw == "..." || strings.HasPrefix(raw, "... ") {
+			break
+		}
+
+		if raw == "---" || strings.HasPrefix(raw, "--- ") {
+
+			if started {
+				return nil, newParseError(num, "multiple YAML documents in one file are not supported")
+			}
+
+			started = true
+
+			if rest := strings.TrimSpace(stripYAMLComment(raw[3:])); rest != "" {
+				return nil, newParseError(num, "content on the same line as the document start marker is not supported")
+			}
+
+			continue
+		}
+
+		if !started && strings.HasPrefix(raw, "%") {
+			continue
+		}
+
+		if strings.TrimSpace(raw) != "" && !strings.HasPrefix(strings.TrimSpace(raw), "#") {
+			started = true
+		}
+
+		p.lines = append(p.lines, newYAMLLine(num, raw))
+	}
+
+	return p, nil
+}
+
+func newYAMLLine(num int, raw string) *yamlLine {
+
+	l := &yamlLine{num: num, raw: raw}
+
+	trimmed := strings.TrimLeft(raw, " \t")
+	leading := raw[:len(raw)-len(trimmed)]
+
+	l.indent = len(leading)
+	l.tabbed = strings.Contains(leading, "\t")
+	l.text = strings.TrimRight(stripYAMLComment(trimmed), " \t")
+
+	return l
+}
+
+// peek returns the next line with content (skipping blank and comment-only lines) without consuming it, or nil if there
+// are no more lines.
+func (p *yamlParser) peek() (*yamlLine, error) {
+
+	for p.pos < len(p.lines) {
+
+		l := p.lines[p.pos]
+
+		if l.text != "" {
+
+			if l.tabbed {
+				return nil, newParseError(l.num, "tabs cannot be used for indentation")
+			}
+
+			return l, nil
+		}
+
+		p.pos++
+	}
+
+	return nil, nil
+}
+
+// parseBlock parses the node starting on the next line, provided it is indented more than the parent node.
+func (p *yamlParser) parseBlock(parentIndent int) (interface{}, error) {
+
+	l, err := p.peek()
+
+	if err != nil || l == nil || l.indent <= parentIndent {
+		return nil, err
+	}
+
+	if isYAMLSequenceItem(l.text) {
+		return p.parseSequence(l.indent)
+	}
+
+	if isYAMLMappingLine(l.text) {
+		return p.parseMapping(l.indent)
+	}
+
+	p.pos++
+
+	return p.parseValue(l.text, l, parentIndent, false)
+}
+
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+
+	m := make(map[string]interface{})
+
+	for {
+		l, err := p.peek()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if l == nil || l.indent < indent {
+			return m, nil
+		}
+
+		if l.indent > indent {
+			return nil, newParseError(l.num, "unexpected indentation")
+		}
+
+		if isYAMLSequenceItem(l.text) {
+			return nil, newParseError(l.num, "a sequence item cannot appear at the same level as mapping keys")
+		}
+
+		key, rest, ok, err := splitYAMLMappingLine(l.text, l.num)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			return nil, newParseError(l.num, "expected 'key: value' but found %q", l.text)
+		}
+
+		if _, duplicate := m[key]; duplicate {
+			return nil, newParseError(l.num, "duplicate key %q", key)
+		}
+
+		p.pos++
+
+		v, err := p.parseValue(rest, l, indent, true)
+
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = v
+	}
+}
+
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+
+	s := make([]interface{}, 0)
+
+	for {
+		l, err := p.peek()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if l == nil || l.indent < indent || !isYAMLSequenceItem(l.text) {
+
+			if l != nil && l.indent > indent {
+				return nil, newParseError(l.num, "unexpected indentation")
+			}
+
+			return s, nil
+		}
+
+		if l.indent > indent {
+			return nil, newParseError(l.num, "unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+
+		var v interface{}
+
+		if isYAMLSequenceItem(rest) || isYAMLMappingLine(rest) {
+			// A compact nested collection - treat the remainder of the line as if it started a new line at the
+			// same column
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+
+			v, err = p.parseBlock(indent)
+		} else {
+			p.pos++
+			v, err = p.parseValue(rest, l, indent, false)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, v)
+	}
+}
+
+// parseValue parses the value that follows a mapping key or sequence indicator on the supplied line. Nested nodes and
+// continuation lines must be indented more than the supplied indent.
+func (p *yamlParser) parseValue(rest string, l *yamlLine, indent int, inMapping bool) (interface{}, error) {
+
+	rest = strings.TrimSpace(rest)
+
+	if rest == "" {
+
+		next, err := p.peek()
+
+		if err != nil || next == nil {
+			return nil, err
+		}
+
+		if next.indent > indent {
+			return p.parseBlock(indent)
+		}
+
+		if inMapping && next.indent == indent && isYAMLSequenceItem(next.text) {
+			return p.parseSequence(indent)
+		}
+
+		return nil, nil
+	}
+
+	switch rest[0] {
+	case '|', '>':
+		return p.parseBlockScalar(rest, l, indent)
+	case '&', '*':
+		return nil, newParseError(l.num, "anchors and aliases are not supported")
+	case '!':
+		return nil, newParseError(l.num, "tags are not supported")
+	case '?':
+		if rest == "?" || strings.HasPrefix(rest, "? ") {
+			return nil, newParseError(l.num, "complex keys are not supported")
+		}
+	case '[', '{':
+		text := rest
+
+		for !yamlFlowComplete(text) {
+
+			next, err := p.peek()
+
+			if err != nil {
+				return nil, err
+			}
+
+			if next == nil {
+				return nil, newParseError(l.num, "unterminated flow collection")
+			}
+
+			text += " " + next.text
+			p.pos++
+		}
+
+		return parseYAMLFlow(text, l.num)
+	case '"', '\'':
+		v, remaining, err := parseYAMLQuoted(rest, l.num)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.TrimSpace(remaining) != "" {
+			return nil, newParseError(l.num, "unexpected content after quoted string: %q", remaining)
+		}
+
+		return v, nil
+	}
+
+	text := rest
+
+	// Plain scalars can continue on following lines that are indented further
+	for {
+		next, err := p.peek()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if next == nil || next.indent <= indent || isYAMLMappingLine(next.text) {
+			break
+		}
+
+		text += " " + next.text
+		p.pos++
+	}
+
+	return resolveYAMLPlain(text, l.num)
+}
+
+func (p *yamlParser) parseBlockScalar(header string, l *yamlLine, indent int) (interface{}, error) {
+
+	style := header[0]
+	chomp := byte(0)
+	explicit := 0
+
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			explicit = int(c - '0')
+		default:
+			return nil, newParseError(l.num, "invalid block scalar header %q", header)
+		}
+	}
+
+	parent := indent
+
+	if parent < 0 {
+		parent = 0
+	}
+
+	contentIndent := 0
+
+	if explicit > 0 {
+		contentIndent = parent + explicit
+	}
+
+	lines := make([]string, 0)
+
+	for p.pos < len(p.lines) {
+
+		raw := p.lines[p.pos].raw
+
+		if strings.TrimSpace(raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+
+		ind := len(raw) - len(strings.TrimLeft(raw, " "))
+
+		if contentIndent == 0 {
+
+			if ind <= indent {
+				break
+			}
+
+			contentIndent = ind
+		}
+
+		if ind < contentIndent {
+			break
+		}
+
+		lines = append(lines, raw[contentIndent:])
+		p.pos++
+	}
+
+	trailing := 0
+
+	for i := len(lines) - 1; i >= 0 && lines[i] == ""; i-- {
+		trailing++
+	}
+
+	body := lines[:len(lines)-trailing]
+
+	var text string
+
+	if style == '|' {
+		text = strings.Join(body, "\n")
+	} else {
+		text = foldYAMLLines(body)
+	}
+
+	if len(body) > 0 {
+		switch chomp {
+		case '-':
+		case '+':
+			text += strings.Repeat("\n", trailing+1)
+		default:
+			text += "\n"
+		}
+	}
+
+	return text, nil
+}
+
+// foldYAMLLines joins the lines of a folded block scalar. Line breaks between lines of text become spaces, empty lines
+// become line breaks and line breaks around more indented lines are preserved.
+func foldYAMLLines(body []string) string {
+
+	var b strings.Builder
+
+	blanks := 0
+	started := false
+	prevIndented := false
+
+	for _, line := range body {
+
+		if line == "" {
+			blanks++
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+
+		if started {
+			switch {
+			case blanks > 0:
+				b.WriteString(strings.Repeat("\n", blanks))
+			case indented || prevIndented:
+				b.WriteString("\n")
+			default:
+				b.WriteString(" ")
+			}
+		} else {
+			b.WriteString(strings.Repeat("\n", blanks))
+		}
+
+		b.WriteString(line)
+
+		started = true
+		blanks = 0
+		prevIndented = indented
+	}
+
+	return b.String()
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isYAMLMappingLine(text string) bool {
+	_, _, ok, err := splitYAMLMappingLine(text, 0)
+
+	return ok && err == nil
+}
+
+// splitYAMLMappingLine splits a line of the form 'key: value' into the key and the (possibly empty) value. ok is false if the
+// line is not a mapping entry.
+func splitYAMLMappingLine(text string, num int) (key string, rest string, ok bool, err error) {
+
+	if text == "" || isYAMLSequenceItem(text) {
+		return "", "", false, nil
+	}
+
+	switch text[0] {
+	case '"', '\'':
+		k, remaining, err := parseYAMLQuoted(text, num)
+
+		if err != nil {
+			return "", "", false, nil
+		}
+
+		remaining = strings.TrimLeft(remaining, " ")
+
+		if remaining == ":" || strings.HasPrefix(remaining, ": ") {
+			return k, remaining[1:], true, nil
+		}
+
+		return "", "", false, nil
+	case '[', '{':
+		return "", "", false, nil
+	case '?':
+		if text == "?" || strings.HasPrefix(text, "? ") {
+			return "", "", false, newParseError(num, "complex keys are not supported")
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+
+		if text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ' || text[i+1] == '\t') {
+			return strings.TrimSpace(text[:i]), text[i+1:], true, nil
+		}
+	}
+
+	return "", "", false, nil
+}
+
+// stripYAMLComment removes a comment from a line. A # starts a comment if it is at the start of the line or follows whitespace
+// and is not within a quoted scalar.
+func stripYAMLComment(text string) string {
+
+	var quote byte
+	lastSignificant := byte(0)
+
+	for i := 0; i < len(text); i++ {
+
+		c := text[i]
+
+		if quote != 0 {
+
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+
+			continue
+		}
+
+		switch {
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		case (c == '"' || c == '\'') && (i == 0 || text[i-1] == ' ' || strings.IndexByte("[{,", text[i-1]) >= 0) && (lastSignificant == 0 || strings.IndexByte(":-,[{?", lastSignificant) >= 0):
+			quote = c
+		}
+
+		if c != ' ' && c != '\t' {
+			lastSignificant = c
+		}
+	}
+
+	return text
+}
+
+// parseYAMLQuoted parses the single or double quoted scalar at the start of the supplied text and returns its value
+// and any text that follows the closing quote.
+func parseYAMLQuoted(text string, num int) (string, string, error) {
+
+	quote := text[0]
+
+	var b strings.Builder
+
+	for i := 1; i < len(text); i++ {
+
+		c := text[i]
+
+		if quote == '\'' {
+
+			if c == '\'' {
+
+				if i+1 < len(text) && text[i+1] == '\'' {
+					b.WriteByte('\'')
+					i++
+					continue
+				}
+
+				return b.String(), text[i+1:], nil
+			}
+
+			b.WriteByte(c)
+			continue
+		}
+
+		switch c {
+		case '"':
+			return b.String(), text[i+1:], nil
+		case '\\':
+			n, err := writeYAMLEscape(&b, text[i+1:], num)
+
+			if err != nil {
+				return "", "", err
+			}
+
+			i += n
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", "", newParseError(num, "unterminated quoted string (quoted strings must be on a single line)")
+}
+
+// writeYAMLEscape decodes the escape sequence at the start of the supplied text (which follows a backslash) and returns
+// the number of bytes consumed.
+func writeYAMLEscape(b *strings.Builder, text string, num int) (int, error) {
+
+	if text == "" {
+		return 0, newParseError(num, "incomplete escape sequence")
+	}
+
+	simple := map[byte]string{'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+		'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085", '_': " ", 'L': " ", 'P': " "}
+
+	if s, found := simple[text[0]]; found {
+		b.WriteString(s)
+		return 1, nil
+	}
+
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[text[0]]
+
+	if digits == 0 || len(text) < digits+1 {
+		return 0, newParseError(num, "invalid escape sequence \\%c", text[0])
+	}
+
+	r, err := strconv.ParseUint(text[1:digits+1], 16, 32)
+
+	if err != nil {
+		return 0, newParseError(num, "invalid escape sequence \\%s", text[:digits+1])
+	}
+
+	b.WriteRune(rune(r))
+
+	return digits + 1, nil
+}
+
+// resolveYAMLPlain converts a plain scalar to a null, bool, number or string using the YAML 1.2 core schema
+func resolveYAMLPlain(text string, num int) (interface{}, error) {
+
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+
+	switch {
+	case yamlIntPattern.MatchString(text), yamlFloatPattern.MatchString(text):
+		f, err := strconv.ParseFloat(text, 64)
+
+		if err != nil {
+			return nil, newParseError(num, "%s cannot be converted to a number", text)
+		}
+
+		return jsonNumber(f, num)
+	case yamlRadixIntPattern.MatchString(text):
+		i, err := strconv.ParseInt(text, 0, 64)
+
+		if err != nil {
+			return nil, newParseError(num, "%s cannot be converted to a number", text)
+		}
+
+		return float64(i), nil
+	case yamlInfNaNPattern.MatchString(text):
+		return nil, newParseError(num, "%s cannot be used in configuration (infinite and NaN numbers are not supported)", text)
+	}
+
+	return text, nil
+}
+
+// yamlFlowComplete returns true if all of the brackets and braces opened in the supplied text have been closed
+func yamlFlowComplete(text string) bool {
+
+	depth := 0
+	var quote byte
+
+	for i := 0; i < len(text); i++ {
+
+		c := text[i]
+
+		if quote != 0 {
+
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+	}
+
+	return depth <= 0
+}
+
+// yamlFlowParser parses flow sequences and mappings
+type yamlFlowParser struct {
+	text string
+	pos  int
+	num  int
+}
+
+func parseYAMLFlow(text string, num int) (interface{}, error) {
+
+	fp := &yamlFlowParser{text: text, num: num}
+
+	v, err := fp.value(false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fp.skipSpace()
+
+	if fp.pos < len(fp.text) {
+		return nil, newParseError(num, "unexpected content after flow collection: %q", fp.text[fp.pos:])
+	}
+
+	return v, nil
+}
+
+func (fp *yamlFlowParser) skipSpace() {
+	for fp.pos < len(fp.text) && (fp.text[fp.pos] == ' ' || fp.text[fp.pos] == '\t') {
+		fp.pos++
+	}
+}
+
+func (fp *yamlFlowParser) value(key bool) (interface{}, error) {
+
+	fp.skipSpace()
+
+	if fp.pos >= len(fp.text) {
+		return nil, newParseError(fp.num, "unexpected end of flow collection")
+	}
+
+	switch fp.text[fp.pos] {
+	case '[':
+		return fp.sequence()
+	case '{':
+		return fp.mapping()
+	case '"', '\'':
+		v, remaining, err := parseYAMLQuoted(fp.text[fp.pos:], fp.num)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fp.pos = len(fp.text) - len(remaining)
+
+		return v, nil
+	case '&', '*':
+		return nil, newParseError(fp.num, "anchors and aliases are not supported")
+	case '!':
+		return nil, newParseError(fp.num, "tags are not supported")
+	}
+
+	start := fp.pos
+
+	for fp.pos < len(fp.text) {
+
+		c := fp.text[fp.pos]
+
+		if c == ',' || c == ']' || c == '}' || c == '[' || c == '{' {
+			break
+		}
+
+		if key && c == ':' && (fp.pos+1 == len(fp.text) || strings.IndexByte(" ,}", fp.text[fp.pos+1]) >= 0) {
+			break
+		}
+
+		fp.pos++
+	}
+
+	plain := strings.TrimSpace(fp.text[start:fp.pos])
+
+	if key {
+		return plain, nil
+	}
+
+	return resolveYAMLPlain(plain, fp.num)
+}
+
+func (fp *yamlFlowParser) sequence() (interface{}, error) {
+
+	fp.pos++
+
+	s := make([]interface{}, 0)
+
+	for {
+		fp.skipSpace()
+
+		if fp.pos < len(fp.text) && fp.text[fp.pos] == ']' {
+			fp.pos++
+			return s, nil
+		}
+
+		v, err := fp.value(false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, v)
+
+		if err := fp.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (fp *yamlFlowParser) mapping() (interface{}, error) {
+
+	fp.pos++
+
+	m := make(map[string]interface{})
+
+	for {
+		fp.skipSpace()
+
+		if fp.pos < len(fp.text) && fp.text[fp.pos] == '}' {
+			fp.pos++
+			return m, nil
+		}
+
+		k, err := fp.value(true)
+
+		if err != nil {
+			return nil, err
+		}
+
+		key, found := k.(string)
+
+		if !found {
+			return nil, newParseError(fp.num, "flow mapping keys must be scalars")
+		}
+
+		if _, duplicate := m[key]; duplicate {
+			return nil, newParseError(fp.num, "duplicate key %q", key)
+		}
+
+		fp.skipSpace()
+
+		var v interface{}
+
+		if fp.pos < len(fp.text) && fp.text[fp.pos] == ':' {
+			fp.pos++
+			fp.skipSpace()
+
+			if fp.pos < len(fp.text) && fp.text[fp.pos] != ',' && fp.text[fp.pos] != '}' {
+
+				if v, err = fp.value(false); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		m[key] = v
+
+		if err := fp.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator consumes the comma between entries in a flow collection. The closing character is left to be consumed by
+// the caller.
+func (fp *yamlFlowParser) separator(closing byte) error {
+
+	fp.skipSpace()
+
+	if fp.pos >= len(fp.text) {
+		return newParseError(fp.num, "unterminated flow collection")
+	}
+
+	switch fp.text[fp.pos] {
+	case ',':
+		fp.pos++
+		return nil
+	case closing:
+		return nil
+	}
+
+	return newParseError(fp.num, "expected ',' or '%c' in flow collection but found %q", closing, fp.text[fp.pos:])
+}
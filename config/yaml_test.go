@@ -0,0 +1,114 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestYAMLScalarsAndCollections(t *testing.T) {
+
+	doc := `
+# Comment
+String: plain text # trailing comment
+Quoted: "tab\there"
+Single: 'it''s'
+Int: 42
+Hex: 0x1F
+Float: 1.5e3
+Bool: yes
+Null: ~
+Flow: {A: [1, two], B: {}}
+Nested:
+  List:
+    - 1
+    - Name: x
+      Enabled: true
+    - - inner
+Literal: |
+  line one
+  line two
+Folded: >-
+  folded
+  text
+`
+
+	var parsed interface{}
+
+	err := new(YAMLContentParser).ParseInto([]byte(doc), &parsed)
+	test.ExpectNil(t, err)
+
+	expected := map[string]interface{}{
+		"String": "plain text",
+		"Quoted": "tab\there",
+		"Single": "it's",
+		"Int":    float64(42),
+		"Hex":    float64(31),
+		"Float":  float64(1500),
+		"Bool":   "yes",
+		"Null":   nil,
+		"Flow": map[string]interface{}{
+			"A": []interface{}{float64(1), "two"},
+			"B": map[string]interface{}{},
+		},
+		"Nested": map[string]interface{}{
+			"List": []interface{}{
+				float64(1),
+				map[string]interface{}{"Name": "x", "Enabled": true},
+				[]interface{}{"inner"},
+			},
+		},
+		"Literal": "line one\nline two\n",
+		"Folded":  "folded text",
+	}
+
+	if !reflect.DeepEqual(expected, parsed) {
+		t.Errorf("Expected %v, got %v", expected, parsed)
+	}
+}
+
+func TestYAMLErrors(t *testing.T) {
+
+	docs := map[string]int{
+		"A: 1\nA: 2\n":              2,
+		"A:\n  B: &anchor 1\n":      2,
+		"A: !!str 1\n":              1,
+		"A:\n\tB: 1\n":              2,
+		"A: 1\n---\nB: 2\n":         2,
+		"A: [1, 2\nB: 1\n":          1,
+		"- 1\n":                     1,
+		"A:\n  - 1\n  B: 2\n":       3,
+		"A: \"unterminated\nB: 2\n": 1,
+		"A: 1\nE: .inf\n":           2,
+		"A: -.Inf\n":                1,
+		"A:\n  - .nan\n":            2,
+	}
+
+	for doc, line := range docs {
+
+		var parsed interface{}
+
+		err := new(YAMLContentParser).ParseInto([]byte(doc), &parsed)
+
+		pe, found := err.(ParseError)
+
+		if !found {
+			t.Errorf("Expected a ParseError for %q, got %v", doc, err)
+			continue
+		}
+
+		if pe.Line != line {
+			t.Errorf("Expected error for %q on line %d, got %s", doc, line, pe.Error())
+		}
+	}
+
+	var parsed interface{}
+
+	if _, found := new(YAMLContentParser).ParseInto([]byte("# Only a comment\n"), &parsed).(EmptyFileError); !found {
+		t.Errorf("Expected an EmptyFileError for a document with no content")
+	}
+}
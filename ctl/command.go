@@ -57,10 +57,12 @@ func (s ByName) Less(i, j int) bool { return s.Commands[i].Name() < s.Commands[j
 
 type renderMode string
 
-// A hint to the grnc-ctl command on how to render the output of a Command - either as paragraphs of free text or as two columns.
+// A hint to the grnc-ctl command on how to render the output of a Command - either as paragraphs of free text, as two columns
+// or as raw lines of text that are displayed without wrapping or indentation.
 const (
 	Columns   = "COLUMNS"
 	Paragraph = "PARAGRAPH"
+	Raw       = "RAW"
 )
 
 const commandError = "COMMAND_ERROR"
@@ -75,7 +77,7 @@ type CommandOutput struct {
 	// columns.
 	OutputBody [][]string
 
-	// Whether grnc-ctl should render the OutputBody as Columns or Paragraph
+	// Whether grnc-ctl should render the OutputBody as Columns, Paragraph or Raw
 	RenderHint renderMode
 }
 
@@ -86,6 +86,12 @@ func (cf *ContextFilterBuilder) DependsOnFacilities() []string {
 	return []string{}
 }
 
+//OptionallyDependsOnFacilities returns the facilities whose components might implement or use logging.ContextFilter, so
+//that they are built before the filter is located.
+func (cf *ContextFilterBuilder) OptionallyDependsOnFacilities() []string {
+	return []string{"ApplicationLogging", "HTTPServer"}
+}
+
 func cfTypeMatcher(i interface{}) bool {
 
 	_, okay := i.(logging.ContextFilter)
@@ -133,3 +139,8 @@ func (id *filterDecorator) DecorateComponent(subject *ioc.Component, cc *ioc.Com
 	reflectComponent := reflect.ValueOf(subject.Instance).Elem()
 	reflectComponent.FieldByName(expectedFilterFieldName).Set(reflect.ValueOf(id.Filter))
 }
+
+// DecoratesScopedInstances returns true, as injecting the filter only modifies the subject component.
+func (id *filterDecorator) DecoratesScopedInstances() bool {
+	return true
+}
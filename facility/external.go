@@ -0,0 +1,124 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package facility
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// ExternalFacility is a facility that is not built in to Granitic, but can be enabled and configured in the same way as
+// built-in facilities.
+type ExternalFacility struct {
+	// The Builder that constructs the facility's components if the facility is enabled.
+	Builder Builder
+
+	// The facility's default configuration as a JSON object. It is merged over Granitic's built-in facility configuration
+	// and before your application's configuration files, so any value can be overridden by your application. If it
+	// does not contain a setting for the facility in the Facilities object, the facility is disabled by default.
+	DefaultConfig []byte
+}
+
+var (
+	externalMutex sync.Mutex
+	external      []*ExternalFacility
+)
+
+// Register makes a facility that is not built in to Granitic available to applications. It must be called before
+// Granitic is started (with granitic.StartGranitic or granitic.StartGraniticWithSettings), typically from the main
+// function of your application or from an init function in the package that contains the facility's Builder.
+//
+// defaultConfig is the facility's default configuration as a JSON object (see ExternalFacility). Problems with the
+// default configuration, or facilities with the same name as another facility, are reported when Granitic starts.
+func Register(b Builder, defaultConfig []byte) {
+
+	externalMutex.Lock()
+	defer externalMutex.Unlock()
+
+	external = append(external, &ExternalFacility{Builder: b, DefaultConfig: defaultConfig})
+}
+
+// Registered returns the facilities that have been registered with Register, in the order in which they were registered.
+func Registered() []*ExternalFacility {
+
+	externalMutex.Lock()
+	defer externalMutex.Unlock()
+
+	r := make([]*ExternalFacility, len(external))
+	copy(r, external)
+
+	return r
+}
+
+// RegisteredConfigLayers parses the default configuration of each registered facility so that it can be merged with
+// Granitic's built-in configuration. A new copy of the configuration is created each time this function is called.
+func RegisteredConfigLayers() ([]*config.Layer, error) {
+
+	var layers []*config.Layer
+
+	for _, ef := range Registered() {
+
+		name := ef.Builder.FacilityName()
+		defaults := make(map[string]interface{})
+
+		if len(ef.DefaultConfig) > 0 {
+
+			var parsed interface{}
+
+			if err := new(config.JSONContentParser).ParseInto(ef.DefaultConfig, &parsed); err != nil {
+				return nil, fmt.Errorf("unable to parse the default configuration of facility %s: %s", name, err)
+			}
+
+			m, found := parsed.(map[string]interface{})
+
+			if !found {
+				return nil, fmt.Errorf("the default configuration of facility %s must be a JSON object", name)
+			}
+
+			defaults = m
+		}
+
+		// Make sure the facility can be enabled in the same way as built-in facilities
+		fs, found := defaults["Facilities"].(map[string]interface{})
+
+		if !found {
+			fs = make(map[string]interface{})
+			defaults["Facilities"] = fs
+		}
+
+		if _, found := fs[name]; !found {
+			fs[name] = false
+		}
+
+		layers = append(layers, &config.Layer{Source: "facility:" + name, Config: defaults})
+	}
+
+	return layers, nil
+}
+
+// addExternalFacilities adds a Builder for each registered facility, checking that no two facilities share a name
+func (fi *FacilitiesInitialisor) addExternalFacilities() error {
+
+	names := make(map[string]bool)
+
+	for _, fb := range fi.facilities {
+		names[fb.FacilityName()] = true
+	}
+
+	for _, ef := range Registered() {
+
+		name := ef.Builder.FacilityName()
+
+		if names[name] {
+			return fmt.Errorf("unable to register facility %s as there is already a facility with that name", name)
+		}
+
+		names[name] = true
+		fi.addFacility(ef.Builder)
+	}
+
+	return nil
+}
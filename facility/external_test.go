@@ -0,0 +1,106 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package facility
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+// withRegistered runs the supplied function with an empty set of registered facilities
+func withRegistered(f func()) {
+
+	defer func() {
+		external = nil
+	}()
+
+	external = nil
+
+	f()
+}
+
+func TestRegisteredConfigLayers(t *testing.T) {
+
+	withRegistered(func() {
+
+		var built []string
+
+		Register(&stubBuilder{name: "MessageConsumer", built: &built}, []byte(`{"MessageConsumer": {"Queue": "events"}}`))
+		Register(&stubBuilder{name: "FeatureFlags", built: &built}, []byte(`{"Facilities": {"FeatureFlags": true}}`))
+		Register(&stubBuilder{name: "NoDefaults", built: &built}, nil)
+
+		layers, err := RegisteredConfigLayers()
+		test.ExpectNil(t, err)
+
+		test.ExpectInt(t, len(layers), 3)
+		test.ExpectString(t, layers[0].Source, "facility:MessageConsumer")
+
+		mc := layers[0].Config
+		test.ExpectString(t, mc["MessageConsumer"].(map[string]interface{})["Queue"].(string), "events")
+		test.ExpectBool(t, mc["Facilities"].(map[string]interface{})["MessageConsumer"].(bool), false)
+
+		test.ExpectBool(t, layers[1].Config["Facilities"].(map[string]interface{})["FeatureFlags"].(bool), true)
+		test.ExpectBool(t, layers[2].Config["Facilities"].(map[string]interface{})["NoDefaults"].(bool), false)
+
+		// Each call creates a new copy that can be safely modified by merging
+		again, _ := RegisteredConfigLayers()
+
+		mc["MessageConsumer"] = "changed"
+
+		if _, found := again[0].Config["MessageConsumer"].(map[string]interface{}); !found {
+			t.Errorf("Expected each call to return a new copy of the default configuration")
+		}
+	})
+}
+
+func TestInvalidRegisteredConfig(t *testing.T) {
+
+	withRegistered(func() {
+
+		Register(&stubBuilder{name: "Broken"}, []byte("{\n\"Broken\": }"))
+
+		_, err := RegisteredConfigLayers()
+
+		if err == nil || !strings.Contains(err.Error(), "facility Broken") || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Expected an error naming the facility and line, got %v", err)
+		}
+
+		external = nil
+
+		Register(&stubBuilder{name: "List"}, []byte("[]"))
+
+		_, err = RegisteredConfigLayers()
+		test.ExpectNotNil(t, err)
+	})
+}
+
+func TestRegisteredFacilitiesAreBuilt(t *testing.T) {
+
+	withRegistered(func() {
+
+		var built []string
+
+		Register(&stubBuilder{name: "MessageConsumer", deps: []string{"Builtin"}, built: &built}, nil)
+
+		fi := stubInitialisor(map[string]interface{}{"Builtin": true, "MessageConsumer": true},
+			&stubBuilder{name: "Builtin", built: &built})
+
+		test.ExpectNil(t, fi.addExternalFacilities())
+		test.ExpectNil(t, fi.buildEnabledFacilities())
+
+		test.ExpectString(t, strings.Join(built, ","), "Builtin,MessageConsumer")
+
+		Register(&stubBuilder{name: "Builtin", built: &built}, nil)
+
+		fi = stubInitialisor(map[string]interface{}{}, &stubBuilder{name: "Builtin", built: &built})
+
+		err := fi.addExternalFacilities()
+
+		if err == nil || !strings.Contains(err.Error(), "already a facility with that name") {
+			t.Errorf("Expected an error about a duplicate facility, got %v", err)
+		}
+	})
+}
@@ -30,7 +30,8 @@ which will look something like:
 		"RdbmsAccess": false,
 		"ServiceErrorManager": false,
 		"RuntimeCtl": false,
-		"TaskScheduler": false
+		"TaskScheduler": false,
+		"HealthCheck": false
 	  }
 	}
 
@@ -92,3 +93,20 @@ type Builder interface {
 	//correctly.
 	DependsOnFacilities() []string
 }
+
+// A SchemaProvider is a Builder that can describe the structure of its facility's configuration. If a Builder implements
+// this interface and its facility is enabled, merged configuration is checked against the returned schemas before
+// any facility is built.
+type SchemaProvider interface {
+	// ConfigSchemas returns schemas describing each subtree of configuration used by the facility.
+	ConfigSchemas() []*config.Schema
+}
+
+// An OptionalDependencyProvider is a Builder that can make use of other facilities if they are enabled, but does not
+// require them. Enabled optional dependencies are built before this facility. The Builder can check which of its
+// optional dependencies are enabled with IsEnabled.
+type OptionalDependencyProvider interface {
+	// OptionallyDependsOnFacilities returns the names of facilities that should be built before this facility if they
+	// are enabled.
+	OptionallyDependsOnFacilities() []string
+}
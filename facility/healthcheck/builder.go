@@ -0,0 +1,51 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+/*
+Package healthcheck provides the HealthCheck facility which adds liveness and readiness endpoints to the HTTPServer facility.
+
+See package health and https://granitic.io/ref/health-check for more details.
+*/
+package healthcheck
+
+import (
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/health"
+	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+)
+
+const facilityName = "HealthCheck"
+
+// HealthCheckComponentName is the name of the health check endpoint component as stored in the IoC framework.
+const HealthCheckComponentName = instance.FrameworkPrefix + facilityName
+
+// FacilityBuilder creates the components that make up the HealthCheck facility
+type FacilityBuilder struct {
+}
+
+// BuildAndRegister implements FacilityBuilder.BuildAndRegister
+func (fb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager, ca *config.Accessor, cn *ioc.ComponentContainer) error {
+
+	e := new(health.Endpoint)
+	e.FrameworkLogger = lm.CreateLogger(HealthCheckComponentName)
+
+	if err := ca.Populate(facilityName, e); err != nil {
+		return err
+	}
+
+	cn.WrapAndAddProto(HealthCheckComponentName, e)
+
+	return nil
+}
+
+// FacilityName implements FacilityBuilder.FacilityName
+func (fb *FacilityBuilder) FacilityName() string {
+	return facilityName
+}
+
+// DependsOnFacilities implements FacilityBuilder.DependsOnFacilities
+func (fb *FacilityBuilder) DependsOnFacilities() []string {
+	return []string{"HTTPServer"}
+}
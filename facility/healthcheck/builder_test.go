@@ -0,0 +1,121 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/facility/httpserver"
+	"github.com/graniticio/granitic/v2/health"
+	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+	"github.com/graniticio/granitic/v2/ws"
+)
+
+func TestFacilityNaming(t *testing.T) {
+
+	fb := new(FacilityBuilder)
+
+	if fb.FacilityName() != "HealthCheck" {
+		t.Errorf("Unexpected facility name %s", fb.FacilityName())
+	}
+
+	test.ExpectString(t, fb.DependsOnFacilities()[0], "HTTPServer")
+}
+
+func TestBuilderWithDefaultConfig(t *testing.T) {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	configLoc, err := test.FindFacilityConfigFromWD()
+	test.ExpectNil(t, err)
+
+	jf, err := config.FindJSONFilesInDir(configLoc)
+	test.ExpectNil(t, err)
+
+	merged, err := config.NewJSONMergerWithManagedLogging(lm, new(config.JSONContentParser)).LoadAndMergeConfig(jf)
+	test.ExpectNil(t, err)
+
+	ca := &config.Accessor{JSONData: merged, FrameworkLogger: lm.CreateLogger("ca")}
+
+	cc := ioc.NewComponentContainer(lm, ca, new(instance.System))
+
+	test.ExpectNil(t, new(FacilityBuilder).BuildAndRegister(lm, ca, cc))
+	test.ExpectNil(t, cc.Populate())
+
+	e := cc.ComponentByName(HealthCheckComponentName).Instance.(*health.Endpoint)
+
+	test.ExpectString(t, e.LivePath, "/health/live")
+	test.ExpectString(t, e.ReadyPath, "/health/ready")
+
+	if e.Timeout != 2*time.Second {
+		t.Errorf("Expected a timeout of 2s, got %v", e.Timeout)
+	}
+
+	test.ExpectNil(t, e.StartComponent())
+}
+
+type statusOnlyWriter struct{}
+
+func (sw *statusOnlyWriter) WriteAbnormalStatus(ctx context.Context, state *ws.ProcessState) error {
+	state.HTTPResponseWriter.WriteHeader(state.Status)
+	return nil
+}
+
+func TestEndpointOnlyMatchesHealthPaths(t *testing.T) {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	configLoc, err := test.FindFacilityConfigFromWD()
+	test.ExpectNil(t, err)
+
+	jf, err := config.FindJSONFilesInDir(configLoc)
+	test.ExpectNil(t, err)
+
+	merged, err := config.NewJSONMergerWithManagedLogging(lm, new(config.JSONContentParser)).LoadAndMergeConfig(jf)
+	test.ExpectNil(t, err)
+
+	ca := &config.Accessor{JSONData: merged, FrameworkLogger: lm.CreateLogger("ca")}
+
+	cc := ioc.NewComponentContainer(lm, ca, new(instance.System))
+
+	test.ExpectNil(t, new(httpserver.FacilityBuilder).BuildAndRegister(lm, ca, cc))
+	test.ExpectNil(t, new(FacilityBuilder).BuildAndRegister(lm, ca, cc))
+	test.ExpectNil(t, cc.Populate())
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	test.ExpectNil(t, err)
+
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	s := cc.ComponentByName(httpserver.HTTPServerComponentName).Instance.(*httpserver.HTTPServer)
+	s.Port = port
+	s.Address = "127.0.0.1"
+	s.AbnormalStatusWriter = new(statusOnlyWriter)
+	s.FrameworkLogger = lm.CreateLogger(httpserver.HTTPServerComponentName)
+
+	// Components are started in the order the container determines, so the HTTPServer starts before the health endpoint
+	test.ExpectNil(t, cc.Lifecycle.StartAll())
+	defer s.Stop()
+
+	for path, status := range map[string]int{"/health/live": http.StatusOK, "/health/ready": http.StatusOK, "/anything/at/all": http.StatusNotFound} {
+
+		res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, path))
+		test.ExpectNil(t, err)
+		res.Body.Close()
+
+		if res.StatusCode != status {
+			t.Errorf("Expected %d for %s, got %d", status, path, res.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package healthcheck
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// ConfigSchemas implements facility.SchemaProvider
+func (fb *FacilityBuilder) ConfigSchemas() []*config.Schema {
+
+	path := &config.SchemaField{Type: config.StringValue, Required: true, Check: checkPath}
+
+	return []*config.Schema{{
+		Path: facilityName,
+		Fields: map[string]*config.SchemaField{
+			"LivePath":  path,
+			"ReadyPath": path,
+			"Timeout":   {Type: config.StringValue, Required: true, Check: checkTimeout},
+		},
+	}}
+}
+
+func checkPath(v interface{}) error {
+
+	if !strings.HasPrefix(v.(string), "/") {
+		return errors.New("paths must start with /")
+	}
+
+	return nil
+}
+
+func checkTimeout(v interface{}) error {
+
+	d, err := time.ParseDuration(v.(string))
+
+	if err != nil || d <= 0 {
+		return errors.New("must be a positive duration (e.g. 2s or 500ms)")
+	}
+
+	return nil
+}
@@ -141,8 +141,7 @@ func (alw *AccessLogWriter) StartComponent() error {
 }
 
 func (alw *AccessLogWriter) watchLineBuffer() {
-	for {
-		line := <-alw.lines
+	for line := range alw.lines {
 
 		f := alw.logFile
 
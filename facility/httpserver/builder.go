@@ -14,12 +14,16 @@ import (
 	"github.com/graniticio/granitic/v2/uuid"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // HTTPServerComponentName is the name of the HTTPServer component as stored in the IoC framework.
 const HTTPServerComponentName = instance.FrameworkPrefix + "HTTPServer"
 const contextIDDecoratorName = instance.FrameworkPrefix + "RequestIDContextDecorator"
 const instrumentationDecoratorName = instance.FrameworkPrefix + "RequestInstrumentationDecorator"
+const middlewareDecoratorName = instance.FrameworkPrefix + "MiddlewareDecorator"
+const compressionMiddlewareName = instance.FrameworkPrefix + "CompressionMiddleware"
+const contentEncoderDecoratorName = instance.FrameworkPrefix + "ContentEncoderDecorator"
 
 const textEntryMode = "TEXT"
 const jsonEntryMode = "JSON"
@@ -43,10 +47,15 @@ func (hsfb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager
 	log := lm.CreateLogger(instance.FrameworkPrefix + "FacilityBuilder")
 
 	httpServer := new(HTTPServer)
-	ca.Populate("HTTPServer", httpServer)
+
+	if err := ca.Populate("HTTPServer", httpServer); err != nil {
+		return err
+	}
 
 	cn.WrapAndAddProto(HTTPServerComponentName, httpServer)
 
+	hsfb.checkDrainBudget(ca, log, httpServer)
+
 	if httpServer.AccessLogging {
 		if err := hsfb.setupAccessLogging(ca, log, httpServer, cn); err != nil {
 			return err
@@ -57,6 +66,24 @@ func (hsfb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager
 	idbd.Server = httpServer
 	cn.WrapAndAddProto(contextIDDecoratorName, idbd)
 
+	md := new(middlewareDecorator)
+	md.Server = httpServer
+	md.Log = lm.CreateLogger(middlewareDecoratorName)
+	cn.WrapAndAddProto(middlewareDecoratorName, md)
+
+	if httpServer.Compression.Enabled || httpServer.Compression.DecompressRequests {
+
+		cm := new(compressionMiddleware)
+		cm.config = &httpServer.Compression
+		cm.server = httpServer
+		cn.WrapAndAddProto(compressionMiddlewareName, cm)
+
+		ced := new(contentEncoderDecorator)
+		ced.Server = httpServer
+		ced.Log = lm.CreateLogger(contentEncoderDecoratorName)
+		cn.WrapAndAddProto(contentEncoderDecoratorName, ced)
+	}
+
 	if !httpServer.DisableInstrumentationAutoWire {
 
 		log.LogDebugf("Will attempt to auto-wire an implementation of instrument.RequestInstrumentationManager")
@@ -79,9 +106,32 @@ func (hsfb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager
 
 }
 
+// checkDrainBudget warns if the HTTP server might not finish draining before the container stops waiting for it
+func (hsfb *FacilityBuilder) checkDrainBudget(ca *config.Accessor, log logging.Logger, httpServer *HTTPServer) {
+
+	stopRetries, err := ca.IntVal("System.StopRetries")
+
+	if err != nil {
+		return
+	}
+
+	stopInterval, err := ca.IntVal("System.StopIntervalMS")
+
+	if err != nil {
+		return
+	}
+
+	if err := checkDrainBudget(httpServer.Drain, stopRetries, time.Duration(stopInterval)*time.Millisecond); err != nil {
+		log.LogWarnf("Connections may be closed before the HTTP server has finished draining: %s", err.Error())
+	}
+}
+
 func (hsfb *FacilityBuilder) setupAccessLogging(ca *config.Accessor, log logging.Logger, httpServer *HTTPServer, cn *ioc.ComponentContainer) error {
 	accessLogWriter := new(AccessLogWriter)
-	ca.Populate("HTTPServer.AccessLog", accessLogWriter)
+
+	if err := ca.Populate("HTTPServer.AccessLog", accessLogWriter); err != nil {
+		return err
+	}
 
 	var lb LineBuilder
 	var mode string
@@ -105,7 +155,11 @@ func (hsfb *FacilityBuilder) setupAccessLogging(ca *config.Accessor, log logging
 		jlb := new(JSONLineBuilder)
 
 		jc := new(AccessLogJSONConfig)
-		ca.Populate("HTTPServer.AccessLog.JSON", jc)
+
+		if err := ca.Populate("HTTPServer.AccessLog.JSON", jc); err != nil {
+			return err
+		}
+
 		jlb.Config = jc
 
 		jc.ParsedFields = ConvertFields(jc.Fields)
@@ -9,6 +9,7 @@ import (
 	"github.com/graniticio/granitic/v2/test"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 )
@@ -59,6 +60,24 @@ func TestBuilderWithDefaultConfig(t *testing.T) {
 
 }
 
+func TestBuilderReportsUnbindableConfig(t *testing.T) {
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	ca, err := configAccessor(lm, test.FilePath("badport.json"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	cc := ioc.NewComponentContainer(lm, ca, new(instance.System))
+
+	if err = new(FacilityBuilder).BuildAndRegister(lm, ca, cc); err == nil {
+		t.Fatalf("Expected an error when HTTPServer.Port cannot be bound")
+	}
+
+	test.ExpectBool(t, strings.Contains(err.Error(), "HTTPServer.Port"), true)
+}
+
 func TestBuilderWithJSONConfig(t *testing.T) {
 	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
 
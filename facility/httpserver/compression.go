@@ -0,0 +1,475 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+)
+
+// Encodings for CompressionConfig.Encodings that are built in to the HTTPServer
+const (
+	// GzipEncoding compresses responses in the gzip format (RFC 1952).
+	GzipEncoding = "gzip"
+
+	// DeflateEncoding compresses responses in the zlib format (RFC 1950), which is what HTTP calls deflate.
+	DeflateEncoding = "deflate"
+)
+
+// ContentEncoder is implemented by components that add support for an encoding that is not built in to the HTTPServer
+// (for example br, using a third-party Brotli library) or replace the implementation of a built-in encoding. Components
+// implementing this interface are automatically found in the IoC container and their encodings may then be listed in
+// CompressionConfig.Encodings.
+type ContentEncoder interface {
+	// Encoding returns the name of the encoding as used in Accept-Encoding and Content-Encoding headers (e.g. br).
+	Encoding() string
+
+	// NewWriter returns a writer that compresses data written to it and writes the result to w. The level is the value
+	// of CompressionConfig.Level, which the encoder may interpret as it sees fit. Closing the writer must finish the
+	// compressed stream without closing w.
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+}
+
+// CompressionMiddlewarePriority is the priority of the Middleware that compresses responses and decompresses requests.
+// It is higher than most application Middleware will use, so that responses written by other Middleware are also compressed.
+const CompressionMiddlewarePriority = 1000
+
+// CompressionConfig controls whether an HTTPServer compresses responses for clients that support it and decompresses
+// request bodies sent by clients.
+type CompressionConfig struct {
+	// Whether or not responses should be compressed if the client's Accept-Encoding header allows it.
+	Enabled bool
+
+	// The encodings the server may use, in order of preference. gzip, deflate and the encodings of any ContentEncoder
+	// components are supported.
+	Encodings []string
+
+	// The compression level. For gzip and deflate, from 1 (fastest) to 9 (smallest) with zero or -1 meaning the default
+	// level. Passed unchanged to ContentEncoders.
+	Level int
+
+	// Responses with bodies smaller than this number of bytes are not compressed.
+	MinSize int
+
+	// The content types of responses that may be compressed. An entry ending in /* (e.g. text/*) matches any subtype.
+	ContentTypes []string
+
+	// Whether or not request bodies with a Content-Encoding of gzip should be decompressed before they are processed.
+	DecompressRequests bool
+
+	// The maximum number of bytes a decompressed request body may contain. Reading beyond this limit returns an error
+	// (and the connection is closed after the response is sent). Zero means no limit.
+	MaxDecompressedSize int64
+
+	// ContentEncoders added with AddEncoder, by encoding
+	encoders map[string]ContentEncoder
+}
+
+// AddEncoder makes the supplied ContentEncoder's encoding available for use in Encodings, replacing any existing
+// encoder for that encoding (including the built-in gzip and deflate encoders).
+func (cc *CompressionConfig) AddEncoder(e ContentEncoder) {
+
+	if cc.encoders == nil {
+		cc.encoders = make(map[string]ContentEncoder)
+	}
+
+	cc.encoders[strings.ToLower(e.Encoding())] = e
+}
+
+// encoder returns the ContentEncoder for the supplied encoding, or nil if the encoding is not supported
+func (cc *CompressionConfig) encoder(encoding string) ContentEncoder {
+
+	if e := cc.encoders[encoding]; e != nil {
+		return e
+	}
+
+	switch encoding {
+	case GzipEncoding:
+		return gzipEncoder{}
+	case DeflateEncoding:
+		return deflateEncoder{}
+	}
+
+	return nil
+}
+
+// supported returns the sorted names of all of the encodings that may be used
+func (cc *CompressionConfig) supported() string {
+
+	names := []string{GzipEncoding, DeflateEncoding}
+
+	for e := range cc.encoders {
+		if e != GzipEncoding && e != DeflateEncoding {
+			names = append(names, e)
+		}
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// validate checks that the configured encodings and level are supported
+func (cc *CompressionConfig) validate() error {
+
+	if !cc.Enabled {
+		return nil
+	}
+
+	if len(cc.Encodings) == 0 {
+		return fmt.Errorf("HTTPServer.Compression.Encodings must contain at least one of %s", cc.supported())
+	}
+
+	builtIn := false
+
+	for _, e := range cc.Encodings {
+
+		encoder := cc.encoder(e)
+
+		if encoder == nil {
+			return fmt.Errorf("%q is not a supported value for HTTPServer.Compression.Encodings. Should be one of %s", e, cc.supported())
+		}
+
+		switch encoder.(type) {
+		case gzipEncoder, deflateEncoder:
+			builtIn = true
+		}
+	}
+
+	if builtIn && (cc.Level < -1 || cc.Level > 9) {
+		return fmt.Errorf("HTTPServer.Compression.Level must be between -1 and 9 (was %d)", cc.Level)
+	}
+
+	return nil
+}
+
+// negotiate returns the most preferred of the configured encodings that is acceptable according to the supplied
+// Accept-Encoding header, or an empty string if none are acceptable.
+func (cc *CompressionConfig) negotiate(acceptEncoding string) string {
+
+	qualities := make(map[string]float64)
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+
+		if coding == "" {
+			continue
+		}
+
+		q := 1.0
+
+		for _, p := range fields[1:] {
+
+			p = strings.TrimSpace(p)
+
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		qualities[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+
+	for _, e := range cc.Encodings {
+
+		q, found := qualities[e]
+
+		if !found {
+			q, found = qualities["*"]
+		}
+
+		if found && q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+
+	return best
+}
+
+// compressible returns true if a response with the supplied Content-Type header may be compressed
+func (cc *CompressionConfig) compressible(contentType string) bool {
+
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+
+	for _, allowed := range cc.ContentTypes {
+
+		allowed = strings.ToLower(allowed)
+
+		if allowed == mediaType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, allowed[:len(allowed)-1])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compressionMiddleware is the Middleware that compresses responses and decompresses requests according to a
+// CompressionConfig. It is created by the HTTPServer facility builder if compression is enabled.
+type compressionMiddleware struct {
+	config *CompressionConfig
+	server *HTTPServer
+}
+
+// Handle implements Middleware.Handle
+func (cm *compressionMiddleware) Handle(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request, next NextFunc) context.Context {
+
+	if cm.config.DecompressRequests && strings.EqualFold(req.Header.Get("Content-Encoding"), GzipEncoding) {
+
+		zr, err := gzip.NewReader(req.Body)
+
+		if err != nil {
+			cm.server.writeAbnormal(ctx, http.StatusBadRequest, w, fmt.Errorf("unable to decompress gzip request body: %s", err.Error()))
+			return ctx
+		}
+
+		var body io.ReadCloser = &decompressedBody{Reader: zr, compressed: req.Body}
+
+		if max := cm.config.MaxDecompressedSize; max > 0 {
+			// Protects against small bodies that expand to exhaust memory
+			body = http.MaxBytesReader(w, body, max)
+		}
+
+		req.Body = body
+		req.Header.Del("Content-Encoding")
+		req.Header.Del("Content-Length")
+		req.ContentLength = -1
+	}
+
+	if !cm.config.Enabled {
+		return next(ctx, w, req)
+	}
+
+	encoding := cm.config.negotiate(req.Header.Get("Accept-Encoding"))
+
+	if encoding == "" {
+		return next(ctx, w, req)
+	}
+
+	var cw *compressingWriter
+
+	w.WrapWriter(func(rw http.ResponseWriter) http.ResponseWriter {
+		cw = &compressingWriter{ResponseWriter: rw, config: cm.config, encoding: encoding}
+		return cw
+	})
+
+	ctx = next(ctx, w, req)
+
+	if err := cw.close(); err != nil {
+		cm.server.FrameworkLogger.LogErrorfCtx(ctx, "Problem compressing response: %s", err.Error())
+	}
+
+	return ctx
+}
+
+// Priority implements MiddlewarePriority.Priority
+func (cm *compressionMiddleware) Priority() int64 {
+	return CompressionMiddlewarePriority
+}
+
+// decompressedBody replaces the body of a request that was sent compressed, so that closing it closes both the
+// decompressor and the original body
+type decompressedBody struct {
+	*gzip.Reader
+	compressed io.ReadCloser
+}
+
+func (db *decompressedBody) Close() error {
+	db.Reader.Close()
+
+	return db.compressed.Close()
+}
+
+// compressingWriter buffers the start of a response until it can decide whether or not the response should be
+// compressed (based on its size, status and content type), then writes the response through a compressor if appropriate.
+type compressingWriter struct {
+	http.ResponseWriter
+	config   *CompressionConfig
+	encoding string
+
+	status     int
+	buffer     []byte
+	decided    bool
+	compressor io.WriteCloser
+}
+
+// WriteHeader records the status code, which is not sent until the writer has decided whether or not to compress the response
+func (cw *compressingWriter) WriteHeader(status int) {
+
+	if cw.decided {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+// Write buffers data until there is enough to decide whether or not to compress the response
+func (cw *compressingWriter) Write(b []byte) (int, error) {
+
+	if cw.decided {
+
+		if cw.compressor != nil {
+			return cw.compressor.Write(b)
+		}
+
+		return cw.ResponseWriter.Write(b)
+	}
+
+	cw.buffer = append(cw.buffer, b...)
+
+	if len(cw.buffer) >= cw.config.MinSize {
+		if err := cw.decide(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// decide sends the response's headers, with a Content-Encoding if the response is to be compressed, then writes any buffered data
+func (cw *compressingWriter) decide() error {
+
+	cw.decided = true
+
+	h := cw.Header()
+
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+
+	if len(cw.buffer) > 0 && h.Get("Content-Type") == "" {
+		h.Set("Content-Type", http.DetectContentType(cw.buffer))
+	}
+
+	if h.Get("Content-Encoding") == "" && cw.config.compressible(h.Get("Content-Type")) {
+
+		h.Add("Vary", "Accept-Encoding")
+
+		if len(cw.buffer) > 0 && len(cw.buffer) >= cw.config.MinSize && bodyAllowed(cw.status) {
+
+			var err error
+
+			if cw.compressor, err = cw.config.encoder(cw.encoding).NewWriter(cw.ResponseWriter, cw.config.Level); err != nil {
+				return err
+			}
+
+			h.Set("Content-Encoding", cw.encoding)
+			h.Del("Content-Length")
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buffered := cw.buffer
+	cw.buffer = nil
+
+	if len(buffered) == 0 {
+		return nil
+	}
+
+	_, err := cw.Write(buffered)
+
+	return err
+}
+
+// close sends any data that is still buffered and finishes the compressed stream
+func (cw *compressingWriter) close() error {
+
+	if !cw.decided {
+
+		if cw.status == 0 && len(cw.buffer) == 0 {
+			// Nothing was written to the response
+			return nil
+		}
+
+		if err := cw.decide(); err != nil {
+			return err
+		}
+	}
+
+	if cw.compressor != nil {
+		return cw.compressor.Close()
+	}
+
+	return nil
+}
+
+// bodyAllowed returns false for statuses that must not have a body
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// gzipEncoder is the built-in ContentEncoder for GzipEncoding
+type gzipEncoder struct{}
+
+func (gzipEncoder) Encoding() string {
+	return GzipEncoding
+}
+
+func (gzipEncoder) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, defaultLevel(level))
+}
+
+// deflateEncoder is the built-in ContentEncoder for DeflateEncoding
+type deflateEncoder struct{}
+
+func (deflateEncoder) Encoding() string {
+	return DeflateEncoding
+}
+
+func (deflateEncoder) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	return zlib.NewWriterLevel(w, defaultLevel(level))
+}
+
+// defaultLevel converts a level of zero to the default level used by compress/flate
+func defaultLevel(level int) int {
+
+	if level == 0 {
+		return -1
+	}
+
+	return level
+}
+
+// Adds components that implement ContentEncoder to the HTTP server's CompressionConfig
+type contentEncoderDecorator struct {
+	Server *HTTPServer
+	Log    logging.Logger
+}
+
+// OfInterest returns true if the supplied component is an instance of ContentEncoder
+func (ced *contentEncoderDecorator) OfInterest(subject *ioc.Component) bool {
+	_, found := subject.Instance.(ContentEncoder)
+
+	return found
+}
+
+// DecorateComponent adds the ContentEncoder to the HTTP server's CompressionConfig
+func (ced *contentEncoderDecorator) DecorateComponent(subject *ioc.Component, cc *ioc.ComponentContainer) {
+
+	e := subject.Instance.(ContentEncoder)
+
+	ced.Log.LogDebugf("HTTP server using %s to encode %s responses", subject.Name, e.Encoding())
+
+	ced.Server.Compression.AddEncoder(e)
+}
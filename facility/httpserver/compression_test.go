@@ -0,0 +1,325 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type payloadProvider struct {
+	routedProvider
+	contentType string
+	body        string
+	received    string
+}
+
+func (p *payloadProvider) ServeHTTP(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+
+	b, _ := ioutil.ReadAll(req.Body)
+	p.received = string(b)
+
+	if p.contentType != "" {
+		w.Header().Set("Content-Type", p.contentType)
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	// Write in two parts to exercise buffering
+	half := len(p.body) / 2
+	w.Write([]byte(p.body[:half]))
+	w.Write([]byte(p.body[half:]))
+
+	return ctx
+}
+
+// servedRecorder records the number of bytes the HTTPResponseWriter reports as served
+type servedRecorder struct {
+	served int
+}
+
+func (sr *servedRecorder) Handle(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request, next NextFunc) context.Context {
+	ctx = next(ctx, w, req)
+	sr.served = w.BytesServed
+
+	return ctx
+}
+
+func (sr *servedRecorder) Priority() int64 {
+	return CompressionMiddlewarePriority + 1
+}
+
+func compressingServer(t *testing.T, cc CompressionConfig, p *payloadProvider) (*HTTPServer, *servedRecorder) {
+
+	s := runningServer(t, map[string]httpendpoint.Provider{"payload": p})
+	s.Compression = cc
+
+	test.ExpectNil(t, s.Compression.validate())
+
+	sr := new(servedRecorder)
+
+	s.AddMiddleware("compression", &compressionMiddleware{config: &s.Compression, server: s})
+	s.AddMiddleware("recorder", sr)
+
+	return s, sr
+}
+
+func defaultCompression() CompressionConfig {
+	return CompressionConfig{
+		Enabled:      true,
+		Encodings:    []string{GzipEncoding, DeflateEncoding},
+		Level:        -1,
+		MinSize:      100,
+		ContentTypes: []string{"application/json", "text/*"},
+	}
+}
+
+func compressionRequest(s *HTTPServer, method, acceptEncoding string, body []byte, headers map[string]string) *httptest.ResponseRecorder {
+
+	req := httptest.NewRequest(method, "/payload", bytes.NewReader(body))
+
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleAll(rec, req)
+
+	return rec
+}
+
+func TestResponseCompression(t *testing.T) {
+
+	body := `{"artists":["` + strings.Repeat("abcdefghij", 50) + `"]}`
+
+	p := &payloadProvider{routedProvider: routedProvider{name: "payload", methods: []string{"GET"}, template: "/payload"}, contentType: "application/json; charset=utf-8", body: body}
+
+	s, sr := compressingServer(t, defaultCompression(), p)
+
+	rec := compressionRequest(s, "GET", "br;q=1.0, gzip;q=0.8, deflate;q=0.5", nil, nil)
+
+	test.ExpectInt(t, rec.Code, http.StatusOK)
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "gzip")
+	test.ExpectString(t, rec.Header().Get("Vary"), "Accept-Encoding")
+	test.ExpectInt(t, sr.served, rec.Body.Len())
+
+	if rec.Body.Len() >= len(body) {
+		t.Errorf("Expected compressed body to be smaller than %d bytes, was %d", len(body), rec.Body.Len())
+	}
+
+	zr, err := gzip.NewReader(rec.Body)
+	test.ExpectNil(t, err)
+
+	b, _ := ioutil.ReadAll(zr)
+	test.ExpectString(t, string(b), body)
+
+	rec = compressionRequest(s, "GET", "gzip;q=0.2, deflate", nil, nil)
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "deflate")
+
+	zlr, err := zlib.NewReader(rec.Body)
+	test.ExpectNil(t, err)
+
+	b, _ = ioutil.ReadAll(zlr)
+	test.ExpectString(t, string(b), body)
+
+	for _, ae := range []string{"", "identity", "br", "gzip;q=0, deflate;q=0"} {
+		rec = compressionRequest(s, "GET", ae, nil, nil)
+		test.ExpectString(t, rec.Header().Get("Content-Encoding"), "")
+		test.ExpectString(t, rec.Body.String(), body)
+		test.ExpectInt(t, sr.served, len(body))
+	}
+
+	rec = compressionRequest(s, "GET", "*", nil, nil)
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "gzip")
+
+	// HEAD responses have no body to compress
+	rec = compressionRequest(s, "HEAD", "gzip", nil, nil)
+	test.ExpectInt(t, rec.Code, http.StatusOK)
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "")
+	test.ExpectInt(t, rec.Body.Len(), 0)
+}
+
+func TestResponsesNotCompressed(t *testing.T) {
+
+	p := &payloadProvider{routedProvider: routedProvider{name: "payload", methods: []string{"GET"}, template: "/payload"}, contentType: "application/json", body: `{"small":true}`}
+
+	s, sr := compressingServer(t, defaultCompression(), p)
+
+	// Smaller than MinSize
+	rec := compressionRequest(s, "GET", "gzip", nil, nil)
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "")
+	test.ExpectString(t, rec.Header().Get("Vary"), "Accept-Encoding")
+	test.ExpectString(t, rec.Body.String(), p.body)
+	test.ExpectInt(t, sr.served, len(p.body))
+
+	// Content type not in allowlist
+	p.contentType = "image/png"
+	p.body = strings.Repeat("x", 500)
+
+	rec = compressionRequest(s, "GET", "gzip", nil, nil)
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "")
+	test.ExpectInt(t, rec.Body.Len(), 500)
+
+	// Content type detected from the body
+	p.contentType = ""
+
+	rec = compressionRequest(s, "GET", "gzip", nil, nil)
+	test.ExpectString(t, rec.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "gzip")
+}
+
+func TestRequestDecompression(t *testing.T) {
+
+	p := &payloadProvider{routedProvider: routedProvider{name: "payload", methods: []string{"POST"}, template: "/payload"}, contentType: "application/json", body: "{}"}
+
+	cc := defaultCompression()
+	cc.DecompressRequests = true
+
+	s, _ := compressingServer(t, cc, p)
+
+	var compressed bytes.Buffer
+
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(`{"name":"Beatles"}`))
+	zw.Close()
+
+	rec := compressionRequest(s, "POST", "", compressed.Bytes(), map[string]string{"Content-Encoding": "gzip"})
+	test.ExpectInt(t, rec.Code, http.StatusOK)
+	test.ExpectString(t, p.received, `{"name":"Beatles"}`)
+
+	rec = compressionRequest(s, "POST", "", []byte("not gzip"), map[string]string{"Content-Encoding": "gzip"})
+	test.ExpectInt(t, rec.Code, http.StatusBadRequest)
+
+	s.Compression.DecompressRequests = false
+
+	compressionRequest(s, "POST", "", compressed.Bytes(), map[string]string{"Content-Encoding": "gzip"})
+	test.ExpectString(t, p.received, compressed.String())
+}
+
+func TestRequestDecompressionLimit(t *testing.T) {
+
+	p := &payloadProvider{routedProvider: routedProvider{name: "payload", methods: []string{"POST"}, template: "/payload"}, contentType: "application/json", body: "{}"}
+
+	cc := defaultCompression()
+	cc.DecompressRequests = true
+	cc.MaxDecompressedSize = 1024
+
+	s, _ := compressingServer(t, cc, p)
+
+	var compressed bytes.Buffer
+
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(bytes.Repeat([]byte("a"), 1024*1024))
+	zw.Close()
+
+	compressionRequest(s, "POST", "", compressed.Bytes(), map[string]string{"Content-Encoding": "gzip"})
+	test.ExpectInt(t, len(p.received), 1024)
+
+	s.Compression.MaxDecompressedSize = 0
+
+	compressionRequest(s, "POST", "", compressed.Bytes(), map[string]string{"Content-Encoding": "gzip"})
+	test.ExpectInt(t, len(p.received), 1024*1024)
+}
+
+func TestCompressionConfigValidation(t *testing.T) {
+
+	cc := defaultCompression()
+	cc.Encodings = []string{"br"}
+
+	test.ExpectNotNil(t, cc.validate())
+
+	cc.AddEncoder(new(upperEncoder))
+	test.ExpectNil(t, cc.validate())
+
+	// The level is only restricted for the built-in encoders
+	cc.Level = 11
+	test.ExpectNil(t, cc.validate())
+
+	cc.Encodings = []string{"br", GzipEncoding}
+	test.ExpectNotNil(t, cc.validate())
+
+	cc = defaultCompression()
+	cc.Level = 10
+
+	test.ExpectNotNil(t, cc.validate())
+
+	cc.Enabled = false
+
+	test.ExpectNil(t, cc.validate())
+}
+
+// upperEncoder is a ContentEncoder for testing that 'compresses' by converting to upper case
+type upperEncoder struct {
+	level int
+}
+
+func (ue *upperEncoder) Encoding() string {
+	return "br"
+}
+
+func (ue *upperEncoder) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	ue.level = level
+
+	return &upperWriter{w: w}, nil
+}
+
+type upperWriter struct {
+	w io.Writer
+}
+
+func (uw *upperWriter) Write(b []byte) (int, error) {
+	return uw.w.Write(bytes.ToUpper(b))
+}
+
+func (uw *upperWriter) Close() error {
+	return nil
+}
+
+func TestAddedContentEncoder(t *testing.T) {
+
+	body := strings.Repeat("abcdefghij", 50)
+
+	p := &payloadProvider{routedProvider: routedProvider{name: "payload", methods: []string{"GET"}, template: "/payload"}, contentType: "text/plain", body: body}
+
+	cc := defaultCompression()
+	cc.Encodings = []string{"br", GzipEncoding}
+	cc.Level = 5
+
+	ue := new(upperEncoder)
+
+	ced := &contentEncoderDecorator{Server: &HTTPServer{Compression: cc}, Log: new(logging.NullLogger)}
+	component := ioc.NewComponent("brotli", ue)
+
+	test.ExpectBool(t, ced.OfInterest(component), true)
+	test.ExpectBool(t, ced.OfInterest(ioc.NewComponent("other", new(servedRecorder))), false)
+
+	ced.DecorateComponent(component, nil)
+
+	s, _ := compressingServer(t, ced.Server.Compression, p)
+
+	rec := compressionRequest(s, "GET", "gzip, br", nil, nil)
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "br")
+	test.ExpectString(t, rec.Body.String(), strings.ToUpper(body))
+	test.ExpectInt(t, ue.level, 5)
+
+	rec = compressionRequest(s, "GET", "gzip", nil, nil)
+	test.ExpectString(t, rec.Header().Get("Content-Encoding"), "gzip")
+}
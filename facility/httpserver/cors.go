@@ -0,0 +1,141 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+)
+
+// corsPolicy returns the CORS policy of the supplied Provider, or the server's policy if the Provider is nil or does
+// not have its own policy
+func (h *HTTPServer) corsPolicy(p httpendpoint.Provider) *httpendpoint.CORSPolicy {
+
+	if ca, found := p.(httpendpoint.CORSAware); found {
+		if cp := ca.CORSPolicy(); cp != nil {
+			return cp
+		}
+	}
+
+	return &h.CORS
+}
+
+// answerPreflight writes a response to a CORS preflight request (an OPTIONS request with Origin and
+// Access-Control-Request-Method headers) if the Provider that would handle the actual request has an active CORS
+// policy. Returns false if the request was not answered and should be processed normally.
+func (h *HTTPServer) answerPreflight(path string, req *http.Request, wrw *httpendpoint.HTTPResponseWriter) bool {
+
+	if !isPreflight(req) {
+		return false
+	}
+
+	origin := req.Header.Get("Origin")
+	method := req.Header.Get("Access-Control-Request-Method")
+
+	// Browsers do not send custom headers with preflight requests, so the request's version can't be determined
+	anyVersion := func(httpendpoint.Provider) bool {
+		return true
+	}
+
+	provider := h.router.match(method, path, anyVersion)
+
+	if provider == nil && method == http.MethodHead {
+		provider = h.router.match(http.MethodGet, path, anyVersion)
+	}
+
+	if provider == nil {
+		return false
+	}
+
+	policy := h.corsPolicy(provider)
+
+	if !policy.Active() {
+		return false
+	}
+
+	header := wrw.Header()
+	header.Add("Vary", "Origin")
+
+	requestedHeaders := req.Header.Get("Access-Control-Request-Headers")
+
+	if policy.AllowsOrigin(origin) && policy.AllowsMethod(method) && policy.AllowsHeaders(splitHeaderList(requestedHeaders)) {
+
+		header.Set("Access-Control-Allow-Origin", policy.AllowOriginValue(origin))
+
+		if len(policy.AllowedMethods) > 0 {
+			header.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+		} else {
+			header.Set("Access-Control-Allow-Methods", strings.Join(h.router.allowed(path, anyVersion), ", "))
+		}
+
+		if requestedHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", requestedHeaders)
+		}
+
+		if policy.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if policy.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge.Seconds())))
+		}
+
+	} else {
+		h.FrameworkLogger.LogDebugf("Preflight request from %s for %s %s not allowed by CORS policy", origin, method, path)
+	}
+
+	wrw.WriteHeader(http.StatusNoContent)
+
+	return true
+}
+
+// isPreflight returns true if the request is a CORS preflight request. Preflight requests that are not answered by
+// answerPreflight are not allowed, so must not receive CORS headers.
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.Header.Get("Origin") != "" && req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// addCORSHeaders adds the headers required by the supplied CORS policy to the response to a cross-origin request
+func (h *HTTPServer) addCORSHeaders(policy *httpendpoint.CORSPolicy, req *http.Request, wrw *httpendpoint.HTTPResponseWriter) {
+
+	origin := req.Header.Get("Origin")
+
+	if origin == "" || !policy.Active() {
+		return
+	}
+
+	header := wrw.Header()
+	header.Add("Vary", "Origin")
+
+	if !policy.AllowsOrigin(origin) {
+		return
+	}
+
+	header.Set("Access-Control-Allow-Origin", policy.AllowOriginValue(origin))
+
+	if policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if len(policy.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+	}
+}
+
+// splitHeaderList splits a comma separated list of header names
+func splitHeaderList(list string) []string {
+
+	var names []string
+
+	for _, n := range strings.Split(list, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+
+	return names
+}
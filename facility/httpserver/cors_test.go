@@ -0,0 +1,172 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+const corsConfig = `{
+  "HTTPServer": {
+    "CORS": {
+      "AllowedOrigins": ["https://www.example.com", "https://*.example.org", "^https://app[0-9]+\\.example\\.net$"],
+      "AllowedHeaders": ["Content-Type", "X-Version"],
+      "ExposedHeaders": ["X-Request-ID"],
+      "AllowCredentials": true,
+      "MaxAge": "10m"
+    }
+  }
+}`
+
+type corsProvider struct {
+	bodyProvider
+	policy *httpendpoint.CORSPolicy
+}
+
+func (p *corsProvider) CORSPolicy() *httpendpoint.CORSPolicy {
+	return p.policy
+}
+
+func corsServer(t *testing.T) *HTTPServer {
+
+	var data map[string]interface{}
+
+	if err := json.Unmarshal([]byte(corsConfig), &data); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.AbnormalStatusWriter = new(statusAsw)
+
+	ca := &config.Accessor{JSONData: data, FrameworkLogger: s.FrameworkLogger}
+
+	if err := ca.Populate("HTTPServer", s); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	s.SetProvidersManually(map[string]httpendpoint.Provider{
+		"get":    &bodyProvider{routedProvider{name: "get", methods: []string{"GET"}, template: "/artist/{id}"}},
+		"delete": &bodyProvider{routedProvider{name: "delete", methods: []string{"DELETE"}, template: "/artist/{id}"}},
+		"public": &corsProvider{
+			bodyProvider: bodyProvider{routedProvider{name: "public", methods: []string{"GET"}, template: "/public"}},
+			policy:       &httpendpoint.CORSPolicy{AllowedOrigins: []string{"*"}},
+		},
+		"private": &corsProvider{
+			bodyProvider: bodyProvider{routedProvider{name: "private", methods: []string{"GET"}, template: "/private"}},
+			policy:       &httpendpoint.CORSPolicy{},
+		},
+	})
+
+	if err := s.StartComponent(); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	s.setState(ioc.RunningState)
+
+	return s
+}
+
+func crossOrigin(s *HTTPServer, method, path, origin string, headers map[string]string) *httptest.ResponseRecorder {
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Origin", origin)
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handleAll(rec, req)
+
+	return rec
+}
+
+func TestCORSPreflight(t *testing.T) {
+
+	s := corsServer(t)
+
+	rec := crossOrigin(s, "OPTIONS", "/artist/1", "https://www.example.com", map[string]string{
+		"Access-Control-Request-Method":  "DELETE",
+		"Access-Control-Request-Headers": "content-type",
+	})
+
+	h := rec.Header()
+
+	test.ExpectInt(t, rec.Code, http.StatusNoContent)
+	test.ExpectString(t, h.Get("Access-Control-Allow-Origin"), "https://www.example.com")
+	test.ExpectString(t, h.Get("Access-Control-Allow-Methods"), "DELETE, GET, HEAD, OPTIONS")
+	test.ExpectString(t, h.Get("Access-Control-Allow-Headers"), "content-type")
+	test.ExpectString(t, h.Get("Access-Control-Allow-Credentials"), "true")
+	test.ExpectString(t, h.Get("Access-Control-Max-Age"), "600")
+	test.ExpectString(t, h.Get("Vary"), "Origin")
+
+	for _, origin := range []string{"https://api.example.org", "https://app12.example.net"} {
+		rec = crossOrigin(s, "OPTIONS", "/artist/1", origin, map[string]string{"Access-Control-Request-Method": "GET"})
+		test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+
+	// Disallowed origin, header and method
+	rec = crossOrigin(s, "OPTIONS", "/artist/1", "https://evil.example.com", map[string]string{"Access-Control-Request-Method": "GET"})
+	test.ExpectInt(t, rec.Code, http.StatusNoContent)
+	test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), "")
+
+	rec = crossOrigin(s, "OPTIONS", "/artist/1", "https://www.example.com", map[string]string{
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "Authorization",
+	})
+	test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), "")
+
+	// Method not supported by any handler for the path
+	rec = crossOrigin(s, "OPTIONS", "/artist/1", "https://www.example.com", map[string]string{"Access-Control-Request-Method": "PUT"})
+	test.ExpectInt(t, rec.Code, http.StatusNoContent)
+	test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), "")
+	test.ExpectString(t, rec.Header().Get("Allow"), "DELETE, GET, HEAD, OPTIONS")
+}
+
+func TestCORSActualRequests(t *testing.T) {
+
+	s := corsServer(t)
+
+	rec := crossOrigin(s, "GET", "/artist/1", "https://www.example.com", nil)
+	test.ExpectString(t, rec.Body.String(), "get")
+	test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), "https://www.example.com")
+	test.ExpectString(t, rec.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID")
+
+	rec = crossOrigin(s, "GET", "/artist/1", "https://www.example.com.evil.com", nil)
+	test.ExpectString(t, rec.Body.String(), "get")
+	test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), "")
+
+	// Handler specific policies
+	rec = crossOrigin(s, "GET", "/public", "https://anywhere.com", nil)
+	test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), "*")
+
+	rec = crossOrigin(s, "GET", "/private", "https://www.example.com", nil)
+	test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), "")
+	test.ExpectString(t, rec.Header().Get("Vary"), "")
+
+	rec = crossOrigin(s, "OPTIONS", "/private", "https://www.example.com", map[string]string{"Access-Control-Request-Method": "GET"})
+	test.ExpectString(t, rec.Header().Get("Access-Control-Allow-Origin"), "")
+	test.ExpectString(t, rec.Header().Get("Allow"), "GET, HEAD, OPTIONS")
+}
+
+func TestInvalidCORSPolicy(t *testing.T) {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.CORS.AllowedOrigins = []string{"^https://(["}
+	s.SetProvidersManually(map[string]httpendpoint.Provider{})
+
+	test.ExpectNotNil(t, s.StartComponent())
+}
@@ -0,0 +1,237 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/graniticio/granitic/v2/ioc"
+)
+
+// DrainConfig controls how an HTTPServer stops serving requests when the application is shutting down. Shutting down
+// proceeds in four phases:
+//
+//  1. Requests to ReadinessPath receive a 503 response so load balancers stop routing to this instance.
+//  2. The server waits for DeregistrationDelay while continuing to serve all other requests.
+//  3. The server stops accepting new connections, closes idle keep-alive connections and waits up to GracePeriod for
+//     active requests to complete.
+//  4. Any connections still open (including hijacked connections such as WebSockets) are closed.
+type DrainConfig struct {
+	// A path (for example the readiness path of the HealthCheck facility) that receives 503 responses once the
+	// application starts shutting down. If empty, no path is treated specially.
+	ReadinessPath string
+
+	// How long to continue serving requests after the application starts shutting down, to give load balancers time
+	// to stop routing requests to this instance.
+	DeregistrationDelay time.Duration
+
+	// The maximum time to wait for active requests to complete after the server stops accepting connections. Zero
+	// means no limit.
+	GracePeriod time.Duration
+}
+
+// drain shuts the server down in the phases described by DrainConfig, closing done when the last phase is complete
+func (h *HTTPServer) drain(done chan struct{}) {
+
+	defer close(done)
+
+	log := h.FrameworkLogger
+	d := h.Drain
+
+	if d.ReadinessPath != "" {
+		log.LogInfof("Drain phase 1 of 4: requests to %s will receive 503 responses", d.ReadinessPath)
+	} else {
+		log.LogInfof("Drain phase 1 of 4: no readiness path configured (HTTPServer.Drain.ReadinessPath)")
+	}
+
+	if d.DeregistrationDelay > 0 {
+		log.LogInfof("Drain phase 2 of 4: serving requests for %v while load balancers deregister this instance", d.DeregistrationDelay)
+		time.Sleep(d.DeregistrationDelay)
+	} else {
+		log.LogInfof("Drain phase 2 of 4: no deregistration delay configured (HTTPServer.Drain.DeregistrationDelay)")
+	}
+
+	grace := "no limit"
+
+	if d.GracePeriod > 0 {
+		grace = d.GracePeriod.String()
+	}
+
+	log.LogInfof("Drain phase 3 of 4: no longer accepting connections, waiting for %d active request(s) to complete (grace period: %s)",
+		atomic.LoadInt64(&h.ActiveRequests), grace)
+
+	h.server.SetKeepAlivesEnabled(false)
+
+	ctx := context.Background()
+
+	if d.GracePeriod > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, d.GracePeriod)
+		defer cancel()
+	}
+
+	if err := h.server.Shutdown(ctx); err != nil {
+		log.LogWarnf("Grace period of %v expired with %d request(s) still active", d.GracePeriod, atomic.LoadInt64(&h.ActiveRequests))
+	}
+
+	log.LogInfof("Drain phase 4 of 4: closing %d remaining connection(s)", h.connections.count())
+
+	h.forceClose()
+
+	h.stopping()
+
+	log.LogInfof("Drain complete")
+}
+
+// stopping sets the server's state to Stopping unless Stop has already been called (because the container stopped
+// waiting for draining to complete)
+func (h *HTTPServer) stopping() {
+
+	for {
+		current := atomic.LoadInt32(&h.state)
+
+		if ioc.ComponentState(current) == ioc.StoppedState || atomic.CompareAndSwapInt32(&h.state, current, int32(ioc.StoppingState)) {
+			return
+		}
+	}
+}
+
+// checkDrainBudget returns an error if draining could take longer than the container waits for components to become
+// ready to stop (System.StopRetries multiplied by System.StopIntervalMS), in which case connections would be closed before
+// draining is complete.
+func checkDrainBudget(d DrainConfig, stopRetries int, stopInterval time.Duration) error {
+
+	budget := time.Duration(stopRetries) * stopInterval
+
+	if d.GracePeriod <= 0 {
+		return fmt.Errorf("HTTPServer.Drain.GracePeriod is unlimited but the container only waits %v for components to stop "+
+			"(System.StopRetries x System.StopIntervalMS)", budget)
+	}
+
+	if needed := d.DeregistrationDelay + d.GracePeriod; needed >= budget {
+		return fmt.Errorf("HTTPServer.Drain.DeregistrationDelay plus HTTPServer.Drain.GracePeriod (%v) is not less than the %v "+
+			"the container waits for components to stop (System.StopRetries x System.StopIntervalMS). Increase System.StopRetries "+
+			"or System.StopIntervalMS", needed, budget)
+	}
+
+	return nil
+}
+
+// forceClose closes the server and any connections it is no longer responsible for (hijacked connections)
+func (h *HTTPServer) forceClose() {
+
+	if h.server != nil {
+		h.server.Close()
+	}
+
+	h.connections.closeAll()
+}
+
+// drained returns true if the server has completed all phases of draining (or was never started)
+func (h *HTTPServer) drained() bool {
+
+	if h.drainDone == nil {
+		return true
+	}
+
+	select {
+	case <-h.drainDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// connectionTracker records the connections accepted by a listener that have not yet been closed. Go's http.Server
+// neither waits for nor closes hijacked connections, so the tracker allows them to be closed when draining is complete.
+type connectionTracker struct {
+	mutex sync.Mutex
+	open  map[*trackedConn]bool
+}
+
+func (ct *connectionTracker) add(c *trackedConn) {
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+
+	if ct.open == nil {
+		ct.open = make(map[*trackedConn]bool)
+	}
+
+	ct.open[c] = true
+}
+
+func (ct *connectionTracker) remove(c *trackedConn) {
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+
+	delete(ct.open, c)
+}
+
+func (ct *connectionTracker) count() int {
+	ct.mutex.Lock()
+	defer ct.mutex.Unlock()
+
+	return len(ct.open)
+}
+
+func (ct *connectionTracker) closeAll() {
+
+	ct.mutex.Lock()
+
+	conns := make([]*trackedConn, 0, len(ct.open))
+
+	for c := range ct.open {
+		conns = append(conns, c)
+	}
+
+	ct.mutex.Unlock()
+
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+// trackingListener wraps a net.Listener so that every connection it accepts is recorded by a connectionTracker
+type trackingListener struct {
+	net.Listener
+	tracker *connectionTracker
+}
+
+func (tl *trackingListener) Accept() (net.Conn, error) {
+
+	c, err := tl.Listener.Accept()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &trackedConn{Conn: c, tracker: tl.tracker}
+	tl.tracker.add(tc)
+
+	return tc, nil
+}
+
+type trackedConn struct {
+	net.Conn
+	tracker *connectionTracker
+	once    sync.Once
+}
+
+func (tc *trackedConn) Close() error {
+	tc.once.Do(func() { tc.tracker.remove(tc) })
+
+	return tc.Conn.Close()
+}
+
+// isReadinessRequest returns true if the server is draining and the request is for the configured readiness path
+func (h *HTTPServer) isReadinessRequest(req *http.Request) bool {
+	return atomic.LoadInt32(&h.draining) == 1 && h.Drain.ReadinessPath != "" && req.URL.Path == h.Drain.ReadinessPath
+}
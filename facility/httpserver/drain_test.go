@@ -0,0 +1,217 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+	"github.com/graniticio/granitic/v2/ws"
+)
+
+type statusAsw struct{}
+
+func (a *statusAsw) WriteAbnormalStatus(ctx context.Context, state *ws.ProcessState) error {
+	state.HTTPResponseWriter.WriteHeader(state.Status)
+	return nil
+}
+
+type drainProvider struct {
+	hijacked chan net.Conn
+}
+
+func (p *drainProvider) SupportedHTTPMethods() []string {
+	return []string{http.MethodGet}
+}
+
+func (p *drainProvider) RegexPattern() string {
+	return "^/(work|hijack)$"
+}
+
+func (p *drainProvider) ServeHTTP(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+
+	if req.URL.Path == "/hijack" {
+		c, _, _ := w.Hijack()
+		p.hijacked <- c
+		return ctx
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	return ctx
+}
+
+func (p *drainProvider) VersionAware() bool {
+	return false
+}
+
+func (p *drainProvider) SupportsVersion(version httpendpoint.RequiredVersion) bool {
+	return true
+}
+
+func (p *drainProvider) AutoWireable() bool {
+	return false
+}
+
+func freePort(t *testing.T) int {
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	test.ExpectNil(t, err)
+
+	defer ln.Close()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestDrainPhases(t *testing.T) {
+
+	p := &drainProvider{hijacked: make(chan net.Conn, 1)}
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.Address = "127.0.0.1"
+	s.Port = freePort(t)
+	s.TooBusyStatus = http.StatusServiceUnavailable
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(map[string]httpendpoint.Provider{"p": p})
+	s.Drain = DrainConfig{ReadinessPath: "/ready", DeregistrationDelay: 500 * time.Millisecond, GracePeriod: 200 * time.Millisecond}
+
+	test.ExpectNil(t, s.StartComponent())
+	test.ExpectNil(t, s.AllowAccess())
+
+	base := fmt.Sprintf("http://127.0.0.1:%d", s.Port)
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	get := func(path string) int {
+		r, err := client.Get(base + path)
+
+		if err != nil {
+			return 0
+		}
+
+		r.Body.Close()
+
+		return r.StatusCode
+	}
+
+	test.ExpectInt(t, get("/ready"), http.StatusNotFound)
+	test.ExpectInt(t, get("/work"), http.StatusOK)
+
+	c, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", s.Port))
+	test.ExpectNil(t, err)
+	defer c.Close()
+
+	fmt.Fprintf(c, "GET /hijack HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	hijacked := <-p.hijacked
+
+	s.PrepareToStop()
+
+	// Still serving during the deregistration delay, but failing readiness
+	test.ExpectInt(t, get("/ready"), http.StatusServiceUnavailable)
+	test.ExpectInt(t, get("/work"), http.StatusOK)
+
+	ready, _ := s.ReadyToStop()
+	test.ExpectBool(t, ready, false)
+
+	deadline := time.Now().Add(2 * time.Second)
+
+	for !ready && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		ready, _ = s.ReadyToStop()
+	}
+
+	test.ExpectBool(t, ready, true)
+
+	// No longer accepting connections
+	test.ExpectInt(t, get("/work"), 0)
+
+	// Hijacked connections are closed in the final phase
+	if _, err := hijacked.Write([]byte("x")); err == nil {
+		t.Errorf("Expected hijacked connection to be closed")
+	}
+
+	test.ExpectInt(t, s.connections.count(), 0)
+	test.ExpectNil(t, s.Stop())
+}
+
+func TestStopBeforeDrained(t *testing.T) {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.Address = "127.0.0.1"
+	s.Port = freePort(t)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(map[string]httpendpoint.Provider{})
+	s.Drain = DrainConfig{DeregistrationDelay: 200 * time.Millisecond, GracePeriod: time.Minute}
+
+	test.ExpectNil(t, s.StartComponent())
+	test.ExpectNil(t, s.AllowAccess())
+
+	s.PrepareToStop()
+	test.ExpectNil(t, s.Stop())
+
+	if _, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/", s.Port)); err == nil {
+		t.Errorf("Expected server to be closed")
+	}
+
+	// Draining finishing after Stop must not move the server out of the stopped state
+	<-s.drainDone
+
+	if s.currentState() != ioc.StoppedState {
+		t.Errorf("Expected server to remain stopped, state is %v", s.currentState())
+	}
+}
+
+func TestCheckDrainBudget(t *testing.T) {
+
+	test.ExpectNil(t, checkDrainBudget(DrainConfig{DeregistrationDelay: 5 * time.Second, GracePeriod: 20 * time.Second}, 15, 2*time.Second))
+	test.ExpectNil(t, checkDrainBudget(DrainConfig{GracePeriod: 25 * time.Second}, 15, 2*time.Second))
+
+	for _, d := range []DrainConfig{{GracePeriod: 30 * time.Second}, {DeregistrationDelay: 10 * time.Second, GracePeriod: 25 * time.Second}, {}} {
+
+		err := checkDrainBudget(d, 15, 2*time.Second)
+
+		if err == nil || !strings.Contains(err.Error(), "System.StopRetries") {
+			t.Errorf("Expected %v to exceed the stop budget, got %v", d, err)
+		}
+	}
+}
+
+func TestDrainWithoutGracePeriod(t *testing.T) {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.Address = "127.0.0.1"
+	s.Port = freePort(t)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(map[string]httpendpoint.Provider{})
+
+	test.ExpectNil(t, s.StartComponent())
+	test.ExpectNil(t, s.AllowAccess())
+
+	atomic.AddInt64(&s.ActiveRequests, 1)
+
+	s.PrepareToStop()
+
+	<-s.drainDone
+
+	ready, _ := s.ReadyToStop()
+	test.ExpectBool(t, ready, false)
+
+	atomic.AddInt64(&s.ActiveRequests, -1)
+
+	ready, _ = s.ReadyToStop()
+	test.ExpectBool(t, ready, true)
+}
@@ -0,0 +1,17 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+//go:build !go1.24
+// +build !go1.24
+
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+)
+
+// enableH2C is not supported by the standard library before Go 1.24
+func enableH2C(sv *http.Server) error {
+	return errors.New("HTTPServer.H2C requires your application to be built with Go 1.24 or later")
+}
@@ -0,0 +1,55 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+//go:build go1.24
+// +build go1.24
+
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestH2C(t *testing.T) {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.Address = "127.0.0.1"
+	s.Port = freePort(t)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(map[string]httpendpoint.Provider{"p": new(drainProvider)})
+	s.H2C = true
+
+	test.ExpectNil(t, s.StartComponent())
+	test.ExpectNil(t, s.AllowAccess())
+	defer s.Stop()
+
+	get := func(p *http.Protocols) *http.Response {
+
+		r, err := (&http.Client{Transport: &http.Transport{Protocols: p}}).Get(fmt.Sprintf("http://127.0.0.1:%d/work", s.Port))
+		test.ExpectNil(t, err)
+		r.Body.Close()
+
+		return r
+	}
+
+	h2 := new(http.Protocols)
+	h2.SetUnencryptedHTTP2(true)
+
+	r := get(h2)
+	test.ExpectInt(t, r.StatusCode, http.StatusOK)
+	test.ExpectInt(t, r.ProtoMajor, 2)
+
+	h1 := new(http.Protocols)
+	h1.SetHTTP1(true)
+
+	r = get(h1)
+	test.ExpectInt(t, r.StatusCode, http.StatusOK)
+	test.ExpectInt(t, r.ProtoMajor, 1)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/graniticio/granitic/v2/config"
 	"github.com/graniticio/granitic/v2/httpendpoint"
 	"github.com/graniticio/granitic/v2/instrument"
 	"github.com/graniticio/granitic/v2/ioc"
@@ -27,21 +28,17 @@ import (
 	"github.com/graniticio/granitic/v2/ws"
 	"net"
 	"net/http"
-	"regexp"
+	"sort"
+	"strings"
 	"sync/atomic"
 	"time"
 )
 
-type registeredProvider struct {
-	Provider httpendpoint.Provider
-	Pattern  *regexp.Regexp
-}
-
 // HTTPServer is the server that accepts incoming HTTP requests and maps them to handlers to process them.
 type HTTPServer struct {
-	registeredProvidersByMethod map[string][]*registeredProvider
-	unregisteredProviders       map[string]httpendpoint.Provider
-	componentContainer          *ioc.ComponentContainer
+	router                *router
+	unregisteredProviders map[string]httpendpoint.Provider
+	componentContainer    *ioc.ComponentContainer
 
 	// Logger used by Granitic framework components. Automatically injected.
 	FrameworkLogger logging.Logger
@@ -91,8 +88,55 @@ type HTTPServer struct {
 	// A component able to use data in an HTTP request's headers to populate a context
 	IDContextBuilder IdentifiedRequestContextBuilder
 
-	state  ioc.ComponentState
-	server *http.Server
+	// The maximum time allowed to read an entire request, including the body. Zero means no limit.
+	ReadTimeout time.Duration
+
+	// The maximum time allowed to read a request's headers. Zero means ReadTimeout is used.
+	ReadHeaderTimeout time.Duration
+
+	// The maximum time allowed to write a response, measured from the end of reading the request's headers. Zero means no limit.
+	WriteTimeout time.Duration
+
+	// The maximum time a keep-alive connection may be idle before it is closed. Zero means ReadTimeout is used.
+	IdleTimeout time.Duration
+
+	// The maximum size in bytes of a request's headers. Zero means Go's default (1MB).
+	MaxHeaderBytes int
+
+	// Whether or not the server accepts HTTP/2 requests over unencrypted connections (h2c), as well as HTTP/1.x requests.
+	// Cannot be used with TLS, where HTTP/2 is negotiated automatically.
+	H2C bool
+
+	// Controls how the server stops serving requests when the application is shutting down.
+	Drain DrainConfig
+
+	// Controls whether the server accepts HTTPS connections and verifies client certificates.
+	TLS TLSConfig
+
+	// The Cross-Origin Resource Sharing (CORS) policy applied to requests, unless the Provider handling a request has its
+	// own policy (see httpendpoint.CORSAware). The policy is inactive if no origins are allowed.
+	CORS httpendpoint.CORSPolicy
+
+	// Controls whether responses are compressed and compressed request bodies are decompressed.
+	Compression CompressionConfig
+
+	state       int32
+	server      *http.Server
+	connections connectionTracker
+	draining    int32
+	drainDone   chan struct{}
+	tls         *tlsReloader
+	middleware  []namedMiddleware
+}
+
+// currentState returns the server's state. The state is changed by the drain goroutine and read while handling
+// requests, so is accessed atomically.
+func (h *HTTPServer) currentState() ioc.ComponentState {
+	return ioc.ComponentState(atomic.LoadInt32(&h.state))
+}
+
+func (h *HTTPServer) setState(s ioc.ComponentState) {
+	atomic.StoreInt32(&h.state, int32(s))
 }
 
 // Container allows Granitic to inject a reference to the IOC container
@@ -100,33 +144,64 @@ func (h *HTTPServer) Container(container *ioc.ComponentContainer) {
 	h.componentContainer = container
 }
 
-func (h *HTTPServer) registerProvider(endPointProvider httpendpoint.Provider) {
+// ReloadConfig applies a changed value of HTTPServer.MaxConcurrent without restarting the server. The change is rejected
+// if HTTPServer.Port or HTTPServer.Address have changed, as they can only be applied by restarting the application.
+func (h *HTTPServer) ReloadConfig(ca *config.Accessor) error {
 
-	for _, method := range endPointProvider.SupportedHTTPMethods() {
-		var compiledRegex *regexp.Regexp
-		var err error
+	port, err := ca.IntVal("HTTPServer.Port")
 
-		pattern := endPointProvider.RegexPattern()
+	if err != nil {
+		return err
+	}
 
-		if compiledRegex, err = regexp.Compile(pattern); err != nil {
-			h.FrameworkLogger.LogErrorf("Unable to compile regular expression from pattern %s: %s", pattern, err.Error())
-		}
+	address, err := ca.StringVal("HTTPServer.Address")
 
-		h.FrameworkLogger.LogTracef("Registering %s %s", pattern, method)
+	if err != nil {
+		return err
+	}
 
-		rp := registeredProvider{endPointProvider, compiledRegex}
+	if port != h.Port || address != h.Address {
+		return errors.New("HTTPServer.Port and HTTPServer.Address cannot be changed without restarting the application")
+	}
 
-		providersForMethod := h.registeredProvidersByMethod[method]
+	max, err := ca.IntVal("HTTPServer.MaxConcurrent")
 
-		if providersForMethod == nil {
-			providersForMethod = make([]*registeredProvider, 1)
-			providersForMethod[0] = &rp
-			h.registeredProvidersByMethod[method] = providersForMethod
-		} else {
-			h.registeredProvidersByMethod[method] = append(providersForMethod, &rp)
+	if err != nil {
+		return err
+	}
+
+	if max < 0 {
+		return fmt.Errorf("HTTPServer.MaxConcurrent cannot be negative (was %d)", max)
+	}
+
+	if old := atomic.SwapInt64(&h.MaxConcurrent, int64(max)); old != int64(max) {
+		h.FrameworkLogger.LogInfof("MaxConcurrent changed from %d to %d", old, max)
+	}
+
+	return nil
+}
+
+func (h *HTTPServer) registerProvider(endPointProvider httpendpoint.Provider) error {
+
+	h.FrameworkLogger.LogTracef("Registering %v for %s", endPointProvider.SupportedHTTPMethods(), describeRoute(endPointProvider))
+
+	if ca, found := endPointProvider.(httpendpoint.CORSAware); found && ca.CORSPolicy() != nil {
+		if err := ca.CORSPolicy().Compile(); err != nil {
+			return fmt.Errorf("invalid CORS policy: %s", err.Error())
 		}
 	}
 
+	return h.router.add(endPointProvider)
+}
+
+// describeRoute returns the path template or regular expression used to match requests to a Provider
+func describeRoute(p httpendpoint.Provider) string {
+
+	if tp, found := p.(httpendpoint.TemplatedProvider); found && tp.TemplatePattern() != "" {
+		return tp.TemplatePattern()
+	}
+
+	return p.RegexPattern()
 }
 
 // StartComponent Finds and registers any available components that implement httpendpoint.Provider (normally instances of
@@ -134,12 +209,14 @@ func (h *HTTPServer) registerProvider(endPointProvider httpendpoint.Provider) {
 // requests until the IoC container calls AllowAccess.
 func (h *HTTPServer) StartComponent() error {
 
-	if h.state != ioc.StoppedState {
+	if h.currentState() != ioc.StoppedState {
 		return nil
 	}
 
-	h.state = ioc.StartingState
-	h.registeredProvidersByMethod = make(map[string][]*registeredProvider)
+	h.setState(ioc.StartingState)
+	h.router = newRouter()
+
+	var problems []string
 
 	if h.AutoFindHandlers {
 		for _, component := range h.componentContainer.AllComponents() {
@@ -148,14 +225,19 @@ func (h *HTTPServer) StartComponent() error {
 
 			if provider, found := component.Instance.(httpendpoint.Provider); found && provider.AutoWireable() {
 				h.FrameworkLogger.LogDebugf("Found Provider %s", name)
-				h.registerProvider(provider)
+
+				if err := h.registerProvider(provider); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %s", name, err.Error()))
+				}
 			}
 		}
 	} else if h.unregisteredProviders != nil {
 
-		for _, provider := range h.unregisteredProviders {
+		for name, provider := range h.unregisteredProviders {
 
-			h.registerProvider(provider)
+			if err := h.registerProvider(provider); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", name, err.Error()))
+			}
 
 		}
 
@@ -163,18 +245,39 @@ func (h *HTTPServer) StartComponent() error {
 		return errors.New("auto finding of handlers is disabled, but handlers have not been set manually")
 	}
 
+	// Providers set manually are registered in no particular order
+	sort.Strings(problems)
+
+	problems = append(problems, h.router.conflicts()...)
+
+	if err := h.CORS.Compile(); err != nil {
+		problems = append(problems, fmt.Sprintf("HTTPServer.CORS: %s", err.Error()))
+	}
+
+	if err := h.Compression.validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("unable to register handlers with the HTTP server:\n%s", strings.Join(problems, "\n"))
+	}
+
 	if h.AbnormalStatusWriter == nil {
 
 		return errors.New("no AbnormalStatusWriter set - make sure you have enabled a web services facility")
 	}
 
+	for _, m := range h.middleware {
+		h.FrameworkLogger.LogDebugf("Middleware %s (priority %d)", m.name, m.priority)
+	}
+
 	if h.InstrumentationManager == nil {
 		//No RequestInstrumentationManager component injected, use a 'noop' implementation
 		h.FrameworkLogger.LogDebugf("No RequestInstrumentationManager set. Using noop implementation")
 		h.InstrumentationManager = new(noopRequestInstrumentationManager)
 	}
 
-	h.state = ioc.AwaitingAccessState
+	h.setState(ioc.AwaitingAccessState)
 
 	return nil
 }
@@ -182,11 +285,11 @@ func (h *HTTPServer) StartComponent() error {
 // Suspend causes all subsequent new HTTP requests to receive a 'too busy' response until Resume is called.
 func (h *HTTPServer) Suspend() error {
 
-	if h.state != ioc.RunningState {
+	if h.currentState() != ioc.RunningState {
 		return nil
 	}
 
-	h.state = ioc.SuspendedState
+	h.setState(ioc.SuspendedState)
 
 	return nil
 }
@@ -194,11 +297,11 @@ func (h *HTTPServer) Suspend() error {
 // Resume allows subsequent requests to be processed normally (reverses the effect of calling Suspend).
 func (h *HTTPServer) Resume() error {
 
-	if h.state != ioc.SuspendedState {
+	if h.currentState() != ioc.SuspendedState {
 		return nil
 	}
 
-	h.state = ioc.RunningState
+	h.setState(ioc.RunningState)
 
 	return nil
 }
@@ -206,7 +309,7 @@ func (h *HTTPServer) Resume() error {
 // AllowAccess starts the server listening on the configured address and port. Returns an error if the port is already in use.
 func (h *HTTPServer) AllowAccess() error {
 
-	if h.state != ioc.AwaitingAccessState {
+	if h.currentState() != ioc.AwaitingAccessState {
 		return nil
 	}
 
@@ -215,25 +318,62 @@ func (h *HTTPServer) AllowAccess() error {
 
 	sv := new(http.Server)
 	sv.Handler = sm
+	sv.ReadTimeout = h.ReadTimeout
+	sv.ReadHeaderTimeout = h.ReadHeaderTimeout
+	sv.WriteTimeout = h.WriteTimeout
+	sv.IdleTimeout = h.IdleTimeout
+	sv.MaxHeaderBytes = h.MaxHeaderBytes
+
+	if h.H2C {
+
+		if h.TLS.Enabled {
+			return errors.New("HTTPServer.H2C cannot be used when HTTPServer.TLS is enabled (HTTP/2 is negotiated automatically over TLS)")
+		}
+
+		if err := enableH2C(sv); err != nil {
+			return err
+		}
+	}
 
 	listenAddress := fmt.Sprintf("%s:%d", h.Address, h.Port)
 
-	//Check if the address is already in use
-	if ln, err := net.Listen("tcp", listenAddress); err == nil {
-		ln.Close()
-	} else {
+	if h.TLS.Enabled {
+
+		r, err := newTLSReloader(&h.TLS, h.FrameworkLogger)
+
+		if err != nil {
+			return err
+		}
+
+		sv.TLSConfig = r.serverConfig()
+		h.tls = r
+	}
+
+	ln, err := net.Listen("tcp", listenAddress)
+
+	if err != nil {
 		return err
 	}
 
 	sv.Addr = listenAddress
 
-	go sv.ListenAndServe()
+	tl := &trackingListener{Listener: ln, tracker: &h.connections}
 
-	h.server = sv
+	if h.tls != nil {
+		go sv.ServeTLS(tl, "", "")
 
-	h.FrameworkLogger.LogInfof("Listening on %d", h.Port)
+		h.tls.watch()
 
-	h.state = ioc.RunningState
+		h.FrameworkLogger.LogInfof("Listening on %d (HTTPS)", h.Port)
+	} else {
+		go sv.Serve(tl)
+
+		h.FrameworkLogger.LogInfof("Listening on %d", h.Port)
+	}
+
+	h.server = sv
+
+	h.setState(ioc.RunningState)
 
 	return nil
 }
@@ -263,6 +403,8 @@ func (h *HTTPServer) handleAll(res http.ResponseWriter, req *http.Request) {
 	ctx, cancelFunc := context.WithCancel(req.Context())
 	defer cancelFunc()
 
+	ctx = ioc.WithRequestScope(ctx)
+
 	if h.AllowEarlyInstrumentation {
 		ctx, instrumentor, endInstrumentation = h.InstrumentationManager.Begin(ctx, res, req)
 		defer endInstrumentation()
@@ -270,16 +412,22 @@ func (h *HTTPServer) handleAll(res http.ResponseWriter, req *http.Request) {
 
 	wrw := httpendpoint.NewHTTPResponseWriter(res)
 
-	if h.state != ioc.RunningState {
+	if h.currentState() != ioc.RunningState {
 		// The HTTP server is suspended - reject the request
 		h.writeAbnormal(ctx, h.TooBusyStatus, wrw)
 		return
 	}
 
+	if h.isReadinessRequest(req) {
+		// The server is draining - tell load balancers to stop sending requests to this instance
+		h.writeAbnormal(ctx, http.StatusServiceUnavailable, wrw)
+		return
+	}
+
 	rCount := atomic.AddInt64(&h.ActiveRequests, 1)
 	defer atomic.AddInt64(&h.ActiveRequests, -1)
 
-	if h.MaxConcurrent > 0 && rCount > h.MaxConcurrent {
+	if max := atomic.LoadInt64(&h.MaxConcurrent); max > 0 && rCount > max {
 		// Too many requests already being processed
 		h.writeAbnormal(ctx, h.TooBusyStatus, wrw)
 		return
@@ -317,40 +465,66 @@ func (h *HTTPServer) handleAll(res http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	matched := false
+	path := req.URL.Path
 
-	providersByMethod := h.registeredProvidersByMethod[req.Method]
+	h.FrameworkLogger.LogTracef("Finding provider to handle %s %s", path, req.Method)
 
-	path := req.URL.Path
+	if !h.answerPreflight(path, req, wrw) {
+		ctx = h.serve(ctx, instrumentor, path, wrw, req)
+	}
 
-	h.FrameworkLogger.LogTracef("Finding provider to handle %s %s from %d providers", path, req.Method, len(providersByMethod))
+	if h.AccessLogging {
+		finished := time.Now()
+		h.AccessLogWriter.LogRequest(ctx, req, wrw, &received, &finished)
+	}
 
-	for _, handlerPattern := range providersByMethod {
+}
+
+// serve finds the Provider that should handle the request and has it write a response, or writes a 404 or 405 response
+// if there is no suitable Provider.
+func (h *HTTPServer) serve(ctx context.Context, instrumentor instrument.Instrumentor, path string, wrw *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
 
-		pattern := handlerPattern.Pattern
+	accept := func(p httpendpoint.Provider) bool {
+		return h.versionMatch(instrumentor, req, p)
+	}
 
-		h.FrameworkLogger.LogTracef("Testing %s", pattern.String())
+	provider := h.router.match(req.Method, path, accept)
 
-		if pattern.MatchString(path) && h.versionMatch(instrumentor, req, handlerPattern.Provider) {
-			h.FrameworkLogger.LogTracef("Matches %s", pattern.String())
-			matched = true
-			ctx = handlerPattern.Provider.ServeHTTP(ctx, wrw, req)
+	if provider == nil && req.Method == http.MethodHead {
+		// Serve HEAD requests with the handler for GET requests, discarding the body
+		if provider = h.router.match(http.MethodGet, path, accept); provider != nil {
+			wrw.SuppressBody()
 		}
 	}
 
-	if !matched {
-		state := ws.NewAbnormalState(http.StatusNotFound, wrw)
+	if !isPreflight(req) {
+		h.addCORSHeaders(h.corsPolicy(provider), req, wrw)
+	}
 
-		if err := h.AbnormalStatusWriter.WriteAbnormalStatus(ctx, state); err != nil {
-			h.FrameworkLogger.LogErrorfCtx(ctx, err.Error())
-		}
+	if provider != nil {
+		h.FrameworkLogger.LogTracef("Matches %s", describeRoute(provider))
+		ctx = h.invoke(ctx, provider, wrw, req)
+	} else if allowed := h.router.allowed(path, accept); len(allowed) > 0 {
+		h.writeNotAllowed(ctx, req, wrw, allowed)
+	} else {
+		h.writeAbnormal(ctx, http.StatusNotFound, wrw)
 	}
 
-	if h.AccessLogging {
-		finished := time.Now()
-		h.AccessLogWriter.LogRequest(ctx, req, wrw, &received, &finished)
+	return ctx
+}
+
+// writeNotAllowed responds to a request for a path that is handled, but not with the request's HTTP method. OPTIONS
+// requests receive an empty 204 response, other methods a 405 response. Both list the supported methods in an Allow header.
+func (h *HTTPServer) writeNotAllowed(ctx context.Context, req *http.Request, wrw *httpendpoint.HTTPResponseWriter, allowed []string) {
+
+	wrw.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	if req.Method == http.MethodOptions {
+		wrw.WriteHeader(http.StatusNoContent)
+		return
 	}
 
+	h.writeAbnormal(ctx, http.StatusMethodNotAllowed, wrw)
 }
 
 func (h *HTTPServer) versionMatch(ri instrument.Instrumentor, r *http.Request, p httpendpoint.Provider) bool {
@@ -367,19 +541,28 @@ func (h *HTTPServer) versionMatch(ri instrument.Instrumentor, r *http.Request, p
 
 }
 
-// PrepareToStop sets state to Stopping. Any subsequent requests will receive a 'too busy response'
+// PrepareToStop starts draining the server in the background (see DrainConfig). If the server is not listening for
+// requests, it sets state to Stopping and any subsequent requests will receive a 'too busy' response.
 func (h *HTTPServer) PrepareToStop() {
-	h.state = ioc.StoppingState
 
-	if h.server != nil {
-		h.server.Shutdown(context.Background())
+	if h.server == nil || !atomic.CompareAndSwapInt32(&h.draining, 0, 1) {
+		h.setState(ioc.StoppingState)
+		return
 	}
 
+	h.drainDone = make(chan struct{})
+
+	go h.drain(h.drainDone)
 }
 
-// ReadyToStop returns false is the server is currently handling any requests.
+// ReadyToStop returns false if the server is still draining or is currently handling any requests.
 func (h *HTTPServer) ReadyToStop() (bool, error) {
-	a := h.ActiveRequests
+
+	if !h.drained() {
+		return false, fmt.Errorf("HTTP server listening on %d is still draining", h.Port)
+	}
+
+	a := atomic.LoadInt64(&h.ActiveRequests)
 	ready := a <= 0
 
 	if ready {
@@ -390,14 +573,19 @@ func (h *HTTPServer) ReadyToStop() (bool, error) {
 
 }
 
-// Stop sets state to Stopped. Any subsequent requests will receive a 'too busy response'. Note that the HTTP
-// server is still listening on its configured port and address.
+// Stop sets state to Stopped. If the server has not finished draining, all of its connections are closed immediately.
 func (h *HTTPServer) Stop() error {
 
-	h.state = ioc.StoppedState
+	if !h.drained() {
+		h.FrameworkLogger.LogWarnf("Stopping before draining is complete. Closing %d connection(s)", h.connections.count())
+	}
+
+	h.setState(ioc.StoppedState)
+
+	h.forceClose()
 
-	if h.server != nil {
-		h.server.Close()
+	if h.tls != nil {
+		h.tls.stopWatching()
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package httpserver
 
 import (
 	"context"
+	"github.com/graniticio/granitic/v2/config"
 	"github.com/graniticio/granitic/v2/httpendpoint"
 	"github.com/graniticio/granitic/v2/logging"
 	"github.com/graniticio/granitic/v2/ws"
@@ -37,3 +38,35 @@ type mockAsw struct {
 func (a *mockAsw) WriteAbnormalStatus(ctx context.Context, state *ws.ProcessState) error {
 	return nil
 }
+
+func TestReloadMaxConcurrent(t *testing.T) {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.ConsoleErrorLogger)
+	s.Port = 8080
+
+	ca := func(port int, max int) *config.Accessor {
+		hs := map[string]interface{}{"Port": float64(port), "Address": "", "MaxConcurrent": float64(max)}
+		return &config.Accessor{JSONData: map[string]interface{}{"HTTPServer": hs}, FrameworkLogger: s.FrameworkLogger}
+	}
+
+	if err := s.ReloadConfig(ca(8080, 10)); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if s.MaxConcurrent != 10 {
+		t.Errorf("Expected MaxConcurrent to be updated")
+	}
+
+	if err := s.ReloadConfig(ca(9000, 20)); err == nil {
+		t.Errorf("Expected a port change to be rejected")
+	}
+
+	if err := s.ReloadConfig(ca(8080, -1)); err == nil {
+		t.Errorf("Expected a negative MaxConcurrent to be rejected")
+	}
+
+	if s.MaxConcurrent != 10 {
+		t.Errorf("Expected MaxConcurrent to be unchanged after a rejected reload")
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type bodyProvider struct {
+	routedProvider
+}
+
+func (p *bodyProvider) ServeHTTP(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+	w.Header().Set("X-Handled-By", p.name)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(p.name))
+
+	return ctx
+}
+
+func runningServer(t *testing.T, providers map[string]httpendpoint.Provider) *HTTPServer {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(providers)
+
+	if err := s.StartComponent(); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	s.setState(ioc.RunningState)
+
+	return s
+}
+
+func serve(s *HTTPServer, method, path string) *httptest.ResponseRecorder {
+
+	rec := httptest.NewRecorder()
+	s.handleAll(rec, httptest.NewRequest(method, path, nil))
+
+	return rec
+}
+
+func TestMethodHandling(t *testing.T) {
+
+	s := runningServer(t, map[string]httpendpoint.Provider{
+		"get":    &bodyProvider{routedProvider{name: "get", methods: []string{"GET"}, template: "/artist/{id}"}},
+		"delete": &bodyProvider{routedProvider{name: "delete", methods: []string{"DELETE"}, template: "/artist/{id}"}},
+		"create": &bodyProvider{routedProvider{name: "create", methods: []string{"POST"}, regex: "^/artist$"}},
+		"ping":   &bodyProvider{routedProvider{name: "ping", methods: []string{"HEAD", "OPTIONS"}, template: "/ping"}},
+	})
+
+	rec := serve(s, "GET", "/artist/1")
+	test.ExpectInt(t, rec.Code, http.StatusOK)
+	test.ExpectString(t, rec.Body.String(), "get")
+
+	// HEAD is served by the GET handler, without a body
+	rec = serve(s, "HEAD", "/artist/1")
+	test.ExpectInt(t, rec.Code, http.StatusOK)
+	test.ExpectString(t, rec.Header().Get("X-Handled-By"), "get")
+	test.ExpectInt(t, rec.Body.Len(), 0)
+
+	rec = serve(s, "PUT", "/artist/1")
+	test.ExpectInt(t, rec.Code, http.StatusMethodNotAllowed)
+	test.ExpectString(t, rec.Header().Get("Allow"), "DELETE, GET, HEAD, OPTIONS")
+
+	rec = serve(s, "GET", "/artist")
+	test.ExpectInt(t, rec.Code, http.StatusMethodNotAllowed)
+	test.ExpectString(t, rec.Header().Get("Allow"), "OPTIONS, POST")
+
+	rec = serve(s, "OPTIONS", "/artist/1")
+	test.ExpectInt(t, rec.Code, http.StatusNoContent)
+	test.ExpectString(t, rec.Header().Get("Allow"), "DELETE, GET, HEAD, OPTIONS")
+
+	// Handlers explicitly registered for HEAD and OPTIONS are used in preference to the automatic behaviour
+	rec = serve(s, "HEAD", "/ping")
+	test.ExpectString(t, rec.Header().Get("X-Handled-By"), "ping")
+
+	rec = serve(s, "OPTIONS", "/ping")
+	test.ExpectInt(t, rec.Code, http.StatusOK)
+	test.ExpectString(t, rec.Body.String(), "ping")
+
+	rec = serve(s, "GET", "/album/1")
+	test.ExpectInt(t, rec.Code, http.StatusNotFound)
+	test.ExpectString(t, rec.Header().Get("Allow"), "")
+
+	rec = serve(s, "OPTIONS", "/album/1")
+	test.ExpectInt(t, rec.Code, http.StatusNotFound)
+}
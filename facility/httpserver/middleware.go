@@ -0,0 +1,105 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+)
+
+// Middleware is implemented by components that need to act on every request that has been matched to a
+// httpendpoint.Provider. Components implementing this interface are automatically found in the IoC container and
+// added to the HTTPServer.
+//
+// Each Middleware wraps the next Middleware in the chain (or the Provider itself). Implementations can modify the context
+// before calling next, write a response without calling next (short-circuiting the rest of the chain) or examine and
+// modify the HTTPResponseWriter after next returns.
+type Middleware interface {
+	// Handle processes the request, calling next to pass the request on to the rest of the chain. Returns the context
+	// returned by next (or a context derived from it).
+	Handle(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request, next NextFunc) context.Context
+}
+
+// NextFunc passes a request on to the next Middleware in the chain, or to the Provider that will handle the request.
+type NextFunc func(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context
+
+// MiddlewarePriority is implemented by Middleware that need to be called before or after other Middleware. Higher values
+// are called first (i.e. they are further from the Provider) and negative values are allowed. Middleware that does not
+// implement this interface has a priority of zero.
+type MiddlewarePriority interface {
+	Priority() int64
+}
+
+type namedMiddleware struct {
+	name       string
+	middleware Middleware
+	priority   int64
+}
+
+// AddMiddleware adds a Middleware to the chain of Middleware that wraps every Provider. The name is used in log messages
+// and to order Middleware with the same priority.
+func (h *HTTPServer) AddMiddleware(name string, m Middleware) {
+
+	nm := namedMiddleware{name: name, middleware: m}
+
+	if mp, found := m.(MiddlewarePriority); found {
+		nm.priority = mp.Priority()
+	}
+
+	h.middleware = append(h.middleware, nm)
+
+	sort.SliceStable(h.middleware, func(i, j int) bool {
+
+		a, b := h.middleware[i], h.middleware[j]
+
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+
+		return a.name < b.name
+	})
+}
+
+// invoke passes the request through the chain of Middleware to the supplied Provider
+func (h *HTTPServer) invoke(ctx context.Context, p httpendpoint.Provider, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+	return h.next(0, p)(ctx, w, req)
+}
+
+// next returns a function that calls the Middleware at position i in the chain or, at the end of the chain, the Provider
+func (h *HTTPServer) next(i int, p httpendpoint.Provider) NextFunc {
+
+	if i >= len(h.middleware) {
+		return p.ServeHTTP
+	}
+
+	return func(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+		return h.middleware[i].middleware.Handle(ctx, w, req, h.next(i+1, p))
+	}
+}
+
+// Adds components that implement Middleware to the HTTP server
+type middlewareDecorator struct {
+	Server *HTTPServer
+	Log    logging.Logger
+}
+
+// OfInterest returns true if the supplied component is an instance of Middleware
+func (md *middlewareDecorator) OfInterest(subject *ioc.Component) bool {
+	_, found := subject.Instance.(Middleware)
+
+	return found
+}
+
+// DecorateComponent adds the Middleware to the HTTP server
+func (md *middlewareDecorator) DecorateComponent(subject *ioc.Component, cc *ioc.ComponentContainer) {
+
+	md.Log.LogDebugf("HTTP server using %s as middleware", subject.Name)
+
+	md.Server.AddMiddleware(subject.Name, subject.Instance.(Middleware))
+}
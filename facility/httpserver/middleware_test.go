@@ -0,0 +1,141 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type middlewareKey string
+
+type recordingMiddleware struct {
+	name     string
+	priority int64
+	block    bool
+}
+
+func (m *recordingMiddleware) Handle(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request, next NextFunc) context.Context {
+
+	w.Header().Add("X-Before", m.name)
+
+	if m.block {
+		w.WriteHeader(http.StatusForbidden)
+		return ctx
+	}
+
+	ctx = next(context.WithValue(ctx, middlewareKey(m.name), true), w, req)
+
+	w.Header().Add("X-After", m.name)
+
+	return ctx
+}
+
+func (m *recordingMiddleware) Priority() int64 {
+	return m.priority
+}
+
+// unprioritisedMiddleware does not implement MiddlewarePriority, so has a priority of zero
+type unprioritisedMiddleware struct {
+	recorder recordingMiddleware
+}
+
+func (m *unprioritisedMiddleware) Handle(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request, next NextFunc) context.Context {
+	return m.recorder.Handle(ctx, w, req, next)
+}
+
+type contextProvider struct {
+	bodyProvider
+	seen []string
+}
+
+func (p *contextProvider) ServeHTTP(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+
+	for _, k := range []string{"auth", "tenant", "zero"} {
+		if ctx.Value(middlewareKey(k)) != nil {
+			p.seen = append(p.seen, k)
+		}
+	}
+
+	return p.bodyProvider.ServeHTTP(ctx, w, req)
+}
+
+func TestMiddlewareOrdering(t *testing.T) {
+
+	p := &contextProvider{bodyProvider: bodyProvider{routedProvider{name: "get", methods: []string{"GET"}, template: "/artist/{id}"}}}
+
+	s := runningServer(t, map[string]httpendpoint.Provider{"get": p})
+
+	s.AddMiddleware("tenant", &recordingMiddleware{name: "tenant", priority: 10})
+	s.AddMiddleware("zero", &unprioritisedMiddleware{recordingMiddleware{name: "zero", priority: 100}})
+	s.AddMiddleware("auth", &recordingMiddleware{name: "auth", priority: 20})
+
+	rec := serve(s, "GET", "/artist/1")
+
+	test.ExpectInt(t, rec.Code, http.StatusOK)
+	test.ExpectString(t, rec.Body.String(), "get")
+	test.ExpectString(t, strings.Join(rec.Header()["X-Before"], ","), "auth,tenant,zero")
+	test.ExpectString(t, strings.Join(rec.Header()["X-After"], ","), "zero,tenant,auth")
+	test.ExpectString(t, strings.Join(p.seen, ","), "auth,tenant,zero")
+
+	// Middleware is only applied to requests that match a Provider
+	rec = serve(s, "GET", "/album/1")
+	test.ExpectInt(t, rec.Code, http.StatusNotFound)
+	test.ExpectInt(t, len(rec.Header()["X-Before"]), 0)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+
+	p := &contextProvider{bodyProvider: bodyProvider{routedProvider{name: "get", methods: []string{"GET"}, template: "/artist/{id}"}}}
+
+	s := runningServer(t, map[string]httpendpoint.Provider{"get": p})
+
+	s.AddMiddleware("auth", &recordingMiddleware{name: "auth", priority: 20, block: true})
+	s.AddMiddleware("tenant", &recordingMiddleware{name: "tenant", priority: 10})
+
+	rec := serve(s, "GET", "/artist/1")
+
+	test.ExpectInt(t, rec.Code, http.StatusForbidden)
+	test.ExpectString(t, strings.Join(rec.Header()["X-Before"], ","), "auth")
+	test.ExpectInt(t, rec.Body.Len(), 0)
+	test.ExpectInt(t, len(p.seen), 0)
+}
+
+func TestMiddlewareFoundInContainer(t *testing.T) {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	ca, err := configAccessor(lm)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	cc := ioc.NewComponentContainer(lm, ca, new(instance.System))
+
+	if err = new(FacilityBuilder).BuildAndRegister(lm, ca, cc); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	cc.WrapAndAddProto("tenantMiddleware", &recordingMiddleware{name: "tenant", priority: 10})
+	cc.WrapAndAddProto("authMiddleware", &recordingMiddleware{name: "auth", priority: 20})
+
+	if err = cc.Populate(); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	s := cc.ComponentByName(HTTPServerComponentName).Instance.(*HTTPServer)
+
+	test.ExpectInt(t, len(s.middleware), 2)
+	test.ExpectString(t, s.middleware[0].name, "authMiddleware")
+	test.ExpectString(t, s.middleware[1].name, "tenantMiddleware")
+}
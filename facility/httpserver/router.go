@@ -0,0 +1,276 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+)
+
+// The order in which parameter segments are tried when more than one could match a path segment. More specific types are tried first.
+var paramPrecedence = []httpendpoint.ParamType{httpendpoint.IntParam, httpendpoint.UintParam, httpendpoint.FloatParam, httpendpoint.StringParam}
+
+// router finds the Provider that should handle a request. Providers with a path template are stored in a tree of path
+// segments, so the time taken to find a match depends on the length of the path rather than the number of providers.
+// Literal segments take precedence over parameters, and parameters with more specific types take precedence over
+// string parameters. Providers that are matched by regular expression are tested in the order they were registered
+// if no templated Provider matches.
+type router struct {
+	root  *routeNode
+	regex map[string][]*registeredProvider
+
+	// The providers registered for each distinct route, used to detect conflicts
+	routes map[string][]*route
+
+	// Every HTTP method that at least one Provider supports
+	methods map[string]bool
+}
+
+type registeredProvider struct {
+	Provider httpendpoint.Provider
+	Pattern  *regexp.Regexp
+}
+
+type route struct {
+	template *httpendpoint.PathTemplate
+	method   string
+	provider httpendpoint.Provider
+}
+
+type routeNode struct {
+	literals  map[string]*routeNode
+	params    map[httpendpoint.ParamType]*routeNode
+	providers map[string][]httpendpoint.Provider
+}
+
+func newRouteNode() *routeNode {
+	return &routeNode{
+		literals:  make(map[string]*routeNode),
+		params:    make(map[httpendpoint.ParamType]*routeNode),
+		providers: make(map[string][]httpendpoint.Provider),
+	}
+}
+
+func newRouter() *router {
+	return &router{
+		root:    newRouteNode(),
+		regex:   make(map[string][]*registeredProvider),
+		routes:  make(map[string][]*route),
+		methods: make(map[string]bool),
+	}
+}
+
+// add registers the supplied Provider for each of its supported HTTP methods
+func (r *router) add(p httpendpoint.Provider) error {
+
+	for _, method := range p.SupportedHTTPMethods() {
+		r.methods[method] = true
+	}
+
+	if tp, found := p.(httpendpoint.TemplatedProvider); found && tp.TemplatePattern() != "" {
+		return r.addTemplated(p, tp.TemplatePattern())
+	}
+
+	pattern := p.RegexPattern()
+
+	if pattern == "" {
+		// An empty pattern would match every path
+		return errors.New("no path template or regular expression pattern set")
+	}
+
+	compiled, err := regexp.Compile(pattern)
+
+	if err != nil {
+		return fmt.Errorf("unable to compile regular expression from pattern %s: %s", pattern, err.Error())
+	}
+
+	for _, method := range p.SupportedHTTPMethods() {
+		r.regex[method] = append(r.regex[method], &registeredProvider{p, compiled})
+	}
+
+	return nil
+}
+
+func (r *router) addTemplated(p httpendpoint.Provider, template string) error {
+
+	pt, err := httpendpoint.ParsePathTemplate(template)
+
+	if err != nil {
+		return err
+	}
+
+	n := r.root
+
+	for _, s := range pt.Segments {
+
+		if s.IsParam() {
+
+			if n.params[s.Type] == nil {
+				n.params[s.Type] = newRouteNode()
+			}
+
+			n = n.params[s.Type]
+
+		} else {
+
+			if n.literals[s.Literal] == nil {
+				n.literals[s.Literal] = newRouteNode()
+			}
+
+			n = n.literals[s.Literal]
+		}
+	}
+
+	for _, method := range p.SupportedHTTPMethods() {
+
+		n.providers[method] = append(n.providers[method], p)
+
+		key := method + " " + routeKey(pt)
+		r.routes[key] = append(r.routes[key], &route{template: pt, method: method, provider: p})
+	}
+
+	return nil
+}
+
+// routeKey returns a string that is the same for any two templates that would match exactly the same paths
+func routeKey(pt *httpendpoint.PathTemplate) string {
+
+	var b strings.Builder
+
+	for _, s := range pt.Segments {
+
+		b.WriteString("/")
+
+		if s.IsParam() {
+			b.WriteString("{" + string(s.Type) + "}")
+		} else {
+			b.WriteString(s.Literal)
+		}
+	}
+
+	return b.String()
+}
+
+// conflicts returns a description of each pair of templated providers that would match exactly the same requests.
+// Providers that are version aware may share a route, as requests are matched to them by version.
+func (r *router) conflicts() []string {
+
+	var found []string
+
+	for _, routes := range r.routes {
+
+		for i, a := range routes {
+			for _, b := range routes[i+1:] {
+
+				if a.provider.VersionAware() && b.provider.VersionAware() {
+					continue
+				}
+
+				first, second := a.template.String(), b.template.String()
+
+				// Providers are registered in no particular order, so order each pair to keep the message the same
+				if second < first {
+					first, second = second, first
+				}
+
+				found = append(found, fmt.Sprintf("%s %s conflicts with %s %s", a.method, first, b.method, second))
+			}
+		}
+	}
+
+	sort.Strings(found)
+
+	return found
+}
+
+// match finds a Provider registered for the supplied method whose template or regular expression matches the path and
+// that is accepted by the supplied function.
+func (r *router) match(method, path string, accept func(httpendpoint.Provider) bool) httpendpoint.Provider {
+
+	if p := r.root.match(method, httpendpoint.SplitPath(path), accept); p != nil {
+		return p
+	}
+
+	for _, rp := range r.regex[method] {
+
+		if rp.Pattern.MatchString(path) && accept(rp.Provider) {
+			return rp.Provider
+		}
+	}
+
+	return nil
+}
+
+// allowed returns the HTTP methods, in alphabetical order, for which a Provider accepted by the supplied function
+// matches the path. HEAD is included if GET is supported and OPTIONS is included if any method is supported, as
+// the HTTPServer answers those methods automatically.
+func (r *router) allowed(path string, accept func(httpendpoint.Provider) bool) []string {
+
+	found := make(map[string]bool)
+
+	for method := range r.methods {
+		if r.match(method, path, accept) != nil {
+			found[method] = true
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+
+	if found[http.MethodGet] {
+		found[http.MethodHead] = true
+	}
+
+	found[http.MethodOptions] = true
+
+	methods := make([]string, 0, len(found))
+
+	for method := range found {
+		methods = append(methods, method)
+	}
+
+	sort.Strings(methods)
+
+	return methods
+}
+
+func (n *routeNode) match(method string, segments []string, accept func(httpendpoint.Provider) bool) httpendpoint.Provider {
+
+	if len(segments) == 0 {
+
+		for _, p := range n.providers[method] {
+			if accept(p) {
+				return p
+			}
+		}
+
+		return nil
+	}
+
+	s, remaining := segments[0], segments[1:]
+
+	if c := n.literals[s]; c != nil {
+		if p := c.match(method, remaining, accept); p != nil {
+			return p
+		}
+	}
+
+	for _, t := range paramPrecedence {
+
+		if c := n.params[t]; c != nil && t.Accepts(s) {
+			if p := c.match(method, remaining, accept); p != nil {
+				return p
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type routedProvider struct {
+	name     string
+	methods  []string
+	template string
+	regex    string
+	version  string
+}
+
+func (p *routedProvider) SupportedHTTPMethods() []string {
+	return p.methods
+}
+
+func (p *routedProvider) RegexPattern() string {
+	return p.regex
+}
+
+func (p *routedProvider) TemplatePattern() string {
+	return p.template
+}
+
+func (p *routedProvider) ServeHTTP(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+	return ctx
+}
+
+func (p *routedProvider) VersionAware() bool {
+	return p.version != ""
+}
+
+func (p *routedProvider) SupportsVersion(version httpendpoint.RequiredVersion) bool {
+	return version["v"] == p.version
+}
+
+func (p *routedProvider) AutoWireable() bool {
+	return true
+}
+
+func templated(name, method, template string) *routedProvider {
+	return &routedProvider{name: name, methods: []string{method}, template: template}
+}
+
+func acceptAll(p httpendpoint.Provider) bool {
+	return true
+}
+
+func matchedName(r *router, method, path string) string {
+
+	p := r.match(method, path, acceptAll)
+
+	if p == nil {
+		return ""
+	}
+
+	return p.(*routedProvider).name
+}
+
+func TestRouterPrecedence(t *testing.T) {
+
+	r := newRouter()
+
+	for _, p := range []*routedProvider{
+		templated("root", "GET", "/"),
+		templated("byName", "GET", "/artist/{name}"),
+		templated("byID", "GET", "/artist/{id:int}"),
+		templated("latest", "GET", "/artist/latest"),
+		templated("album", "GET", "/artist/{id:int}/album/{albumID}"),
+		templated("albumByArtistName", "GET", "/artist/{name}/album/special"),
+		templated("create", "POST", "/artist"),
+		{name: "legacy", methods: []string{"GET"}, regex: "^/legacy/([\\d]+)$"},
+	} {
+		test.ExpectNil(t, r.add(p))
+	}
+
+	test.ExpectInt(t, len(r.conflicts()), 0)
+
+	test.ExpectString(t, matchedName(r, "GET", "/"), "root")
+	test.ExpectString(t, matchedName(r, "GET", "/artist/12"), "byID")
+	test.ExpectString(t, matchedName(r, "GET", "/artist/12/"), "byID")
+	test.ExpectString(t, matchedName(r, "GET", "/artist/beatles"), "byName")
+	test.ExpectString(t, matchedName(r, "GET", "/artist/latest"), "latest")
+	test.ExpectString(t, matchedName(r, "GET", "/artist/12/album/x"), "album")
+
+	// The typed parameter is preferred, but the string parameter is used if the rest of the path doesn't match
+	test.ExpectString(t, matchedName(r, "GET", "/artist/12/album/special"), "album")
+	test.ExpectString(t, matchedName(r, "GET", "/artist/abba/album/special"), "albumByArtistName")
+
+	test.ExpectString(t, matchedName(r, "POST", "/artist"), "create")
+	test.ExpectString(t, matchedName(r, "GET", "/artist"), "")
+	test.ExpectString(t, matchedName(r, "GET", "/legacy/12"), "legacy")
+	test.ExpectString(t, matchedName(r, "GET", "/legacy/x"), "")
+}
+
+func TestRouterVersions(t *testing.T) {
+
+	r := newRouter()
+
+	v1 := templated("v1", "GET", "/artist/{id}")
+	v1.version = "1"
+
+	v2 := templated("v2", "GET", "/artist/{id}")
+	v2.version = "2"
+
+	test.ExpectNil(t, r.add(v1))
+	test.ExpectNil(t, r.add(v2))
+
+	test.ExpectInt(t, len(r.conflicts()), 0)
+
+	p := r.match("GET", "/artist/1", func(p httpendpoint.Provider) bool {
+		return p.SupportsVersion(httpendpoint.RequiredVersion{"v": "2"})
+	})
+
+	test.ExpectString(t, p.(*routedProvider).name, "v2")
+}
+
+func TestRouterConflicts(t *testing.T) {
+
+	r := newRouter()
+
+	test.ExpectNil(t, r.add(templated("a", "GET", "/artist/{id}")))
+	test.ExpectNil(t, r.add(templated("b", "GET", "/artist/{name}/")))
+	test.ExpectNil(t, r.add(templated("c", "POST", "/artist/{name}")))
+	test.ExpectNil(t, r.add(templated("d", "GET", "/artist/{id:int}")))
+
+	c := r.conflicts()
+
+	test.ExpectInt(t, len(c), 1)
+	test.ExpectString(t, c[0], "GET /artist/{id} conflicts with GET /artist/{name}/")
+
+	test.ExpectNotNil(t, r.add(templated("e", "GET", "/artist/{id:long}")))
+	test.ExpectNotNil(t, r.add(&routedProvider{methods: []string{"GET"}, regex: "^/artist/(["}))
+	test.ExpectNotNil(t, r.add(&routedProvider{methods: []string{"GET"}}))
+}
+
+func TestServerReportsRouteProblems(t *testing.T) {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(map[string]httpendpoint.Provider{
+		"a": templated("a", "GET", "/artist/{id}"),
+		"b": templated("b", "GET", "/artist/{name}"),
+		"c": templated("c", "GET", "/album/{id:date}"),
+	})
+
+	err := s.StartComponent()
+
+	test.ExpectNotNil(t, err)
+
+	expected := "unable to register handlers with the HTTP server:\n" +
+		"c: path template /album/{id:date} is invalid: parameter id has unsupported type \"date\" (should be one of string, int, uint, float)\n" +
+		"GET /artist/{id} conflicts with GET /artist/{name}"
+
+	test.ExpectString(t, err.Error(), expected)
+}
@@ -0,0 +1,131 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// ServerSchemaFields returns definitions of the configuration keys that can be used to configure an HTTPServer component.
+// The returned map can be safely modified.
+func ServerSchemaFields() map[string]*config.SchemaField {
+	return map[string]*config.SchemaField{
+		"Port":                           {Type: config.IntValue, Required: true, Min: config.Limit(0), Max: config.Limit(65535)},
+		"Address":                        {Type: config.StringValue},
+		"AllowEarlyInstrumentation":      {Type: config.BoolValue},
+		"DisableInstrumentationAutoWire": {Type: config.BoolValue},
+		"MaxConcurrent":                  {Type: config.IntValue, Min: config.Limit(0)},
+		"TooBusyStatus":                  {Type: config.IntValue, Min: config.Limit(100), Max: config.Limit(599)},
+		"AutoFindHandlers":               {Type: config.BoolValue},
+		"AccessLogging":                  {Type: config.BoolValue},
+		"ReadTimeout":                    {Type: config.StringValue, Check: checkDuration(false)},
+		"ReadHeaderTimeout":              {Type: config.StringValue, Check: checkDuration(false)},
+		"WriteTimeout":                   {Type: config.StringValue, Check: checkDuration(false)},
+		"IdleTimeout":                    {Type: config.StringValue, Check: checkDuration(false)},
+		"MaxHeaderBytes":                 {Type: config.IntValue, Min: config.Limit(0)},
+		"H2C":                            {Type: config.BoolValue},
+	}
+}
+
+// ConfigSchemas implements facility.SchemaProvider
+func (hsfb *FacilityBuilder) ConfigSchemas() []*config.Schema {
+
+	fields := ServerSchemaFields()
+
+	fields["Drain"] = &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"ReadinessPath":       {Type: config.StringValue, Check: checkReadinessPath},
+		"DeregistrationDelay": {Type: config.StringValue, Check: checkDuration(false)},
+		"GracePeriod":         {Type: config.StringValue, Check: checkDuration(false)},
+	}}
+
+	fields["TLS"] = &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"Enabled":        {Type: config.BoolValue},
+		"CertFile":       {Type: config.StringValue},
+		"KeyFile":        {Type: config.StringValue},
+		"MinVersion":     {Type: config.StringValue, Enum: []interface{}{"1.0", "1.1", "1.2", "1.3"}},
+		"CipherSuites":   {Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue}},
+		"ClientAuth":     {Type: config.StringValue, Enum: []interface{}{ClientAuthNone, ClientAuthOptional, ClientAuthRequired}},
+		"ClientCAFile":   {Type: config.StringValue},
+		"ReloadInterval": {Type: config.StringValue, Check: checkDuration(false)},
+	}}
+
+	fields["CORS"] = &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"AllowedOrigins":   {Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue, Min: config.Limit(1)}},
+		"AllowedMethods":   {Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue, Min: config.Limit(1)}},
+		"AllowedHeaders":   {Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue, Min: config.Limit(1)}},
+		"ExposedHeaders":   {Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue, Min: config.Limit(1)}},
+		"AllowCredentials": {Type: config.BoolValue},
+		"MaxAge":           {Type: config.StringValue, Check: checkDuration(false)},
+	}}
+
+	fields["Compression"] = &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"Enabled":             {Type: config.BoolValue},
+		"Encodings":           {Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue, Min: config.Limit(1)}},
+		"Level":               {Type: config.IntValue},
+		"MinSize":             {Type: config.IntValue, Min: config.Limit(0)},
+		"ContentTypes":        {Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue, Min: config.Limit(1)}},
+		"DecompressRequests":  {Type: config.BoolValue},
+		"MaxDecompressedSize": {Type: config.IntValue, Min: config.Limit(0)},
+	}}
+
+	fields["RequestID"] = &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"Enabled": {Type: config.BoolValue},
+		"Format":  {Type: config.StringValue, Enum: []interface{}{"UUIDV4"}},
+		"UUID": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+			"Encoding": {Type: config.StringValue, Enum: []interface{}{"RFC4122", "Base32", "Base64"}},
+		}},
+	}}
+
+	fields["AccessLog"] = &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"LogPath":        {Type: config.StringValue, Min: config.Limit(1)},
+		"LogLineFormat":  {Type: config.StringValue},
+		"LogLinePreset":  {Type: config.StringValue, Enum: []interface{}{"", presetCommonName, presetCombinedName, presetFrameworkName}},
+		"UtcTimes":       {Type: config.BoolValue},
+		"LineBufferSize": {Type: config.IntValue, Min: config.Limit(0)},
+		"Entry":          {Type: config.StringValue, Enum: []interface{}{textEntryMode, jsonEntryMode}},
+		"JSON": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+			"Prefix": {Type: config.StringValue},
+			"Suffix": {Type: config.StringValue},
+			"Fields": {Type: config.ArrayValue, Elements: &config.SchemaField{
+				Type:     config.ArrayValue,
+				Min:      config.Limit(2),
+				Elements: &config.SchemaField{Type: config.StringValue},
+			}},
+		}},
+	}}
+
+	return []*config.Schema{{Path: "HTTPServer", Fields: fields}}
+}
+
+func checkReadinessPath(v interface{}) error {
+
+	if p := v.(string); p != "" && !strings.HasPrefix(p, "/") {
+		return errors.New("must be empty or start with /")
+	}
+
+	return nil
+}
+
+// checkDuration returns a check that a value is a duration that is not negative (or is greater than zero if positive is true)
+func checkDuration(positive bool) func(v interface{}) error {
+
+	return func(v interface{}) error {
+
+		d, err := time.ParseDuration(v.(string))
+
+		if positive && (err != nil || d <= 0) {
+			return errors.New("must be a positive duration (e.g. 30s or 500ms)")
+		}
+
+		if err != nil || d < 0 {
+			return errors.New("must be a duration that is not negative (e.g. 0s or 5s)")
+		}
+
+		return nil
+	}
+}
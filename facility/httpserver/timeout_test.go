@@ -0,0 +1,64 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestServerTimeouts(t *testing.T) {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.Address = "127.0.0.1"
+	s.Port = freePort(t)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(map[string]httpendpoint.Provider{})
+	s.ReadHeaderTimeout = 100 * time.Millisecond
+	s.MaxHeaderBytes = 2048
+
+	test.ExpectNil(t, s.StartComponent())
+	test.ExpectNil(t, s.AllowAccess())
+	defer s.Stop()
+
+	test.ExpectInt(t, int(s.server.ReadHeaderTimeout), int(100*time.Millisecond))
+	test.ExpectInt(t, s.server.MaxHeaderBytes, 2048)
+
+	// A client that never finishes sending headers is disconnected
+	c, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", s.Port))
+	test.ExpectNil(t, err)
+	defer c.Close()
+
+	fmt.Fprintf(c, "GET / HTTP/1.1\r\nHost: localhost\r\n")
+
+	c.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	start := time.Now()
+	ioutil.ReadAll(c)
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected slow client to be disconnected by ReadHeaderTimeout")
+	}
+}
+
+func TestH2CNotAllowedWithTLS(t *testing.T) {
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(map[string]httpendpoint.Provider{})
+	s.H2C = true
+	s.TLS.Enabled = true
+
+	test.ExpectNil(t, s.StartComponent())
+	test.ExpectNotNil(t, s.AllowAccess())
+}
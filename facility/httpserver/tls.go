@@ -0,0 +1,300 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/graniticio/granitic/v2/logging"
+)
+
+// Supported values for TLSConfig.ClientAuth
+const (
+	// ClientAuthNone means clients are not asked for a certificate.
+	ClientAuthNone = "NONE"
+
+	// ClientAuthOptional means clients may present a certificate, which is verified against TLSConfig.ClientCAFile if present.
+	ClientAuthOptional = "OPTIONAL"
+
+	// ClientAuthRequired means clients must present a certificate that can be verified against TLSConfig.ClientCAFile.
+	ClientAuthRequired = "REQUIRED"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	ClientAuthNone:     tls.NoClientCert,
+	ClientAuthOptional: tls.VerifyClientCertIfGiven,
+	ClientAuthRequired: tls.RequireAndVerifyClientCert,
+}
+
+// TLSConfig controls whether an HTTPServer accepts HTTPS connections and, optionally, verifies certificates presented
+// by clients (mutual TLS).
+type TLSConfig struct {
+	// Whether or not the server should only accept HTTPS connections.
+	Enabled bool
+
+	// Path to a PEM encoded certificate (or certificate chain) for the server.
+	CertFile string
+
+	// Path to the PEM encoded private key for CertFile.
+	KeyFile string
+
+	// The minimum version of TLS clients may use (1.0, 1.1, 1.2 or 1.3).
+	MinVersion string
+
+	// The names of the cipher suites that may be used with TLS 1.2 and earlier (e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256).
+	// If empty, Go's default cipher suites are used. Cipher suites for TLS 1.3 are not configurable.
+	CipherSuites []string
+
+	// Whether clients are asked for a certificate (NONE, OPTIONAL or REQUIRED).
+	ClientAuth string
+
+	// Path to a PEM encoded bundle of CA certificates used to verify client certificates.
+	ClientCAFile string
+
+	// How often the certificate, key and CA files are checked for changes. Zero disables reloading.
+	ReloadInterval time.Duration
+}
+
+// tlsReloader builds a tls.Config from a TLSConfig and rebuilds it when any of the files it was built from change
+type tlsReloader struct {
+	config *TLSConfig
+	log    logging.Logger
+
+	minVersion uint16
+	ciphers    []uint16
+	clientAuth tls.ClientAuthType
+
+	mutex    sync.RWMutex
+	current  *tls.Config
+	modTimes map[string]time.Time
+
+	stop chan struct{}
+}
+
+func newTLSReloader(tc *TLSConfig, log logging.Logger) (*tlsReloader, error) {
+
+	r := &tlsReloader{config: tc, log: log}
+
+	if tc.CertFile == "" || tc.KeyFile == "" {
+		return nil, errors.New("HTTPServer.TLS.CertFile and HTTPServer.TLS.KeyFile must be set when TLS is enabled")
+	}
+
+	var found bool
+
+	if r.minVersion, found = tlsVersions[tc.MinVersion]; !found {
+		return nil, fmt.Errorf("%q is not a supported value for HTTPServer.TLS.MinVersion. Should be one of 1.0, 1.1, 1.2, 1.3", tc.MinVersion)
+	}
+
+	if r.clientAuth, found = clientAuthTypes[tc.ClientAuth]; !found {
+		return nil, fmt.Errorf("%q is not a supported value for HTTPServer.TLS.ClientAuth. Should be one of %s, %s, %s", tc.ClientAuth,
+			ClientAuthNone, ClientAuthOptional, ClientAuthRequired)
+	}
+
+	if r.clientAuth != tls.NoClientCert && tc.ClientCAFile == "" {
+		return nil, fmt.Errorf("HTTPServer.TLS.ClientCAFile must be set when HTTPServer.TLS.ClientAuth is %s", tc.ClientAuth)
+	}
+
+	ciphers, err := cipherSuiteIDs(tc.CipherSuites)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.ciphers = ciphers
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// cipherSuiteIDs converts cipher suite names to the IDs used by the crypto/tls package
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+
+	for _, cs := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		known[cs.Name] = cs.ID
+	}
+
+	ids := make([]uint16, len(names))
+
+	for i, n := range names {
+
+		id, found := known[n]
+
+		if !found {
+			return nil, fmt.Errorf("%s is not a cipher suite supported by HTTPServer.TLS.CipherSuites", n)
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+// files returns the paths of every file the tls.Config is built from
+func (r *tlsReloader) files() []string {
+
+	f := []string{r.config.CertFile, r.config.KeyFile}
+
+	if r.config.ClientCAFile != "" {
+		f = append(f, r.config.ClientCAFile)
+	}
+
+	return f
+}
+
+// load reads the certificate, key and CA files and replaces the current tls.Config
+func (r *tlsReloader) load() error {
+
+	modTimes := make(map[string]time.Time)
+
+	for _, f := range r.files() {
+
+		fi, err := os.Stat(f)
+
+		if err != nil {
+			return fmt.Errorf("unable to read TLS file: %s", err.Error())
+		}
+
+		modTimes[f] = fi.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+
+	if err != nil {
+		return fmt.Errorf("unable to load TLS certificate %s and key %s: %s", r.config.CertFile, r.config.KeyFile, err.Error())
+	}
+
+	c := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   r.minVersion,
+		CipherSuites: r.ciphers,
+		ClientAuth:   r.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if r.config.ClientCAFile != "" {
+
+		pem, err := ioutil.ReadFile(r.config.ClientCAFile)
+
+		if err != nil {
+			return fmt.Errorf("unable to read client CA file: %s", err.Error())
+		}
+
+		c.ClientCAs = x509.NewCertPool()
+
+		if !c.ClientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM encoded certificates found in client CA file %s", r.config.ClientCAFile)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.current = c
+	r.modTimes = modTimes
+
+	return nil
+}
+
+// serverConfig returns a tls.Config for an http.Server that always uses the most recently loaded certificates
+func (r *tlsReloader) serverConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         r.minVersion,
+		NextProtos:         []string{"h2", "http/1.1"},
+		GetConfigForClient: r.configForClient,
+	}
+}
+
+func (r *tlsReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.current, nil
+}
+
+// changed returns true if any of the files have been modified since they were last loaded
+func (r *tlsReloader) changed() bool {
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for f, loaded := range r.modTimes {
+
+		if fi, err := os.Stat(f); err == nil && !fi.ModTime().Equal(loaded) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reloadIfChanged reloads the TLS files if they have changed, keeping the previous files if they can't be loaded
+func (r *tlsReloader) reloadIfChanged() {
+
+	if !r.changed() {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		r.log.LogErrorf("TLS files have changed but could not be reloaded (continuing to use previous files): %s", err.Error())
+		return
+	}
+
+	r.log.LogInfof("Reloaded TLS certificate %s", r.config.CertFile)
+}
+
+// watch checks for changes to the TLS files every ReloadInterval until stopWatching is called
+func (r *tlsReloader) watch() {
+
+	if r.config.ReloadInterval <= 0 {
+		return
+	}
+
+	r.stop = make(chan struct{})
+
+	go func() {
+
+		t := time.NewTicker(r.config.ReloadInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C:
+				r.reloadIfChanged()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (r *tlsReloader) stopWatching() {
+
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
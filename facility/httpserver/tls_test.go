@@ -0,0 +1,229 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+	"github.com/graniticio/granitic/v2/ws"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func issue(t *testing.T, cn string, serial int64, parent *testCert, client bool) *testCert {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.ExpectNil(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"Granitic Test"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	signer, signerKey := tmpl, key
+
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+
+		if client {
+			tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		} else {
+			tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+			tmpl.DNSNames = []string{"localhost"}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	test.ExpectNil(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	test.ExpectNil(t, err)
+
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (tc *testCert) write(t *testing.T, certPath, keyPath string) {
+
+	test.ExpectNil(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tc.der}), 0600))
+
+	if keyPath != "" {
+		kb, err := x509.MarshalECPrivateKey(tc.key)
+		test.ExpectNil(t, err)
+		test.ExpectNil(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb}), 0600))
+	}
+}
+
+func (tc *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{tc.der}, PrivateKey: tc.key}
+}
+
+type subjectProvider struct{}
+
+func (p *subjectProvider) SupportedHTTPMethods() []string {
+	return []string{http.MethodGet}
+}
+
+func (p *subjectProvider) RegexPattern() string {
+	return "^/whoami$"
+}
+
+func (p *subjectProvider) ServeHTTP(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+
+	if c := ws.VerifiedClientCertificate(req); c != nil {
+		w.Write([]byte(c.Subject.CommonName))
+	} else {
+		w.Write([]byte("-"))
+	}
+
+	return ctx
+}
+
+func (p *subjectProvider) VersionAware() bool {
+	return false
+}
+
+func (p *subjectProvider) SupportsVersion(version httpendpoint.RequiredVersion) bool {
+	return true
+}
+
+func (p *subjectProvider) AutoWireable() bool {
+	return false
+}
+
+func TestMutualTLS(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "granitic-tls")
+	test.ExpectNil(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := issue(t, "Test CA", 1, nil, false)
+	server := issue(t, "localhost", 2, ca, false)
+	client := issue(t, "billing-service", 3, ca, true)
+
+	certFile, keyFile, caFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt")
+
+	server.write(t, certFile, keyFile)
+	ca.write(t, caFile, "")
+
+	s := new(HTTPServer)
+	s.FrameworkLogger = new(logging.NullLogger)
+	s.Address = "127.0.0.1"
+	s.Port = freePort(t)
+	s.AbnormalStatusWriter = new(statusAsw)
+	s.SetProvidersManually(map[string]httpendpoint.Provider{"p": new(subjectProvider)})
+	s.TLS = TLSConfig{Enabled: true, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2", ClientAuth: ClientAuthOptional, ClientCAFile: caFile}
+
+	test.ExpectNil(t, s.StartComponent())
+	test.ExpectNil(t, s.AllowAccess())
+	defer s.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	whoami := func(certs ...tls.Certificate) (string, *tls.ConnectionState, error) {
+
+		tr := &http.Transport{DisableKeepAlives: true, TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: certs}}
+
+		r, err := (&http.Client{Transport: tr}).Get(fmt.Sprintf("https://127.0.0.1:%d/whoami", s.Port))
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		defer r.Body.Close()
+
+		b, _ := ioutil.ReadAll(r.Body)
+
+		return string(b), r.TLS, nil
+	}
+
+	body, cs, err := whoami(client.tlsCertificate())
+	test.ExpectNil(t, err)
+	test.ExpectString(t, body, "billing-service")
+	test.ExpectInt(t, int(cs.PeerCertificates[0].SerialNumber.Int64()), 2)
+
+	body, _, err = whoami()
+	test.ExpectNil(t, err)
+	test.ExpectString(t, body, "-")
+
+	// A client certificate that can't be verified is never exposed as verified
+	untrusted := issue(t, "intruder", 4, issue(t, "Other CA", 5, nil, false), true)
+
+	body, _, err = whoami(untrusted.tlsCertificate())
+	test.ExpectNil(t, err)
+	test.ExpectString(t, body, "-")
+
+	// Replace the server certificate and check that it is picked up
+	replacement := issue(t, "localhost", 6, ca, false)
+	replacement.write(t, certFile, keyFile)
+
+	future := time.Now().Add(time.Minute)
+	test.ExpectNil(t, os.Chtimes(certFile, future, future))
+
+	s.tls.reloadIfChanged()
+
+	_, cs, err = whoami(client.tlsCertificate())
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, int(cs.PeerCertificates[0].SerialNumber.Int64()), 6)
+
+	// Invalid files are ignored and the previous certificate kept
+	test.ExpectNil(t, ioutil.WriteFile(keyFile, []byte("not a key"), 0600))
+	test.ExpectNil(t, os.Chtimes(keyFile, future.Add(time.Minute), future.Add(time.Minute)))
+
+	s.tls.reloadIfChanged()
+
+	_, cs, err = whoami(client.tlsCertificate())
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, int(cs.PeerCertificates[0].SerialNumber.Int64()), 6)
+}
+
+func TestInvalidTLSConfig(t *testing.T) {
+
+	log := new(logging.NullLogger)
+
+	_, err := newTLSReloader(&TLSConfig{MinVersion: "1.2", ClientAuth: ClientAuthNone}, log)
+	test.ExpectNotNil(t, err)
+
+	_, err = newTLSReloader(&TLSConfig{CertFile: "a", KeyFile: "b", MinVersion: "1.4", ClientAuth: ClientAuthNone}, log)
+	test.ExpectNotNil(t, err)
+
+	_, err = newTLSReloader(&TLSConfig{CertFile: "a", KeyFile: "b", MinVersion: "1.2", ClientAuth: ClientAuthRequired}, log)
+	test.ExpectNotNil(t, err)
+
+	_, err = cipherSuiteIDs([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_MADE_UP"})
+	test.ExpectNotNil(t, err)
+
+	ids, err := cipherSuiteIDs([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"})
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, int(ids[0]), int(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256))
+}
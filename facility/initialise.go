@@ -5,8 +5,8 @@ package facility
 
 import (
 	"errors"
-	"fmt"
 	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/facility/healthcheck"
 	"github.com/graniticio/granitic/v2/facility/httpserver"
 	"github.com/graniticio/granitic/v2/facility/logger"
 	"github.com/graniticio/granitic/v2/facility/querymanager"
@@ -22,6 +22,7 @@ import (
 
 const frameworkLoggingManagerName = instance.FrameworkPrefix + "FrameworkLoggingManager"
 const frameworkLoggerDecoratorName = instance.FrameworkPrefix + "FrameworkLoggingDecorator"
+const frameworkLogLevelReloaderName = instance.FrameworkPrefix + "FrameworkLogLevelReloader"
 const facilityInitialisorComponentName string = instance.FrameworkPrefix + "FacilityInitialisor"
 const configErrorPrefix = "Unable to configure framework logging: "
 
@@ -58,7 +59,11 @@ type FacilitiesInitialisor struct {
 	FrameworkLoggingManager *logging.ComponentLoggerManager
 
 	//Allows the FacilitiesInitialisor to log problems during facility initialisation.
-	Logger         logging.Logger
+	Logger logging.Logger
+
+	// Schemas describing the application's own configuration, checked along with the schemas of enabled facilities.
+	ConfigSchemas []*config.Schema
+
 	container      *ioc.ComponentContainer
 	facilities     []Builder
 	facilityStatus map[string]interface{}
@@ -70,33 +75,18 @@ func (fi *FacilitiesInitialisor) addFacility(f Builder) {
 
 func (fi *FacilitiesInitialisor) buildEnabledFacilities() error {
 
-	for _, fb := range fi.facilities {
-
-		name := fb.FacilityName()
-
-		if fi.facilityStatus[name] == nil {
-
-			fi.Logger.LogWarnf("No setting for facility %s in the Facilities configuration object - will not enable this facility", name)
-			continue
+	ordered, err := fi.orderFacilities()
 
-		}
-
-		if fi.facilityStatus[name].(bool) {
-
-			for _, dep := range fb.DependsOnFacilities() {
-
-				if fi.facilityStatus[dep] == nil || fi.facilityStatus[dep].(bool) == false {
-					message := fmt.Sprintf("Facility %s depends on facility %s, but %s is not enabled in configuration.", name, dep, dep)
-					return errors.New(message)
-				}
+	if err != nil {
+		return err
+	}
 
-			}
+	for _, fb := range ordered {
 
-			err := fb.BuildAndRegister(fi.FrameworkLoggingManager, fi.ConfigAccessor, fi.container)
+		fi.Logger.LogDebugf("Building facility %s", fb.FacilityName())
 
-			if err != nil {
-				return err
-			}
+		if err := fb.BuildAndRegister(fi.FrameworkLoggingManager, fi.ConfigAccessor, fi.container); err != nil {
+			return err
 		}
 	}
 
@@ -104,8 +94,8 @@ func (fi *FacilitiesInitialisor) buildEnabledFacilities() error {
 
 }
 
-// Initialise creates a Builder for each of the built-in Granitic facilities and then
-// builds those facilities that have been enabled by the user.
+// Initialise creates a Builder for each of the built-in Granitic facilities (and any registered with Register) and then
+// builds those facilities that have been enabled by the user, in an order that respects the dependencies declared by each Builder.
 func (fi *FacilitiesInitialisor) Initialise(ca *config.Accessor) error {
 	fi.ConfigAccessor = ca
 
@@ -135,6 +125,11 @@ func (fi *FacilitiesInitialisor) Initialise(ca *config.Accessor) error {
 	fi.addFacility(new(rdbms.FacilityBuilder))
 	fi.addFacility(new(runtimectl.FacilityBuilder))
 	fi.addFacility(new(taskscheduler.FacilityBuilder))
+	fi.addFacility(new(healthcheck.FacilityBuilder))
+
+	if err := fi.addExternalFacilities(); err != nil {
+		return err
+	}
 
 	if fc["ApplicationLogging"].(bool) || fc["HTTPServer"].(bool) {
 		//Facilties are required that might need a logging.ContextFilter
@@ -147,11 +142,59 @@ func (fi *FacilitiesInitialisor) Initialise(ca *config.Accessor) error {
 
 	}
 
+	if err = fi.validateConfig(); err != nil {
+		return err
+	}
+
 	err = fi.buildEnabledFacilities()
 
 	return err
 }
 
+// validateConfig checks merged configuration against the schemas declared by enabled facilities and by the application,
+// logging a warning for each unrecognised key and returning an error listing every problem found.
+func (fi *FacilitiesInitialisor) validateConfig() error {
+
+	warnings, err := fi.ConfigAccessor.Validate(fi.configSchemas()...)
+
+	for _, w := range warnings {
+		fi.Logger.LogWarnf(w)
+	}
+
+	return err
+}
+
+func (fi *FacilitiesInitialisor) configSchemas() []*config.Schema {
+
+	schemas := []*config.Schema{fi.facilitiesSchema()}
+	schemas = append(schemas, logger.FrameworkLoggingSchemas()...)
+
+	for _, fb := range fi.facilities {
+
+		enabled, _ := fi.facilityStatus[fb.FacilityName()].(bool)
+
+		if sp, found := fb.(SchemaProvider); found && enabled {
+			schemas = append(schemas, sp.ConfigSchemas()...)
+		}
+	}
+
+	return append(schemas, fi.ConfigSchemas...)
+}
+
+// facilitiesSchema describes the Facilities object, which must contain a bool for each known facility
+func (fi *FacilitiesInitialisor) facilitiesSchema() *config.Schema {
+
+	fields := map[string]*config.SchemaField{
+		"FrameworkLogging": {Type: config.BoolValue},
+	}
+
+	for _, fb := range fi.facilities {
+		fields[fb.FacilityName()] = &config.SchemaField{Type: config.BoolValue}
+	}
+
+	return &config.Schema{Path: "Facilities", Fields: fields}
+}
+
 func (fi *FacilitiesInitialisor) handleDisabledApplicationLogging(ca *config.Accessor) error {
 
 	logger.AddRuntimeCommandsForFrameworkLogging(ca, fi.FrameworkLoggingManager, fi.container)
@@ -213,6 +256,8 @@ func (fi *FacilitiesInitialisor) updateFrameworkLoggingConfiguration() error {
 	flm.SetInitialLogLevels(il)
 	flm.SetGlobalThreshold(defaultLogLevel)
 
+	fi.container.WrapAndAddProto(frameworkLogLevelReloaderName, logger.NewLogLevelReloader(flm, "FrameworkLogger"))
+
 	return nil
 
 }
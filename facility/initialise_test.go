@@ -0,0 +1,107 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package facility
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/facility/healthcheck"
+	"github.com/graniticio/granitic/v2/facility/httpserver"
+	"github.com/graniticio/granitic/v2/facility/logger"
+	"github.com/graniticio/granitic/v2/facility/querymanager"
+	"github.com/graniticio/granitic/v2/facility/rdbms"
+	"github.com/graniticio/granitic/v2/facility/runtimectl"
+	"github.com/graniticio/granitic/v2/facility/serviceerror"
+	"github.com/graniticio/granitic/v2/facility/taskscheduler"
+	"github.com/graniticio/granitic/v2/facility/ws"
+	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+// allFacilities creates a FacilitiesInitialisor with every built-in facility enabled
+func allFacilities(t *testing.T, additionalFiles ...string) *FacilitiesInitialisor {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	ca, err := configAccessor(lm, additionalFiles...)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	fi := NewFacilitiesInitialisor(ioc.NewComponentContainer(lm, ca, new(instance.System)), lm)
+	fi.ConfigAccessor = ca
+	fi.facilityStatus = make(map[string]interface{})
+
+	for _, fb := range []Builder{new(logger.FacilityBuilder), new(querymanager.FacilityBuilder), new(httpserver.FacilityBuilder),
+		new(ws.JSONFacilityBuilder), new(ws.XMLFacilityBuilder), new(serviceerror.FacilityBuilder), new(rdbms.FacilityBuilder),
+		new(runtimectl.FacilityBuilder), new(taskscheduler.FacilityBuilder), new(healthcheck.FacilityBuilder)} {
+
+		fi.addFacility(fb)
+		fi.facilityStatus[fb.FacilityName()] = true
+	}
+
+	return fi
+}
+
+func TestDefaultConfigMatchesSchemas(t *testing.T) {
+
+	fi := allFacilities(t)
+
+	warnings, err := fi.ConfigAccessor.Validate(fi.configSchemas()...)
+
+	if err != nil {
+		t.Fatalf("Built-in configuration does not match facility schemas: %s", err.Error())
+	}
+
+	if len(warnings) > 0 {
+		t.Errorf("Unexpected warnings validating built-in configuration: %v", warnings)
+	}
+}
+
+func TestInvalidConfigReportsAllProblems(t *testing.T) {
+
+	fi := allFacilities(t, test.FilePath(filepath.Join("schema", "invalid.json")))
+
+	fi.ConfigSchemas = []*config.Schema{{
+		Path:   "App",
+		Fields: map[string]*config.SchemaField{"Name": {Type: config.StringValue, Required: true}},
+	}}
+
+	warnings, err := fi.ConfigAccessor.Validate(fi.configSchemas()...)
+
+	sort.Strings(warnings)
+
+	test.ExpectInt(t, len(warnings), 2)
+	test.ExpectString(t, warnings[0], "Facilities.HTTPSever is not a recognised configuration key (did you mean Facilities.HTTPServer?)")
+	test.ExpectString(t, warnings[1], "HTTPServer.Prot is not a recognised configuration key (did you mean HTTPServer.Port?)")
+
+	se, found := err.(config.SchemaError)
+
+	if !found {
+		t.Fatalf("Expected a config.SchemaError, got %v", err)
+	}
+
+	expected := []string{
+		"ApplicationLogger.GlobalLogLevel is invalid",
+		"HTTPServer.TooBusyStatus has a value of 999 but must be between 100 and 599",
+		"HTTPServer.AccessLog.Entry is \"XML\" but must be one of \"TEXT\", \"JSON\"",
+		"JSONWs.Marshal.PrettyPrint should be a bool but is \"yes\"",
+		"App.Name is required but has not been set",
+	}
+
+	test.ExpectInt(t, len(se.Problems), len(expected))
+
+	for _, e := range expected {
+		if !strings.Contains(err.Error(), e) {
+			t.Errorf("Expected problems to include %q: %s", e, err.Error())
+		}
+	}
+}
@@ -33,3 +33,8 @@ func (id *InstanceIDDecorator) DecorateComponent(subject *ioc.Component, contain
 
 	r.RegisterInstanceID(id.InstanceID)
 }
+
+// DecoratesScopedInstances returns true, as injecting the Identifier only modifies the subject component.
+func (id *InstanceIDDecorator) DecoratesScopedInstances() bool {
+	return true
+}
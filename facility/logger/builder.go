@@ -22,6 +22,7 @@ import (
 const applicationLoggingDecoratorName = instance.FrameworkPrefix + "ApplicationLoggingDecorator"
 const applicationLoggingManagerName = instance.FrameworkPrefix + "ApplicationLoggingManager"
 const applicationLoggingFormatterName = instance.FrameworkPrefix + "ApplicationLoggingEntryFormatter"
+const applicationLogLevelReloaderName = instance.FrameworkPrefix + "ApplicationLogLevelReloader"
 
 const textEntryMode = "TEXT"
 const jsonEntryMode = "JSON"
@@ -76,6 +77,8 @@ func (alfb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager
 
 	cn.WrapAndAddProto(applicationLoggingDecoratorName, ald)
 
+	cn.WrapAndAddProto(applicationLogLevelReloaderName, NewLogLevelReloader(alm, "ApplicationLogger"))
+
 	AddRuntimeCommandsForLogging(ca, alm, lm, cn)
 
 	return nil
@@ -143,7 +146,10 @@ func BuildFormatterFromConfig(ca *config.Accessor) (logging.StringFormatter, err
 
 		cfg := new(logging.JSONConfig)
 
-		ca.Populate("LogWriting.Format.JSON", cfg)
+		if err := ca.Populate("LogWriting.Format.JSON", cfg); err != nil {
+			return nil, err
+		}
+
 		jmf.Config = cfg
 
 		cfg.UTC, _ = ca.BoolVal("LogWriting.Format.UtcTimes")
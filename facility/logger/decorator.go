@@ -75,6 +75,11 @@ func (ald *applicationLogDecorator) DecorateComponent(subject *ioc.Component, co
 
 }
 
+// DecoratesScopedInstances returns true, as injecting a Logger only modifies the subject component.
+func (ald *applicationLogDecorator) DecoratesScopedInstances() bool {
+	return true
+}
+
 // FrameworkLogDecorator injects a framework logger into Granitic framework components.
 type FrameworkLogDecorator struct {
 	// The framework ComponentLoggerManager (as opposed to the application ComponentLoggerManager)
@@ -122,3 +127,8 @@ func (fld *FrameworkLogDecorator) DecorateComponent(component *ioc.Component, co
 	}
 
 }
+
+// DecoratesScopedInstances returns true, as injecting a Logger only modifies the subject component.
+func (fld *FrameworkLogDecorator) DecoratesScopedInstances() bool {
+	return true
+}
@@ -0,0 +1,83 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package logger
+
+import (
+	"fmt"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/logging"
+)
+
+// NewLogLevelReloader creates a component that will update the global and per-component log levels of the supplied
+// ComponentLoggerManager when configuration is reloaded. The levels are read from the GlobalLogLevel and ComponentLogLevels
+// fields under the supplied config path (e.g. ApplicationLogger or FrameworkLogger).
+func NewLogLevelReloader(lm *logging.ComponentLoggerManager, configPath string) *LogLevelReloader {
+	return &LogLevelReloader{LoggerManager: lm, ConfigPath: configPath}
+}
+
+// LogLevelReloader implements ioc.ConfigReloadable to allow log levels to be changed without restarting the application.
+// Any component not listed in the reloaded ComponentLogLevels has its log level reset to ALL, so that it is once again
+// controlled by the global log level.
+type LogLevelReloader struct {
+	// The manager whose levels will be changed
+	LoggerManager *logging.ComponentLoggerManager
+
+	// The path in configuration under which GlobalLogLevel and ComponentLogLevels are defined
+	ConfigPath string
+}
+
+// ReloadConfig implements ioc.ConfigReloadable.ReloadConfig. The change is rejected if any of the log levels are invalid.
+func (llr *LogLevelReloader) ReloadConfig(ca *config.Accessor) error {
+
+	globalPath := llr.ConfigPath + ".GlobalLogLevel"
+	componentsPath := llr.ConfigPath + ".ComponentLogLevels"
+
+	label, err := ca.StringVal(globalPath)
+
+	if err != nil {
+		return err
+	}
+
+	global, err := logging.LogLevelFromLabel(label)
+
+	if err != nil {
+		return fmt.Errorf("%s: %s", globalPath, err.Error())
+	}
+
+	levels := make(map[string]interface{})
+
+	for _, cl := range llr.LoggerManager.CurrentLevels() {
+		levels[cl.Name] = logging.AllLabel
+	}
+
+	if ca.PathExists(componentsPath) {
+
+		configured, err := ca.ObjectVal(componentsPath)
+
+		if err != nil {
+			return err
+		}
+
+		for name, v := range configured {
+
+			label, found := v.(string)
+
+			if !found {
+				return fmt.Errorf("%s.%s must be a string", componentsPath, name)
+			}
+
+			if _, err := logging.LogLevelFromLabel(label); err != nil {
+				return fmt.Errorf("%s.%s: %s", componentsPath, name, err.Error())
+			}
+
+			levels[name] = label
+		}
+	}
+
+	llr.LoggerManager.SetGlobalThreshold(global)
+	llr.LoggerManager.SetInitialLogLevels(levels)
+
+	return nil
+}
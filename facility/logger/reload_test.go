@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/logging"
+)
+
+func TestLogLevelReload(t *testing.T) {
+
+	lm := logging.CreateComponentLoggerManager(logging.Info, map[string]interface{}{"noisy": "ERROR"}, []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+	noisy := lm.CreateLogger("noisy")
+	quiet := lm.CreateLogger("quiet")
+
+	llr := NewLogLevelReloader(lm, "ApplicationLogger")
+
+	ca := func(global string, levels map[string]interface{}) *config.Accessor {
+		al := map[string]interface{}{"GlobalLogLevel": global, "ComponentLogLevels": levels}
+		return &config.Accessor{JSONData: map[string]interface{}{"ApplicationLogger": al}}
+	}
+
+	if err := llr.ReloadConfig(ca("WARN", map[string]interface{}{"quiet": "DEBUG"})); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if lm.GlobalLevel() != logging.Warn {
+		t.Errorf("Expected global level to be changed")
+	}
+
+	if !quiet.IsLevelEnabled(logging.Debug) {
+		t.Errorf("Expected component level to be applied")
+	}
+
+	if noisy.IsLevelEnabled(logging.Info) || !noisy.IsLevelEnabled(logging.Warn) {
+		t.Errorf("Expected component no longer listed to follow the global level")
+	}
+
+	if err := llr.ReloadConfig(ca("LOUD", map[string]interface{}{})); err == nil {
+		t.Errorf("Expected invalid global level to be rejected")
+	}
+
+	if err := llr.ReloadConfig(ca("INFO", map[string]interface{}{"quiet": "CHATTY"})); err == nil {
+		t.Errorf("Expected invalid component level to be rejected")
+	}
+
+	if lm.GlobalLevel() != logging.Warn {
+		t.Errorf("Expected levels to be unchanged after a rejected reload")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package logger
+
+import (
+	"fmt"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/logging"
+)
+
+// FrameworkLoggingSchemas returns schemas describing the FrameworkLogger and LogWriting configuration, which is used
+// whether or not the ApplicationLogging facility is enabled.
+func FrameworkLoggingSchemas() []*config.Schema {
+	return []*config.Schema{
+		levelsSchema("FrameworkLogger"),
+		{
+			Path: "LogWriting",
+			Fields: map[string]*config.SchemaField{
+				"EnableConsoleLogging": {Type: config.BoolValue, Required: true},
+				"EnableFileLogging":    {Type: config.BoolValue, Required: true},
+				"File": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+					"LogPath":    {Type: config.StringValue, Min: config.Limit(1)},
+					"BufferSize": {Type: config.IntValue, Min: config.Limit(0)},
+				}},
+				"Format": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+					"Entry":        {Type: config.StringValue, Required: true, Enum: []interface{}{textEntryMode, jsonEntryMode}},
+					"UtcTimes":     {Type: config.BoolValue},
+					"Unset":        {Type: config.StringValue},
+					"PrefixFormat": {Type: config.StringValue},
+					"PrefixPreset": {Type: config.StringValue},
+					"JSON":         JSONFormatSchema(),
+				}},
+			},
+		},
+	}
+}
+
+// ConfigSchemas implements facility.SchemaProvider
+func (alfb *FacilityBuilder) ConfigSchemas() []*config.Schema {
+	return []*config.Schema{levelsSchema("ApplicationLogger")}
+}
+
+// JSONFormatSchema describes the configuration of a JSON log line: a prefix, a suffix and a list of fields, each of
+// which is an array of strings.
+func JSONFormatSchema() *config.SchemaField {
+	return &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"Prefix": {Type: config.StringValue},
+		"Suffix": {Type: config.StringValue},
+		"Fields": {Type: config.ArrayValue, Elements: &config.SchemaField{
+			Type:     config.ArrayValue,
+			Min:      config.Limit(2),
+			Elements: &config.SchemaField{Type: config.StringValue},
+		}},
+	}}
+}
+
+func levelsSchema(path string) *config.Schema {
+
+	level := &config.SchemaField{Type: config.StringValue, Check: checkLogLevel}
+
+	return &config.Schema{
+		Path: path,
+		Fields: map[string]*config.SchemaField{
+			"GlobalLogLevel":     {Type: config.StringValue, Required: true, Check: checkLogLevel},
+			"ComponentLogLevels": {Type: config.ObjectValue, Elements: level},
+		},
+	}
+}
+
+func checkLogLevel(v interface{}) error {
+
+	if _, err := logging.LogLevelFromLabel(fmt.Sprint(v)); err != nil {
+		return err
+	}
+
+	return nil
+}
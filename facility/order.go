@@ -0,0 +1,138 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package facility
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// IsEnabled checks whether the named facility has been enabled in the Facilities section of configuration. Builders
+// can use this to change how they build their facility according to which of their optional dependencies are enabled.
+func IsEnabled(ca *config.Accessor, facilityName string) bool {
+
+	enabled, err := ca.BoolVal("Facilities." + facilityName)
+
+	return err == nil && enabled
+}
+
+// orderFacilities returns the enabled facilities in the order in which they should be built. Each facility is built
+// after the facilities it depends on and after any of its optional dependencies that are enabled. Facilities that do not
+// depend on each other are built in the order in which they were added. An error describing every missing dependency is
+// returned if any enabled facility depends on a facility that is not enabled, or if dependencies are circular.
+func (fi *FacilitiesInitialisor) orderFacilities() ([]Builder, error) {
+
+	enabled := make([]Builder, 0)
+	byName := make(map[string]Builder)
+
+	for _, fb := range fi.facilities {
+
+		name := fb.FacilityName()
+
+		if fi.facilityStatus[name] == nil {
+			fi.Logger.LogWarnf("No setting for facility %s in the Facilities configuration object - will not enable this facility", name)
+			continue
+		}
+
+		if fi.facilityStatus[name].(bool) {
+			enabled = append(enabled, fb)
+			byName[name] = fb
+		}
+	}
+
+	var problems []string
+
+	// The facilities that must be built before each facility
+	before := make(map[Builder][]Builder)
+
+	for _, fb := range enabled {
+
+		name := fb.FacilityName()
+
+		for _, dep := range fb.DependsOnFacilities() {
+
+			if on, found := fi.facilityStatus[dep].(bool); !found || !on {
+				problems = append(problems, fmt.Sprintf("Facility %s depends on facility %s, but %s is not enabled in configuration.", name, dep, dep))
+				continue
+			}
+
+			if db := byName[dep]; db != nil {
+				before[fb] = append(before[fb], db)
+			}
+		}
+
+		if op, found := fb.(OptionalDependencyProvider); found {
+
+			for _, dep := range op.OptionallyDependsOnFacilities() {
+
+				if db := byName[dep]; db != nil {
+					before[fb] = append(before[fb], db)
+				} else {
+					fi.Logger.LogDebugf("Facility %s can use facility %s, but %s is not enabled", name, dep, dep)
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		message := fmt.Sprintf("Unable to build facilities (%d missing dependencies): %s", len(problems), strings.Join(problems, " "))
+		return nil, errors.New(message)
+	}
+
+	ordered := make([]Builder, 0, len(enabled))
+	built := make(map[Builder]bool)
+
+	for len(ordered) < len(enabled) {
+
+		next := nextBuildable(enabled, before, built)
+
+		if next == nil {
+
+			var circular []string
+
+			for _, fb := range enabled {
+				if !built[fb] {
+					circular = append(circular, fb.FacilityName())
+				}
+			}
+
+			message := fmt.Sprintf("Unable to build facilities as the dependencies of these facilities are circular: %s", strings.Join(circular, ", "))
+			return nil, errors.New(message)
+		}
+
+		built[next] = true
+		ordered = append(ordered, next)
+	}
+
+	return ordered, nil
+}
+
+// nextBuildable finds the first facility that has not been built and whose dependencies have all been built
+func nextBuildable(enabled []Builder, before map[Builder][]Builder, built map[Builder]bool) Builder {
+
+	for _, fb := range enabled {
+
+		if built[fb] {
+			continue
+		}
+
+		ready := true
+
+		for _, dep := range before[fb] {
+			if !built[dep] {
+				ready = false
+				break
+			}
+		}
+
+		if ready {
+			return fb
+		}
+	}
+
+	return nil
+}
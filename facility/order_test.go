@@ -0,0 +1,114 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package facility
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type stubBuilder struct {
+	name     string
+	deps     []string
+	optional []string
+	built    *[]string
+}
+
+func (sb *stubBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager, ca *config.Accessor, cn *ioc.ComponentContainer) error {
+	*sb.built = append(*sb.built, sb.name)
+	return nil
+}
+
+func (sb *stubBuilder) FacilityName() string {
+	return sb.name
+}
+
+func (sb *stubBuilder) DependsOnFacilities() []string {
+	return sb.deps
+}
+
+type optionalStubBuilder struct {
+	stubBuilder
+}
+
+func (osb *optionalStubBuilder) OptionallyDependsOnFacilities() []string {
+	return osb.optional
+}
+
+func stubInitialisor(status map[string]interface{}, builders ...Builder) *FacilitiesInitialisor {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	fi := NewFacilitiesInitialisor(nil, lm)
+	fi.facilityStatus = status
+
+	for _, b := range builders {
+		fi.addFacility(b)
+	}
+
+	return fi
+}
+
+func TestFacilitiesBuiltInDependencyOrder(t *testing.T) {
+
+	var built []string
+
+	fi := stubInitialisor(map[string]interface{}{"A": true, "B": true, "C": true, "D": true, "E": false},
+		&stubBuilder{name: "A", deps: []string{"C"}, built: &built},
+		&optionalStubBuilder{stubBuilder{name: "B", optional: []string{"D", "E"}, built: &built}},
+		&stubBuilder{name: "C", built: &built},
+		&stubBuilder{name: "D", deps: []string{"C"}, built: &built},
+		&stubBuilder{name: "E", built: &built},
+	)
+
+	test.ExpectNil(t, fi.buildEnabledFacilities())
+
+	test.ExpectString(t, strings.Join(built, ","), "C,A,D,B")
+}
+
+func TestMissingDependenciesReportedTogether(t *testing.T) {
+
+	var built []string
+
+	fi := stubInitialisor(map[string]interface{}{"A": true, "B": true, "C": false},
+		&stubBuilder{name: "A", deps: []string{"C"}, built: &built},
+		&stubBuilder{name: "B", deps: []string{"C", "Unknown"}, built: &built},
+		&stubBuilder{name: "C", built: &built},
+	)
+
+	err := fi.buildEnabledFacilities()
+
+	test.ExpectNotNil(t, err)
+	test.ExpectInt(t, len(built), 0)
+
+	for _, e := range []string{"(3 missing dependencies)", "Facility A depends on facility C", "Facility B depends on facility C", "Facility B depends on facility Unknown"} {
+		if !strings.Contains(err.Error(), e) {
+			t.Errorf("Expected error to contain %q: %s", e, err.Error())
+		}
+	}
+}
+
+func TestCircularDependencies(t *testing.T) {
+
+	var built []string
+
+	fi := stubInitialisor(map[string]interface{}{"A": true, "B": true, "C": true},
+		&stubBuilder{name: "A", built: &built},
+		&stubBuilder{name: "B", deps: []string{"C"}, built: &built},
+		&optionalStubBuilder{stubBuilder{name: "C", optional: []string{"B"}, built: &built}},
+	)
+
+	err := fi.buildEnabledFacilities()
+
+	if err == nil || !strings.HasSuffix(err.Error(), "circular: B, C") {
+		t.Errorf("Expected an error about circular dependencies, got %v", err)
+	}
+
+	test.ExpectInt(t, len(built), 0)
+}
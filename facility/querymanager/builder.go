@@ -91,6 +91,7 @@ import (
 	"github.com/graniticio/granitic/v2/instance"
 	"github.com/graniticio/granitic/v2/ioc"
 	"github.com/graniticio/granitic/v2/logging"
+	"strconv"
 	"strings"
 )
 
@@ -114,7 +115,10 @@ type FacilityBuilder struct {
 func (qmfb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager, ca *config.Accessor, cn *ioc.ComponentContainer) error {
 
 	queryManager := new(dsquery.TemplatedQueryManager)
-	ca.Populate("QueryManager", queryManager)
+
+	if err := ca.Populate("QueryManager", queryManager); err != nil {
+		return err
+	}
 
 	cn.WrapAndAddProto(QueryManagerComponentName, queryManager)
 
@@ -152,19 +156,42 @@ func (qmfb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager
 
 	if vpName == confValueProcess {
 		vp = new(dsquery.ConfigurableProcessor)
+
+		if err := ca.Populate(vpConfig, vp); err != nil {
+			return err
+		}
+
 	} else if vpName == sqlValueProcess {
 
-		vp = new(dsquery.SQLProcessor)
+		// BoolTrue and BoolFalse may be configured as numbers (e.g. 1 and 0) as well as strings
+		sp := new(dsquery.SQLProcessor)
+		sp.BoolTrue, _ = sqlLiteral(ca.Value(vpConfig + ".BoolTrue"))
+		sp.BoolFalse, _ = sqlLiteral(ca.Value(vpConfig + ".BoolFalse"))
 
+		vp = sp
 	}
 
-	ca.Populate(vpConfig, vp)
-
 	queryManager.ValueProcessor = vp
 
 	return nil
 }
 
+// sqlLiteral converts a configured number, string or bool to the text that should be used in SQL. Returns false if
+// the value is of any other type.
+func sqlLiteral(v interface{}) (string, bool) {
+
+	switch t := v.(type) {
+	case string:
+		return t, true
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(t), true
+	}
+
+	return "", false
+}
+
 func (qmfb *FacilityBuilder) checkProcessor(value string) bool {
 
 	value = strings.ToUpper(value)
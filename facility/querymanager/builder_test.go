@@ -105,12 +105,15 @@ func TestBuilderWithJSONValueProcessor(t *testing.T) {
 		t.Fatalf("Unexpected type for %s %t", QueryManagerComponentName, mc)
 	}
 
-	_, okay = tqm.ValueProcessor.(*dsquery.SQLProcessor)
+	sp, okay := tqm.ValueProcessor.(*dsquery.SQLProcessor)
 
 	if !okay {
 		t.Fatalf("Unexpected type for ValueProcessor %T", tqm.ValueProcessor)
 	}
 
+	test.ExpectString(t, sp.BoolTrue, "1")
+	test.ExpectString(t, sp.BoolFalse, "0")
+
 	tqm.FrameworkLogger = lm.CreateLogger(QueryManagerComponentName)
 
 	if err = tqm.StartComponent(); err != nil {
@@ -0,0 +1,62 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package querymanager
+
+import (
+	"fmt"
+
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// ConfigSchemas implements facility.SchemaProvider
+func (qmfb *FacilityBuilder) ConfigSchemas() []*config.Schema {
+
+	checkProcessor := func(v interface{}) error {
+
+		if !qmfb.checkProcessor(fmt.Sprint(v)) {
+			return fmt.Errorf("must be %s or %s", confValueProcess, sqlValueProcess)
+		}
+
+		return nil
+	}
+
+	qm := &config.Schema{
+		Path: QueryManagerFacilityName,
+		Fields: map[string]*config.SchemaField{
+			"TemplateLocation":            {Type: config.StringValue, Required: true},
+			"QueryIDPrefix":               {Type: config.StringValue},
+			"TrimIDWhiteSpace":            {Type: config.BoolValue},
+			"VarMatchRegEx":               {Type: config.StringValue},
+			"NewLine":                     {Type: config.StringValue},
+			"CreateDefaultValueProcessor": {Type: config.BoolValue},
+			"ProcessorName":               {Type: config.StringValue, Check: checkProcessor},
+			"ElementSeparator":            {Type: config.StringValue},
+			"ValueProcessors": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+				"Configurable": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+					"WrapStrings":                          {Type: config.BoolValue},
+					"StringWrapWith":                       {Type: config.StringValue},
+					"DisableWrapWhenDefaultParameterValue": {Type: config.BoolValue},
+					"UseDefaultForMissingParameter":        {Type: config.BoolValue},
+					"EscapeDefaultValues":                  {Type: config.BoolValue},
+				}},
+				"SQL": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+					"BoolTrue":  {Check: checkSQLLiteral},
+					"BoolFalse": {Check: checkSQLLiteral},
+				}},
+			}},
+		},
+	}
+
+	return []*config.Schema{qm}
+}
+
+// checkSQLLiteral accepts the numbers, strings and bools that can be used as SQL representations of bool values
+func checkSQLLiteral(v interface{}) error {
+
+	if _, valid := sqlLiteral(v); !valid {
+		return fmt.Errorf("must be a number, string or bool")
+	}
+
+	return nil
+}
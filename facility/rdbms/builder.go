@@ -63,7 +63,10 @@ func (rafb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager
 
 		// Create config for a default ClientManager
 		mc := new(rdbms.ClientManagerConfig)
-		ca.Populate("RdbmsAccess.Default", mc)
+
+		if err := ca.Populate("RdbmsAccess.Default", mc); err != nil {
+			return err
+		}
 
 		proto := ioc.CreateProtoComponent(mc, rdbmsClientManagerConfigName)
 
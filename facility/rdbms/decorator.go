@@ -66,3 +66,8 @@ func (cmd *clientManagerDecorator) DecorateComponent(component *ioc.Component, c
 	}
 
 }
+
+// DecoratesScopedInstances returns true, as injecting a client manager only modifies the subject component.
+func (cmd *clientManagerDecorator) DecoratesScopedInstances() bool {
+	return true
+}
@@ -0,0 +1,26 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package rdbms
+
+import (
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// ConfigSchemas implements facility.SchemaProvider
+func (rafb *FacilityBuilder) ConfigSchemas() []*config.Schema {
+
+	ra := &config.Schema{
+		Path: "RdbmsAccess",
+		Fields: map[string]*config.SchemaField{
+			"Default": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+				"InjectFieldNames":    {Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue}},
+				"BlockUntilConnected": {Type: config.BoolValue},
+				"ClientName":          {Type: config.StringValue},
+				"ManagerName":         {Type: config.StringValue},
+			}},
+		},
+	}
+
+	return []*config.Schema{ra}
+}
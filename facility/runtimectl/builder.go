@@ -76,6 +76,8 @@ const (
 	shutdownCommandComp        = instance.FrameworkPrefix + "CommandShutdown"
 	helpCommandComp            = instance.FrameworkPrefix + "CommandHelp"
 	componentsCommandComp      = instance.FrameworkPrefix + "CommandComponents"
+	graphCommandComp           = instance.FrameworkPrefix + "CommandGraph"
+	reloadCommandComp          = instance.FrameworkPrefix + "CommandReload"
 	stopCommandComp            = instance.FrameworkPrefix + "CommandStop"
 	suspendCommandComp         = instance.FrameworkPrefix + "CommandSuspend"
 	resumeCommandComp          = instance.FrameworkPrefix + "CommandResume"
@@ -90,21 +92,36 @@ type FacilityBuilder struct {
 func (fb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager, ca *config.Accessor, cc *ioc.ComponentContainer) error {
 
 	sv := new(httpserver.HTTPServer)
-	ca.Populate("RuntimeCtl.Server", sv)
+
+	if err := ca.Populate("RuntimeCtl.Server", sv); err != nil {
+		return err
+	}
 
 	cc.WrapAndAddProto(Server, sv)
 
 	rw := new(ws.MarshallingResponseWriter)
-	ca.Populate("RuntimeCtl.ResponseWriter", rw)
+
+	if err := ca.Populate("RuntimeCtl.ResponseWriter", rw); err != nil {
+		return err
+	}
+
 	rw.FrameworkLogger = lm.CreateLogger(runtimeCtlResponseWriter)
 	sv.AbnormalStatusWriter = rw
 
 	mw := new(json.MarshalingWriter)
-	ca.Populate("RuntimeCtl.Marshal", mw)
+
+	if err := ca.Populate("RuntimeCtl.Marshal", mw); err != nil {
+		return err
+	}
+
 	rw.MarshalingWriter = mw
 
 	wr := new(json.GraniticJSONResponseWrapper)
-	ca.Populate("RuntimeCtl.ResponseWrapper", wr)
+
+	if err := ca.Populate("RuntimeCtl.ResponseWrapper", wr); err != nil {
+		return err
+	}
+
 	rw.ResponseWrapper = wr
 
 	rw.ErrorFormatter = new(json.GraniticJSONErrorFormatter)
@@ -114,14 +131,20 @@ func (fb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager,
 	feg := new(ws.FrameworkErrorGenerator)
 	feg.FrameworkLogger = lm.CreateLogger(runtimeCtlFrameworkErrors)
 
-	ca.Populate("FrameworkServiceErrors", feg)
+	if err := ca.Populate("FrameworkServiceErrors", feg); err != nil {
+		return err
+	}
 
 	rw.FrameworkErrors = feg
 
 	//Handler
 	h := new(handler.WsHandler)
 	h.PreventAutoWiring = true
-	ca.Populate("RuntimeCtl.CommandHandler", h)
+
+	if err := ca.Populate("RuntimeCtl.CommandHandler", h); err != nil {
+		return err
+	}
+
 	h.Log = lm.CreateLogger(runtimeCtlCommandHandler)
 	h.DisablePathParsing = true
 	h.DisableQueryParsing = true
@@ -173,7 +196,9 @@ func (fb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager,
 	//Command manager
 	cm := new(ctl.CommandManager)
 
-	ca.Populate("RuntimeCtl.Manager", cm)
+	if err := ca.Populate("RuntimeCtl.Manager", cm); err != nil {
+		return err
+	}
 
 	if cm.Disabled == nil {
 		cm.DisabledLookup = types.NewEmptyOrderedStringSet()
@@ -213,6 +238,16 @@ func (fb *FacilityBuilder) createBuiltinCommands(lm *logging.ComponentLoggerMana
 	cs := new(componentsCommand)
 	fb.addCommand(cc, componentsCommandComp, cs)
 
+	gc := new(graphCommand)
+	fb.addCommand(cc, graphCommandComp, gc)
+
+	if !cm.DisabledLookup.Contains(graphCommandName) {
+		cc.RecordWiring()
+	}
+
+	rc := new(reloadCommand)
+	fb.addCommand(cc, reloadCommandComp, rc)
+
 	stopc := newStopCommand()
 	fb.addCommand(cc, stopCommandComp, stopc)
 
@@ -0,0 +1,85 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package runtimectl
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/graniticio/granitic/v2/ctl"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/ws"
+)
+
+const (
+	graphCommandName = "graph"
+	graphSummary     = "Export the graph of how components in the IoC container were wired together."
+	graphUsage       = "graph [-f json|dot]"
+	graphHelp        = "Shows every component in the IoC container with its type, the components injected into it, the configuration paths used to populate it and any fields set by decorators."
+	graphHelpTwo     = "Output is JSON by default. If the '-f dot' argument is supplied, the output is a Graphviz digraph that can be rendered with e.g. 'grnc-ctl graph -f dot | dot -Tsvg > components.svg'"
+	formatArg        = "f"
+)
+
+type graphCommand struct {
+	FrameworkLogger logging.Logger
+	container       *ioc.ComponentContainer
+}
+
+func (c *graphCommand) Container(container *ioc.ComponentContainer) {
+	c.container = container
+}
+
+func (c *graphCommand) ExecuteCommand(qualifiers []string, args map[string]string) (*ctl.CommandOutput, []*ws.CategorisedError) {
+
+	format := ioc.WiringFormatJSON
+
+	if f := args[formatArg]; f != "" {
+		format = strings.ToLower(f)
+	}
+
+	w := c.container.Wiring()
+
+	if w == nil {
+		m := "Component wiring was not captured when the container was populated. Capture is enabled when the RuntimeCtl " +
+			"facility is enabled and graph is not listed in RuntimeCtl.Manager.Disabled, or when the application is started with the -g flag"
+
+		return nil, []*ws.CategorisedError{ctl.NewCommandLogicError(m)}
+	}
+
+	var b bytes.Buffer
+
+	if err := w.Write(&b, format); err != nil {
+		return nil, []*ws.CategorisedError{ctl.NewCommandClientError(err.Error())}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	body := make([][]string, len(lines))
+
+	for i, l := range lines {
+		body[i] = []string{l}
+	}
+
+	co := new(ctl.CommandOutput)
+	co.OutputBody = body
+	co.RenderHint = ctl.Raw
+
+	return co, nil
+}
+
+func (c *graphCommand) Name() string {
+	return graphCommandName
+}
+
+func (c *graphCommand) Summmary() string {
+	return graphSummary
+}
+
+func (c *graphCommand) Usage() string {
+	return graphUsage
+}
+
+func (c *graphCommand) Help() []string {
+	return []string{graphHelp, graphHelpTwo}
+}
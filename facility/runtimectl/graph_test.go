@@ -0,0 +1,55 @@
+package runtimectl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/ctl"
+	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type graphTarget struct {
+	Dep *graphTarget
+}
+
+func TestGraphCommand(t *testing.T) {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+	cc := ioc.NewComponentContainer(lm, new(config.Accessor), new(instance.System))
+	cc.RecordWiring()
+
+	gc := new(graphCommand)
+	gc.Container(cc)
+
+	if _, errs := gc.ExecuteCommand([]string{}, map[string]string{}); len(errs) == 0 {
+		t.Errorf("Expected an error before the container is populated")
+	} else if !strings.Contains(errs[0].Message, "RuntimeCtl.Manager.Disabled") || !strings.Contains(errs[0].Message, "-g") {
+		t.Errorf("Expected the error to explain how to enable wiring capture, was %s", errs[0].Message)
+	}
+
+	p := ioc.CreateProtoComponent(new(graphTarget), "a")
+	p.AddDependency("Dep", "b")
+	cc.AddProto(p)
+	cc.WrapAndAddProto("b", new(graphTarget))
+
+	test.ExpectNil(t, cc.Populate())
+
+	co, errs := gc.ExecuteCommand([]string{}, map[string]string{"f": "DOT"})
+
+	test.ExpectInt(t, len(errs), 0)
+	test.ExpectString(t, string(co.RenderHint), ctl.Raw)
+	test.ExpectString(t, co.OutputBody[0][0], "digraph components {")
+
+	co, errs = gc.ExecuteCommand([]string{}, map[string]string{})
+
+	test.ExpectInt(t, len(errs), 0)
+	test.ExpectBool(t, strings.HasPrefix(co.OutputBody[0][0], "{"), true)
+
+	if _, errs = gc.ExecuteCommand([]string{}, map[string]string{"f": "png"}); len(errs) == 0 {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package runtimectl
+
+import (
+	"github.com/graniticio/granitic/v2/ctl"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/ws"
+)
+
+const (
+	reloadCommandName = "reload"
+	reloadSummary     = "Re-read configuration and apply it to components that support reloading."
+	reloadUsage       = "reload"
+	reloadHelp        = "Re-reads and merges the application's configuration files and passes the new configuration to every component that implements ioc.ConfigReloadable."
+	reloadHelpTwo     = "Each of those components is listed along with whether it accepted or rejected the change. Sending SIGHUP to the application's process has the same effect."
+	reloadHeader      = "Configuration reloaded:"
+	reloadNone        = "Configuration reloaded but no components implement ioc.ConfigReloadable"
+	accepted          = "accepted"
+	rejected          = "rejected: "
+)
+
+type reloadCommand struct {
+	FrameworkLogger logging.Logger
+	container       *ioc.ComponentContainer
+}
+
+func (c *reloadCommand) Container(container *ioc.ComponentContainer) {
+	c.container = container
+}
+
+func (c *reloadCommand) ExecuteCommand(qualifiers []string, args map[string]string) (*ctl.CommandOutput, []*ws.CategorisedError) {
+
+	outcomes, err := c.container.ReloadConfig()
+
+	if err != nil {
+		return nil, []*ws.CategorisedError{ctl.NewCommandLogicError(err.Error())}
+	}
+
+	co := new(ctl.CommandOutput)
+	co.RenderHint = ctl.Columns
+
+	if len(outcomes) == 0 {
+		co.OutputHeader = reloadNone
+		return co, nil
+	}
+
+	body := make([][]string, len(outcomes))
+
+	for i, o := range outcomes {
+
+		result := accepted
+
+		if !o.Accepted() {
+			result = rejected + o.Err.Error()
+		}
+
+		body[i] = []string{o.Component, result}
+	}
+
+	co.OutputHeader = reloadHeader
+	co.OutputBody = body
+
+	return co, nil
+}
+
+func (c *reloadCommand) Name() string {
+	return reloadCommandName
+}
+
+func (c *reloadCommand) Summmary() string {
+	return reloadSummary
+}
+
+func (c *reloadCommand) Usage() string {
+	return reloadUsage
+}
+
+func (c *reloadCommand) Help() []string {
+	return []string{reloadHelp, reloadHelpTwo}
+}
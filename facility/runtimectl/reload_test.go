@@ -0,0 +1,49 @@
+package runtimectl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type reloadTarget struct {
+	err error
+}
+
+func (rt *reloadTarget) ReloadConfig(ca *config.Accessor) error {
+	return rt.err
+}
+
+func TestReloadCommand(t *testing.T) {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+	cc := ioc.NewComponentContainer(lm, new(config.Accessor), new(instance.System))
+
+	cc.WrapAndAddProto("a", new(reloadTarget))
+	cc.WrapAndAddProto("b", &reloadTarget{err: errors.New("no")})
+
+	test.ExpectNil(t, cc.Populate())
+
+	rc := new(reloadCommand)
+	rc.Container(cc)
+
+	if _, errs := rc.ExecuteCommand([]string{}, map[string]string{}); len(errs) == 0 {
+		t.Errorf("Expected an error when configuration cannot be loaded")
+	}
+
+	cc.SetConfigLoader(func() (*config.Accessor, error) {
+		return new(config.Accessor), nil
+	})
+
+	co, errs := rc.ExecuteCommand([]string{}, map[string]string{})
+
+	test.ExpectInt(t, len(errs), 0)
+	test.ExpectInt(t, len(co.OutputBody), 2)
+	test.ExpectString(t, co.OutputBody[0][1], "accepted")
+	test.ExpectString(t, co.OutputBody[1][1], "rejected: no")
+}
@@ -0,0 +1,43 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package runtimectl
+
+import (
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/facility/httpserver"
+	fws "github.com/graniticio/granitic/v2/facility/ws"
+)
+
+// ConfigSchemas implements facility.SchemaProvider
+func (fb *FacilityBuilder) ConfigSchemas() []*config.Schema {
+
+	stringList := &config.SchemaField{Type: config.ArrayValue, Elements: &config.SchemaField{Type: config.StringValue}}
+	rules := &config.SchemaField{Type: config.ArrayValue, Elements: stringList}
+
+	runtimeCtl := &config.Schema{
+		Path: "RuntimeCtl",
+		Fields: map[string]*config.SchemaField{
+			"Manager": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+				"Disabled": stringList,
+			}},
+			"Server": {Type: config.ObjectValue, Fields: httpserver.ServerSchemaFields()},
+			"ResponseWriter": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+				"DefaultHeaders":   {Type: config.ObjectValue, Elements: &config.SchemaField{Type: config.StringValue}},
+				"IncludeRequestID": {Type: config.BoolValue},
+				"RequestIDHeader":  {Type: config.StringValue},
+			}},
+			"Marshal":         fws.MarshalSchema(),
+			"ResponseWrapper": fws.ResponseWrapperSchema(),
+			"CommandHandler": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+				"HTTPMethod":  {Type: config.StringValue},
+				"PathPattern": {Type: config.StringValue},
+			}},
+			"SharedRules":       {Type: config.ObjectValue, Elements: stringList},
+			"CommandValidation": rules,
+			"Errors":            rules,
+		},
+	}
+
+	return []*config.Schema{runtimeCtl, fws.FrameworkErrorsSchema()}
+}
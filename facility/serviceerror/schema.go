@@ -0,0 +1,22 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package serviceerror
+
+import (
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// ConfigSchemas implements facility.SchemaProvider
+func (fb *FacilityBuilder) ConfigSchemas() []*config.Schema {
+
+	sem := &config.Schema{
+		Path: "ServiceErrorManager",
+		Fields: map[string]*config.SchemaField{
+			"PanicOnMissing":   {Type: config.BoolValue, Required: true},
+			"ErrorDefinitions": {Type: config.StringValue, Required: true, Min: config.Limit(1)},
+		},
+	}
+
+	return []*config.Schema{sem}
+}
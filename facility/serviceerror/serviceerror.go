@@ -77,6 +77,11 @@ func (secd *consumerDecorator) DecorateComponent(component *ioc.Component, conta
 	c.ProvideErrorFinder(secd.ErrorSource)
 }
 
+// DecoratesScopedInstances returns true, as providing the error finder only modifies the subject component.
+func (secd *consumerDecorator) DecoratesScopedInstances() bool {
+	return true
+}
+
 type errorCodeSourceDecorator struct {
 	ErrorSource *grncerror.ServiceErrorManager
 }
@@ -28,7 +28,10 @@ func (fb *FacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManager,
 	ts.State = ioc.StoppedState
 
 	//Inject JSON config
-	ca.Populate(facilityName, ts)
+
+	if err := ca.Populate(facilityName, ts); err != nil {
+		return err
+	}
 
 	cn.WrapAndAddProto(TaskSchedulerComponentName, ts)
 
@@ -0,0 +1,13 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package taskscheduler
+
+import (
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// ConfigSchemas implements facility.SchemaProvider. The TaskScheduler facility has no settings of its own.
+func (fb *FacilityBuilder) ConfigSchemas() []*config.Schema {
+	return []*config.Schema{{Path: facilityName, Fields: map[string]*config.SchemaField{}}}
+}
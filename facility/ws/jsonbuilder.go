@@ -37,7 +37,11 @@ func (fb *JSONFacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManag
 	cn.WrapAndAddProto(jsonUnmarshallerComponentName, um)
 
 	rw := new(ws.MarshallingResponseWriter)
-	ca.Populate("JSONWs.ResponseWriter", rw)
+
+	if err := ca.Populate("JSONWs.ResponseWriter", rw); err != nil {
+		return err
+	}
+
 	cn.WrapAndAddProto(jsonResponseWriterComponentName, rw)
 
 	rw.StatusDeterminer = wc.StatusDeterminer
@@ -66,7 +70,10 @@ func (fb *JSONFacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManag
 				return errors.New(m)
 			}
 
-			ca.Populate("JSONWs.ResponseWrapper", wrap)
+			if err := ca.Populate("JSONWs.ResponseWrapper", wrap); err != nil {
+				return err
+			}
+
 			rw.ResponseWrapper = wrap
 		} else {
 			return err
@@ -77,7 +84,11 @@ func (fb *JSONFacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManag
 	if !cn.ModifierExists(jsonResponseWriterComponentName, "MarshalingWriter") {
 
 		mw := new(json.MarshalingWriter)
-		ca.Populate("JSONWs.Marshal", mw)
+
+		if err := ca.Populate("JSONWs.Marshal", mw); err != nil {
+			return err
+		}
+
 		rw.MarshalingWriter = mw
 	}
 
@@ -0,0 +1,114 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package ws
+
+import (
+	"github.com/graniticio/granitic/v2/config"
+)
+
+// ConfigSchemas implements facility.SchemaProvider
+func (fb *JSONFacilityBuilder) ConfigSchemas() []*config.Schema {
+
+	rw := responseWriterFields()
+	rw["IncludeRequestID"] = &config.SchemaField{Type: config.BoolValue}
+	rw["RequestIDHeader"] = &config.SchemaField{Type: config.StringValue}
+
+	jsonWs := &config.Schema{
+		Path: "JSONWs",
+		Fields: map[string]*config.SchemaField{
+			"ResponseWriter":  {Type: config.ObjectValue, Fields: rw},
+			"Marshal":         MarshalSchema(),
+			"WrapMode":        {Type: config.StringValue, Enum: []interface{}{modeBody, modeWrap}},
+			"ResponseWrapper": ResponseWrapperSchema(),
+		},
+	}
+
+	return append(commonSchemas(), jsonWs)
+}
+
+// ConfigSchemas implements facility.SchemaProvider
+func (fb *XMLFacilityBuilder) ConfigSchemas() []*config.Schema {
+
+	// Fields used by both the templated and marshalling response writers
+	rw := responseWriterFields()
+	rw["TemplateDir"] = &config.SchemaField{Type: config.StringValue}
+	rw["AbnormalTemplate"] = &config.SchemaField{Type: config.StringValue}
+	rw["ErrorTemplate"] = &config.SchemaField{Type: config.StringValue}
+	rw["CacheTemplates"] = &config.SchemaField{Type: config.BoolValue}
+	rw["PreLoad"] = &config.SchemaField{Type: config.BoolValue}
+	rw["StatusTemplates"] = &config.SchemaField{Type: config.ObjectValue, Elements: &config.SchemaField{Type: config.StringValue}}
+	rw["IncludeRequestID"] = &config.SchemaField{Type: config.BoolValue}
+	rw["RequestIDHeader"] = &config.SchemaField{Type: config.StringValue}
+
+	xmlWs := &config.Schema{
+		Path: "XMLWs",
+		Fields: map[string]*config.SchemaField{
+			"ResponseMode":   {Type: config.StringValue, Required: true, Enum: []interface{}{templateMode, marshalMode}},
+			"ResponseWriter": {Type: config.ObjectValue, Fields: rw},
+			"Marshal":        MarshalSchema(),
+		},
+	}
+
+	return append(commonSchemas(), xmlWs)
+}
+
+// MarshalSchema describes the configuration of a component that serialises response bodies.
+func MarshalSchema() *config.SchemaField {
+	return &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"PrettyPrint":  {Type: config.BoolValue},
+		"IndentString": {Type: config.StringValue},
+		"PrefixString": {Type: config.StringValue},
+	}}
+}
+
+// ResponseWrapperSchema describes the configuration of a component that wraps response bodies and errors.
+func ResponseWrapperSchema() *config.SchemaField {
+	return &config.SchemaField{Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+		"ErrorsFieldName": {Type: config.StringValue},
+		"BodyFieldName":   {Type: config.StringValue},
+	}}
+}
+
+// FrameworkErrorsSchema describes the messages used when Granitic itself needs to report a problem to a caller.
+func FrameworkErrorsSchema() *config.Schema {
+
+	message := &config.SchemaField{Type: config.ArrayValue, Min: config.Limit(2), Max: config.Limit(2), Elements: &config.SchemaField{Type: config.StringValue}}
+
+	return &config.Schema{
+		Path: "FrameworkServiceErrors",
+		Fields: map[string]*config.SchemaField{
+			"Messages":     {Type: config.ObjectValue, Elements: message},
+			"HTTPMessages": {Type: config.ObjectValue, Elements: &config.SchemaField{Type: config.StringValue}},
+		},
+	}
+}
+
+func responseWriterFields() map[string]*config.SchemaField {
+	return map[string]*config.SchemaField{
+		"DefaultHeaders": {Type: config.ObjectValue, Elements: &config.SchemaField{Type: config.StringValue}},
+	}
+}
+
+func commonSchemas() []*config.Schema {
+
+	status := func() *config.SchemaField {
+		return &config.SchemaField{Type: config.IntValue, Required: true, Min: config.Limit(100), Max: config.Limit(599)}
+	}
+
+	return []*config.Schema{
+		{
+			Path: "WS",
+			Fields: map[string]*config.SchemaField{
+				"HTTPStatus": {Type: config.ObjectValue, Fields: map[string]*config.SchemaField{
+					"NoError":    status(),
+					"Client":     status(),
+					"Security":   status(),
+					"Unexpected": status(),
+					"Logic":      status(),
+				}},
+			},
+		},
+		FrameworkErrorsSchema(),
+	}
+}
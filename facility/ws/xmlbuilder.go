@@ -30,7 +30,7 @@ func (fb *XMLFacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManage
 	wc, err := buildAndRegisterWsCommon(lm, ca, cc)
 
 	if err != nil {
-		return nil
+		return err
 	}
 
 	um := new(xml.Unmarshaller)
@@ -42,36 +42,48 @@ func (fb *XMLFacilityBuilder) BuildAndRegister(lm *logging.ComponentLoggerManage
 
 	switch mode {
 	case templateMode:
-		rw = fb.createTemplateComponents(ca, cc, wc)
+		rw, err = fb.createTemplateComponents(ca, cc, wc)
 	case marshalMode:
-		rw = fb.createMarshalComponents(ca, cc, wc)
+		rw, err = fb.createMarshalComponents(ca, cc, wc)
 	default:
 		return errors.New("XMLWs.ResponseMode must be set to either TEMPLATE or MARSHAL")
 	}
 
+	if err != nil {
+		return err
+	}
+
 	buildRegisterWsDecorator(cc, rw, um, wc, lm)
 	offerAbnormalStatusWriter(rw.(ws.AbnormalStatusWriter), cc, xmlResponseWriterName)
 
 	return nil
 }
 
-func (fb *XMLFacilityBuilder) createTemplateComponents(ca *config.Accessor, cc *ioc.ComponentContainer, wc *wsCommon) ws.ResponseWriter {
+func (fb *XMLFacilityBuilder) createTemplateComponents(ca *config.Accessor, cc *ioc.ComponentContainer, wc *wsCommon) (ws.ResponseWriter, error) {
 
 	rw := new(xml.TemplatedXMLResponseWriter)
-	ca.Populate("XMLWs.ResponseWriter", rw)
+
+	if err := ca.Populate("XMLWs.ResponseWriter", rw); err != nil {
+		return nil, err
+	}
+
 	cc.WrapAndAddProto(xmlResponseWriterName, rw)
 
 	rw.FrameworkErrors = wc.FrameworkErrors
 	rw.StatusDeterminer = wc.StatusDeterminer
 
-	return rw
+	return rw, nil
 
 }
 
-func (fb *XMLFacilityBuilder) createMarshalComponents(ca *config.Accessor, cc *ioc.ComponentContainer, wc *wsCommon) ws.ResponseWriter {
+func (fb *XMLFacilityBuilder) createMarshalComponents(ca *config.Accessor, cc *ioc.ComponentContainer, wc *wsCommon) (ws.ResponseWriter, error) {
 
 	rw := new(ws.MarshallingResponseWriter)
-	ca.Populate("XMLWs.ResponseWriter", rw)
+
+	if err := ca.Populate("XMLWs.ResponseWriter", rw); err != nil {
+		return nil, err
+	}
+
 	cc.WrapAndAddProto(xmlResponseWriterName, rw)
 
 	rw.StatusDeterminer = wc.StatusDeterminer
@@ -89,11 +101,17 @@ func (fb *XMLFacilityBuilder) createMarshalComponents(ca *config.Accessor, cc *i
 	if !cc.ModifierExists(xmlResponseWriterName, "MarshalingWriter") {
 
 		mw := new(xml.MarshalingWriter)
-		ca.Populate("XMLWs.Marshal", mw)
+
+		if ca.PathExists("XMLWs.Marshal") {
+			if err := ca.Populate("XMLWs.Marshal", mw); err != nil {
+				return nil, err
+			}
+		}
+
 		rw.MarshalingWriter = mw
 	}
 
-	return rw
+	return rw, nil
 
 }
 
@@ -53,7 +53,18 @@ default your application will support the following arguments.
 	-i An optional string that can be used to uniquely identify this instance of your application
 	-d Defer any log messages emitted by the framework until your application's logging configuration has been applied
 	-m [path] Once Granitic has merged all of your configuration files together, write it to this path and exit
+	-a Used with -m to annotate each value in the merged configuration with the file or URL it came from and the values it overrode
 	-u Generate a UUID and use it as the ID for this instance of your application (ignored if -i set)
+	-g [path] Once the IoC container has been populated, write a graph of your components to this path (DOT if the path ends in .dot or .gv, otherwise JSON) and exit
+	-p A comma separated list of configuration profiles to apply, in order (defaults to the value of the GRANITIC_PROFILE environment variable)
+	-remote-timeout [duration] The maximum time to wait for each request for configuration from a URL (default 30s)
+	-remote-retries [n] The number of times to retry a request for configuration from a URL if the server is unavailable (default 0)
+	-remote-retry-interval [duration] The time to wait before the first retry, doubling after each retry (default 1s)
+	-remote-cache [path] A directory in which to keep copies of configuration loaded from URLs, used if a URL is unavailable
+
+A bearer token to be sent with requests for configuration from URLs can be supplied in the GRANITIC_REMOTE_CONFIG_TOKEN
+environment variable. The token is only sent to the host named in GRANITIC_REMOTE_CONFIG_TOKEN_HOST (or, if that is not
+set, to the host of your configuration URLs if they are all on the same host).
 
 If your application needs to perform command line processing and you want to prevent Granitic from attempting to parse command line arguments,
 you should start Granitic using the alternative:
@@ -69,9 +80,12 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/gob"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
@@ -88,6 +102,8 @@ const (
 	Version                            = "2.2.2"
 	initiatorComponentName      string = instance.FrameworkPrefix + "Init"
 	systemPath                         = "System"
+	builtInConfigSource                = "granitic:built-in"
+	initialSettingsSource              = "granitic:initial-settings"
 	configAccessorComponentName string = instance.FrameworkPrefix + "Accessor"
 	instanceIDDecoratorName            = instance.FrameworkPrefix + "InstanceIDDecorator"
 )
@@ -136,6 +152,16 @@ func (i *initiator) Start(customComponents *ioc.ProtoComponents, is *config.Init
 		instance.ExitNormal()
 	}()
 
+	h := make(chan os.Signal, 1)
+	signal.Notify(h, syscall.SIGHUP)
+
+	go func() {
+		for range h {
+			// Outcomes are logged by the container
+			container.ReloadConfig()
+		}
+	}()
+
 	for {
 		time.Sleep(10 * time.Second)
 	}
@@ -157,7 +183,13 @@ func (i *initiator) buildContainer(ac *ioc.ProtoComponents, is *config.InitialSe
 
 	if is.MergedConfigPath != "" {
 
-		if err := config.WriteJSONConfig(ca, is.MergedConfigPath); err != nil {
+		write := config.WriteJSONConfig
+
+		if is.AnnotateMergedConfig {
+			write = config.WriteAnnotatedJSONConfig
+		}
+
+		if err := write(ca, is.MergedConfigPath); err != nil {
 
 			l.LogErrorf("Unable to write merged config file to %s: %s", is.MergedConfigPath, err.Error())
 			instance.ExitError()
@@ -187,6 +219,11 @@ func (i *initiator) buildContainer(ac *ioc.ProtoComponents, is *config.InitialSe
 	cc := ioc.NewComponentContainer(frameworkLoggingManager, ca, ss)
 	cc.AddProto(logManageProto)
 
+	//Allow configuration to be reloaded at runtime
+	cc.SetConfigLoader(func() (*config.Accessor, error) {
+		return i.mergeConfig(is, frameworkLoggingManager)
+	})
+
 	//Assign an identity to this instance of the application
 	i.createInstanceIdentifier(is, cc)
 
@@ -196,6 +233,7 @@ func (i *initiator) buildContainer(ac *ioc.ProtoComponents, is *config.InitialSe
 
 	//Instantiate those facilities required by user and register as components in container
 	fi := facility.NewFacilitiesInitialisor(cc, frameworkLoggingManager)
+	fi.ConfigSchemas = is.ConfigSchemas
 
 	err := fi.Initialise(ca)
 
@@ -206,10 +244,26 @@ func (i *initiator) buildContainer(ac *ioc.ProtoComponents, is *config.InitialSe
 
 	i.shutdownIfError(err, cc)
 
+	if is.ComponentGraphPath != "" {
+		cc.RecordWiring()
+	}
+
 	//Inject configuration and dependencies into all components
 	err = cc.Populate()
 	i.shutdownIfError(err, cc)
 
+	if is.ComponentGraphPath != "" {
+
+		if err := cc.Wiring().WriteFile(is.ComponentGraphPath); err != nil {
+
+			l.LogErrorf("Unable to write component graph to %s: %s", is.ComponentGraphPath, err.Error())
+			instance.ExitError()
+		} else {
+
+			instance.ExitNormal()
+		}
+	}
+
 	//Proto components no longer needed
 	if ss.FlushMergedConfig {
 		ca.Flush()
@@ -255,6 +309,12 @@ func (i *initiator) createInstanceIdentifier(is *config.InitialSettings, cc *ioc
 // during startup.
 func (i *initiator) shutdownIfError(err error, cc *ioc.ComponentContainer) {
 
+	if _, timedOut := err.(ioc.StartTimeoutError); timedOut {
+		// The component that timed out may still be starting, so other components are not stopped
+		i.logger.LogFatalf("%s. Exiting without stopping components", err.Error())
+		instance.ExitError()
+	}
+
 	if err != nil {
 		i.logger.LogFatalf(err.Error())
 		i.shutdown(cc)
@@ -275,13 +335,37 @@ func (i *initiator) shutdown(cc *ioc.ComponentContainer) {
 // which allows programmatic access to the merged config.
 func (i *initiator) createConfigAccessor(is *config.InitialSettings, flm *logging.ComponentLoggerManager) *config.Accessor {
 
+	if len(is.Profiles) > 0 {
+		i.logger.LogInfof("Configuration profiles: %s", strings.Join(is.Profiles, ", "))
+	}
+
+	for _, ignored := range is.IgnoredConfiguration {
+		i.logger.LogInfof("Not loading %s (profile not selected)", ignored)
+	}
+
+	i.logConfigLocations(is.Configuration)
+
+	ca, err := i.mergeConfig(is, flm)
+
+	if err != nil {
+		i.logger.LogFatalf(err.Error())
+		instance.ExitError()
+	}
+
+	return ca
+}
+
+// Merge Granitic's built-in configuration with the configuration files and URLs listed in the supplied settings. Used
+// both at startup and when configuration is reloaded.
+func (i *initiator) mergeConfig(is *config.InitialSettings, flm *logging.ComponentLoggerManager) (*config.Accessor, error) {
+
 	builtIn := map[string]interface{}{}
 
 	bz, err := base64.StdEncoding.DecodeString(*is.BuiltInConfig)
 
 	if err != nil {
-		i.logger.LogFatalf("Unable to deserialize the copy of Granitic's configuration created by grnc-bind. Re-run grnc-bind and re-build: %s", err.Error())
-		instance.ExitError()
+		message := fmt.Sprintf("Unable to deserialize the copy of Granitic's configuration created by grnc-bind. Re-run grnc-bind and re-build: %s", err.Error())
+		return nil, errors.New(message)
 	}
 
 	b := bytes.Buffer{}
@@ -295,15 +379,21 @@ func (i *initiator) createConfigAccessor(is *config.InitialSettings, flm *loggin
 	err = dc.Decode(&builtIn)
 
 	if err != nil {
-		i.logger.LogFatalf("Unable to deserialize the copy of Granitic's configuration created by grnc-bind. Re-run grnc-bind and re-build: %s", err.Error())
-		instance.ExitError()
+		message := fmt.Sprintf("Unable to deserialize the copy of Granitic's configuration created by grnc-bind. Re-run grnc-bind and re-build: %s", err.Error())
+		return nil, errors.New(message)
 	}
 
-	i.logConfigLocations(is.Configuration)
-
 	fl := flm.CreateLogger(configAccessorComponentName)
 
 	jm := config.NewJSONMergerWithManagedLogging(flm, new(config.JSONContentParser))
+	jm.BaseSource = builtInConfigSource
+	jm.Remote = is.RemoteConfig
+
+	if jm.Layers, err = facility.RegisteredConfigLayers(); err != nil {
+		return nil, err
+	}
+	jm.RegisterContentParser(new(config.YAMLContentParser))
+	jm.RegisterContentParser(new(config.TOMLContentParser))
 
 	for _, cp := range is.ConfigParsers {
 
@@ -314,22 +404,37 @@ func (i *initiator) createConfigAccessor(is *config.InitialSettings, flm *loggin
 	mergedJSON, err := jm.LoadAndMergeConfigWithBase(builtIn, is.Configuration)
 
 	if err != nil {
-		i.logger.LogFatalf(err.Error())
-		instance.ExitError()
+		return nil, err
 	}
 
-	// Add the command line supplied instance ID to the config object
-	if is.InstanceID != "" {
+	placeholders, err := config.ResolvePlaceholders(mergedJSON)
 
-		sys := mergedJSON["System"]
+	if err != nil {
+		return nil, err
+	}
+
+	sources := jm.Sources()
+
+	if sm, okay := mergedJSON["System"].(map[string]interface{}); okay {
 
-		if sm, okay := sys.(map[string]interface{}); okay {
+		// Add the command line supplied instance ID to the config object
+		if is.InstanceID != "" {
 			sm["InstanceID"] = is.InstanceID
+			sources[systemPath+".InstanceID"] = &config.Provenance{Source: initialSettingsSource}
+		}
+
+		// Make the active profiles available to components
+		profiles := make([]interface{}, len(is.Profiles))
+
+		for i, p := range is.Profiles {
+			profiles[i] = p
 		}
 
+		sm["Profiles"] = profiles
+		sources[systemPath+".Profiles"] = &config.Provenance{Source: initialSettingsSource}
 	}
 
-	return &config.Accessor{JSONData: mergedJSON, FrameworkLogger: fl}
+	return &config.Accessor{JSONData: mergedJSON, FrameworkLogger: fl, PlaceholderPaths: placeholders, Sources: sources}, nil
 }
 
 // Record the files and URLs used to create a merged configuration (in the order in which they will be merged)
@@ -0,0 +1,254 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+)
+
+// Endpoint answers liveness and readiness requests made to an HTTPServer. It implements httpendpoint.Provider so is
+// automatically registered with the HTTPServer facility.
+type Endpoint struct {
+	// Injected by Granitic.
+	FrameworkLogger logging.Logger
+
+	// The path on which liveness requests are answered.
+	LivePath string
+
+	// The path on which readiness requests are answered.
+	ReadyPath string
+
+	// The maximum time to wait for each component to report its health before it is considered DOWN.
+	Timeout time.Duration
+
+	container *ioc.ComponentContainer
+
+	mutex    sync.RWMutex
+	stopping bool
+}
+
+// Container accepts a reference to the IoC container so components can be checked.
+func (e *Endpoint) Container(container *ioc.ComponentContainer) {
+	e.container = container
+}
+
+// StartComponent checks that the endpoint's paths have been set.
+func (e *Endpoint) StartComponent() error {
+
+	if e.LivePath == "" || e.ReadyPath == "" {
+		return errors.New("the health check endpoint requires both LivePath and ReadyPath to be set")
+	}
+
+	if e.LivePath == e.ReadyPath {
+		return fmt.Errorf("the health check endpoint's LivePath and ReadyPath must be different (both are %s)", e.LivePath)
+	}
+
+	if e.Timeout <= 0 {
+		return fmt.Errorf("the health check endpoint's Timeout must be greater than zero (is %v)", e.Timeout)
+	}
+
+	return nil
+}
+
+// Live reports the liveness of the application, which is always UP while the application is able to respond.
+func (e *Endpoint) Live() *Report {
+	return &Report{Status: Up}
+}
+
+// Ready checks every component that implements HealthReporter or ioc.AccessibilityBlocker in parallel and reports
+// their health. The application is DOWN if any component is DOWN or if the application is stopping.
+func (e *Endpoint) Ready(ctx context.Context) *Report {
+
+	e.mutex.RLock()
+	stopping := e.stopping
+	e.mutex.RUnlock()
+
+	if stopping {
+		return &Report{Status: Down}
+	}
+
+	r := &Report{Status: Up, Components: make(map[string]*ComponentReport)}
+
+	var wg sync.WaitGroup
+	var m sync.Mutex
+
+	for _, c := range e.container.AllComponents() {
+
+		_, reporter := c.Instance.(HealthReporter)
+		_, blocker := c.Instance.(ioc.AccessibilityBlocker)
+
+		if !reporter && !blocker {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(c *ioc.Component) {
+			defer wg.Done()
+
+			cr := e.check(ctx, c.Instance)
+
+			m.Lock()
+			defer m.Unlock()
+
+			r.Components[c.Name] = cr
+
+			if cr.Status == Down {
+				r.Status = Down
+			}
+		}(c)
+	}
+
+	wg.Wait()
+
+	return r
+}
+
+// check runs a component's health checks, giving up if they take longer than the endpoint's Timeout
+func (e *Endpoint) check(ctx context.Context, instance interface{}) *ComponentReport {
+
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+
+	go func() {
+
+		defer func() {
+			if p := recover(); p != nil {
+				result <- fmt.Errorf("health check panicked: %v", p)
+			}
+		}()
+
+		result <- checkComponent(ctx, instance)
+	}()
+
+	var err error
+
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %v", e.Timeout)
+	}
+
+	cr := &ComponentReport{Status: Up, LatencyMS: math.Round(float64(time.Since(start))/float64(time.Microsecond)) / 1000}
+
+	if err != nil {
+		cr.Status = Down
+		cr.Error = err.Error()
+	}
+
+	return cr
+}
+
+func checkComponent(ctx context.Context, instance interface{}) error {
+
+	if hr, found := instance.(HealthReporter); found {
+
+		if err := hr.CheckHealth(ctx); err != nil {
+			return err
+		}
+	}
+
+	if ab, found := instance.(ioc.AccessibilityBlocker); found {
+
+		if block, err := ab.BlockAccess(); block {
+
+			if err != nil {
+				return err
+			}
+
+			return errors.New("blocking access (no reason given)")
+		}
+	}
+
+	return nil
+}
+
+// SupportedHTTPMethods implements httpendpoint.Provider. Only GET is supported.
+func (e *Endpoint) SupportedHTTPMethods() []string {
+	return []string{http.MethodGet}
+}
+
+// RegexPattern implements httpendpoint.Provider, matching the LivePath and ReadyPath exactly. The pattern is built
+// from the paths each time it is called, as the HTTPServer may read it before this component has been started.
+func (e *Endpoint) RegexPattern() string {
+	return fmt.Sprintf("^(%s|%s)$", regexp.QuoteMeta(e.LivePath), regexp.QuoteMeta(e.ReadyPath))
+}
+
+// ServeHTTP implements httpendpoint.Provider, writing a Report as JSON. The HTTP status is 200 if the Report's status
+// is UP and 503 otherwise.
+func (e *Endpoint) ServeHTTP(ctx context.Context, w *httpendpoint.HTTPResponseWriter, req *http.Request) context.Context {
+
+	var r *Report
+
+	if req.URL.Path == e.LivePath {
+		r = e.Live()
+	} else {
+		r = e.Ready(ctx)
+	}
+
+	status := http.StatusOK
+
+	if r.Status != Up {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(r); err != nil {
+		e.FrameworkLogger.LogErrorf("Unable to write health report: %s", err.Error())
+	}
+
+	return ctx
+}
+
+// VersionAware implements httpendpoint.Provider. Returns false.
+func (e *Endpoint) VersionAware() bool {
+	return false
+}
+
+// SupportsVersion implements httpendpoint.Provider. Returns true.
+func (e *Endpoint) SupportsVersion(version httpendpoint.RequiredVersion) bool {
+	return true
+}
+
+// AutoWireable implements httpendpoint.Provider. Returns true.
+func (e *Endpoint) AutoWireable() bool {
+	return true
+}
+
+// PrepareToStop causes readiness requests to report DOWN so that load balancers stop sending requests to the application
+// while it shuts down.
+func (e *Endpoint) PrepareToStop() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.stopping = true
+}
+
+// ReadyToStop always returns true
+func (e *Endpoint) ReadyToStop() (bool, error) {
+	return true, nil
+}
+
+// Stop does nothing
+func (e *Endpoint) Stop() error {
+	return nil
+}
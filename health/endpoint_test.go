@@ -0,0 +1,159 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/ioc"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type reporter struct {
+	err   error
+	delay time.Duration
+}
+
+func (r *reporter) CheckHealth(ctx context.Context) error {
+
+	select {
+	case <-time.After(r.delay):
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type blocker struct {
+	block bool
+	err   error
+}
+
+func (b *blocker) BlockAccess() (bool, error) {
+	return b.block, b.err
+}
+
+type reportingBlocker struct {
+	reporter
+	blocker
+}
+
+func endpoint(t *testing.T, components map[string]interface{}) *Endpoint {
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	cc := ioc.NewComponentContainer(lm, &config.Accessor{JSONData: map[string]interface{}{}}, new(instance.System))
+
+	e := &Endpoint{LivePath: "/health/live", ReadyPath: "/health/ready", Timeout: 50 * time.Millisecond, FrameworkLogger: new(logging.NullLogger)}
+
+	cc.WrapAndAddProto("healthEndpoint", e)
+
+	for n, c := range components {
+		cc.WrapAndAddProto(n, c)
+	}
+
+	test.ExpectNil(t, cc.Populate())
+	test.ExpectNil(t, e.StartComponent())
+
+	return e
+}
+
+func serve(e *Endpoint, path string) (int, *Report) {
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+
+	e.ServeHTTP(context.Background(), httpendpoint.NewHTTPResponseWriter(rec), req)
+
+	r := new(Report)
+	json.Unmarshal(rec.Body.Bytes(), r)
+
+	return rec.Code, r
+}
+
+func TestReadyWhenAllComponentsHealthy(t *testing.T) {
+
+	e := endpoint(t, map[string]interface{}{
+		"db":      &reporter{},
+		"cache":   &blocker{},
+		"unknown": &struct{}{},
+	})
+
+	status, r := serve(e, "/health/ready")
+
+	test.ExpectInt(t, status, http.StatusOK)
+	test.ExpectString(t, string(r.Status), string(Up))
+	test.ExpectInt(t, len(r.Components), 2)
+	test.ExpectString(t, string(r.Components["db"].Status), string(Up))
+	test.ExpectString(t, string(r.Components["cache"].Status), string(Up))
+}
+
+func TestNotReadyWhenComponentUnhealthy(t *testing.T) {
+
+	e := endpoint(t, map[string]interface{}{
+		"db":     &reporter{err: errors.New("connection refused")},
+		"slow":   &reporter{delay: time.Second},
+		"queue":  &blocker{block: true},
+		"client": &reportingBlocker{blocker: blocker{block: true, err: errors.New("no token")}},
+		"ok":     &reporter{},
+	})
+
+	status, r := serve(e, "/health/ready")
+
+	test.ExpectInt(t, status, http.StatusServiceUnavailable)
+	test.ExpectString(t, string(r.Status), string(Down))
+
+	test.ExpectString(t, r.Components["db"].Error, "connection refused")
+	test.ExpectString(t, r.Components["slow"].Error, "timed out after 50ms")
+	test.ExpectString(t, r.Components["queue"].Error, "blocking access (no reason given)")
+	test.ExpectString(t, r.Components["client"].Error, "no token")
+	test.ExpectString(t, string(r.Components["ok"].Status), string(Up))
+
+	if r.Components["slow"].LatencyMS < 50 {
+		t.Errorf("Expected latency of slow component to be at least 50ms, got %v", r.Components["slow"].LatencyMS)
+	}
+}
+
+func TestLiveAndStopping(t *testing.T) {
+
+	e := endpoint(t, map[string]interface{}{"db": &reporter{err: errors.New("down")}})
+
+	status, r := serve(e, "/health/live")
+
+	test.ExpectInt(t, status, http.StatusOK)
+	test.ExpectString(t, string(r.Status), string(Up))
+
+	e.PrepareToStop()
+
+	status, r = serve(e, "/health/ready")
+
+	test.ExpectInt(t, status, http.StatusServiceUnavailable)
+	test.ExpectInt(t, len(r.Components), 0)
+}
+
+func TestPattern(t *testing.T) {
+
+	e := endpoint(t, nil)
+
+	p := regexp.MustCompile(e.RegexPattern())
+
+	test.ExpectBool(t, p.MatchString("/health/live"), true)
+	test.ExpectBool(t, p.MatchString("/health/ready"), true)
+	test.ExpectBool(t, p.MatchString("/health/readyx"), false)
+	test.ExpectBool(t, p.MatchString("/health"), false)
+
+	e.ReadyPath = e.LivePath
+	test.ExpectNotNil(t, e.StartComponent())
+}
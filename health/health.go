@@ -0,0 +1,75 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+/*
+Package health provides types that allow components to report whether they are healthy and an HTTP endpoint that reports
+the liveness and readiness of an application.
+
+The endpoint is created by the HealthCheck facility (see https://granitic.io/ref/health-check) and answers two types of request:
+
+	Liveness (default path /health/live): always reports that the application is UP while it is able to serve requests.
+	Readiness (default path /health/ready): reports the status of every component that implements HealthReporter or
+	ioc.AccessibilityBlocker. The application is only ready if all of those components are UP.
+
+Responses are JSON, for example:
+
+	{
+	  "Status": "DOWN",
+	  "Components": {
+	    "orderDatabase": {
+	      "Status": "DOWN",
+	      "LatencyMS": 2000.4,
+	      "Error": "timed out after 2s"
+	    },
+	    "stockClient": {
+	      "Status": "UP",
+	      "LatencyMS": 1.3
+	    }
+	  }
+	}
+
+Readiness responses have the HTTP status 200 if the application is ready and 503 otherwise.
+*/
+package health
+
+import (
+	"context"
+)
+
+// Status is the health of an application or a component.
+type Status string
+
+// The possible values of Status
+const (
+	Up   Status = "UP"
+	Down Status = "DOWN"
+)
+
+// HealthReporter is implemented by components that are able to check whether they are able to do their job (for example
+// by checking that a connection to an external system is working).
+type HealthReporter interface {
+	// CheckHealth returns nil if the component is healthy or an error explaining why it is not. Implementations should
+	// return promptly if the supplied context is cancelled.
+	CheckHealth(ctx context.Context) error
+}
+
+// Report is the overall health of an application and the health of each component that was checked.
+type Report struct {
+	// UP if every component checked is UP.
+	Status Status
+
+	// The health of each checked component, keyed by component name.
+	Components map[string]*ComponentReport `json:",omitempty"`
+}
+
+// ComponentReport is the health of a single component.
+type ComponentReport struct {
+	// Whether or not the component is healthy.
+	Status Status
+
+	// How long it took to check the component, in milliseconds.
+	LatencyMS float64
+
+	// If the component is not healthy, the reason given by the component.
+	Error string `json:",omitempty"`
+}
@@ -0,0 +1,176 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpendpoint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CORSAware is implemented by a Provider that may have its own Cross-Origin Resource Sharing (CORS) policy, overriding
+// the policy configured on the HTTP server.
+type CORSAware interface {
+	// CORSPolicy returns the policy that should be applied to requests handled by this endpoint, or nil if the HTTP
+	// server's policy should be used.
+	CORSPolicy() *CORSPolicy
+}
+
+// CORSPolicy describes which cross-origin requests browsers should allow to an endpoint. A policy with no AllowedOrigins
+// allows no cross-origin requests.
+type CORSPolicy struct {
+	// The origins (e.g. https://www.example.com) from which requests are allowed. An origin of * allows any origin, an
+	// origin containing * (e.g. https://*.example.com) allows any host name in place of the *, and an origin starting
+	// with ^ is treated as a regular expression.
+	AllowedOrigins []string
+
+	// The methods that may be used in cross-origin requests. If empty, any method supported by the endpoint is allowed.
+	AllowedMethods []string
+
+	// The request headers that may be used in cross-origin requests, in addition to those browsers always allow. A
+	// header of * allows any header.
+	AllowedHeaders []string
+
+	// The response headers that browsers should make available to scripts, in addition to those they always make available.
+	ExposedHeaders []string
+
+	// Whether or not browsers should include credentials (cookies, HTTP authentication and client certificates) in
+	// cross-origin requests.
+	AllowCredentials bool
+
+	// How long browsers may cache the result of a preflight request. Zero means browsers use their own default.
+	MaxAge time.Duration
+
+	anyOrigin   bool
+	origins     []*regexp.Regexp
+	anyHeader   bool
+	headers     map[string]bool
+	methods     map[string]bool
+	compileOnce sync.Once
+	compileErr  error
+}
+
+// Compile checks that the policy is valid and prepares it for matching requests. It is called automatically the first
+// time the policy is used, but can be called earlier to detect invalid policies.
+func (cp *CORSPolicy) Compile() error {
+
+	cp.compileOnce.Do(func() {
+		cp.compileErr = cp.compile()
+	})
+
+	return cp.compileErr
+}
+
+func (cp *CORSPolicy) compile() error {
+
+	for _, o := range cp.AllowedOrigins {
+
+		var pattern string
+
+		switch {
+		case o == "*":
+			cp.anyOrigin = true
+			continue
+		case strings.HasPrefix(o, "^"):
+			pattern = o
+		default:
+			pattern = "^" + strings.Replace(regexp.QuoteMeta(o), `\*`, `[a-zA-Z0-9.-]+`, -1) + "$"
+		}
+
+		r, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return fmt.Errorf("allowed origin %s is not a valid regular expression: %s", o, err.Error())
+		}
+
+		cp.origins = append(cp.origins, r)
+	}
+
+	cp.headers = make(map[string]bool)
+
+	for _, h := range cp.AllowedHeaders {
+
+		if h == "*" {
+			cp.anyHeader = true
+		}
+
+		cp.headers[strings.ToLower(h)] = true
+	}
+
+	cp.methods = make(map[string]bool)
+
+	for _, m := range cp.AllowedMethods {
+		cp.methods[strings.ToUpper(m)] = true
+	}
+
+	return nil
+}
+
+// Active returns true if the policy allows cross-origin requests from at least one origin.
+func (cp *CORSPolicy) Active() bool {
+	return cp != nil && len(cp.AllowedOrigins) > 0
+}
+
+// AllowsOrigin returns true if the supplied value of a request's Origin header is allowed by this policy.
+func (cp *CORSPolicy) AllowsOrigin(origin string) bool {
+
+	if origin == "" || cp.Compile() != nil {
+		return false
+	}
+
+	if cp.anyOrigin {
+		return true
+	}
+
+	for _, r := range cp.origins {
+		if r.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsMethod returns true if the supplied HTTP method may be used in a cross-origin request.
+func (cp *CORSPolicy) AllowsMethod(method string) bool {
+
+	if cp.Compile() != nil {
+		return false
+	}
+
+	return len(cp.methods) == 0 || cp.methods[strings.ToUpper(method)]
+}
+
+// AllowsHeaders returns true if all of the supplied request headers may be used in a cross-origin request.
+func (cp *CORSPolicy) AllowsHeaders(headers []string) bool {
+
+	if cp.Compile() != nil {
+		return false
+	}
+
+	if cp.anyHeader {
+		return true
+	}
+
+	for _, h := range headers {
+		if !cp.headers[strings.ToLower(h)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AllowOriginValue returns the value that should be sent in the Access-Control-Allow-Origin header of a response to a
+// request from the supplied (allowed) origin.
+func (cp *CORSPolicy) AllowOriginValue(origin string) string {
+
+	if cp.anyOrigin && !cp.AllowCredentials {
+		return "*"
+	}
+
+	return origin
+}
@@ -0,0 +1,58 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpendpoint
+
+import (
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestCORSOriginMatching(t *testing.T) {
+
+	cp := &CORSPolicy{AllowedOrigins: []string{"https://www.example.com", "https://*.example.org", `^https?://localhost(:[0-9]+)?$`}}
+
+	test.ExpectNil(t, cp.Compile())
+	test.ExpectBool(t, cp.Active(), true)
+
+	test.ExpectBool(t, cp.AllowsOrigin("https://www.example.com"), true)
+	test.ExpectBool(t, cp.AllowsOrigin("http://www.example.com"), false)
+	test.ExpectBool(t, cp.AllowsOrigin("https://wwwXexample.com"), false)
+	test.ExpectBool(t, cp.AllowsOrigin("https://api.example.org"), true)
+	test.ExpectBool(t, cp.AllowsOrigin("https://a.b.example.org"), true)
+	test.ExpectBool(t, cp.AllowsOrigin("https://example.org"), false)
+	test.ExpectBool(t, cp.AllowsOrigin("https://evil.com/.example.org"), false)
+	test.ExpectBool(t, cp.AllowsOrigin("http://localhost:8080"), true)
+	test.ExpectBool(t, cp.AllowsOrigin(""), false)
+
+	test.ExpectString(t, cp.AllowOriginValue("https://www.example.com"), "https://www.example.com")
+
+	anyOrigin := &CORSPolicy{AllowedOrigins: []string{"*"}}
+	test.ExpectBool(t, anyOrigin.AllowsOrigin("https://anywhere.com"), true)
+	test.ExpectString(t, anyOrigin.AllowOriginValue("https://anywhere.com"), "*")
+
+	anyOrigin = &CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+	test.ExpectString(t, anyOrigin.AllowOriginValue("https://anywhere.com"), "https://anywhere.com")
+
+	var none *CORSPolicy
+	test.ExpectBool(t, none.Active(), false)
+
+	test.ExpectNotNil(t, (&CORSPolicy{AllowedOrigins: []string{"^https://(["}}).Compile())
+}
+
+func TestCORSMethodsAndHeaders(t *testing.T) {
+
+	cp := &CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"get", "POST"}, AllowedHeaders: []string{"Content-Type"}}
+
+	test.ExpectBool(t, cp.AllowsMethod("GET"), true)
+	test.ExpectBool(t, cp.AllowsMethod("DELETE"), false)
+	test.ExpectBool(t, cp.AllowsHeaders([]string{"content-type"}), true)
+	test.ExpectBool(t, cp.AllowsHeaders([]string{"content-type", "X-Other"}), false)
+	test.ExpectBool(t, cp.AllowsHeaders(nil), true)
+
+	cp = &CORSPolicy{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}}
+
+	test.ExpectBool(t, cp.AllowsMethod("DELETE"), true)
+	test.ExpectBool(t, cp.AllowsHeaders([]string{"X-Anything"}), true)
+}
@@ -3,7 +3,12 @@
 
 package httpendpoint
 
-import "net/http"
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
 
 // HTTPResponseWriter is a wrapper over http.ResponseWriter that provides Granitic with better visibility on the state of response writing.
 type HTTPResponseWriter struct {
@@ -16,6 +21,11 @@ type HTTPResponseWriter struct {
 
 	// How many bytes have been sent to the response so far (excluding headers).
 	BytesServed int
+
+	bodySuppressed bool
+
+	// The http.ResponseWriter originally wrapped, if rw has been replaced by WrapWriter
+	original http.ResponseWriter
 }
 
 // Header calls through to http.ResponseWriter.Header()
@@ -26,7 +36,15 @@ func (w *HTTPResponseWriter) Header() http.Header {
 // Write calls through to http.ResponseWriter.Write while keeping track of the number of bytes sent.
 func (w *HTTPResponseWriter) Write(b []byte) (int, error) {
 
-	w.BytesServed += len(b)
+	if w.bodySuppressed {
+		w.DataSent = true
+		return len(b), nil
+	}
+
+	if w.original == nil {
+		w.BytesServed += len(b)
+	}
+
 	w.DataSent = true
 
 	return w.rw.Write(b)
@@ -45,6 +63,62 @@ func (w *HTTPResponseWriter) WriteHeader(i int) {
 	w.DataSent = true
 }
 
+// SuppressBody causes any subsequent calls to Write to be discarded (while reporting success), so that a response to a
+// HEAD request can be generated by a handler for GET requests. Headers and the status code are still sent.
+func (w *HTTPResponseWriter) SuppressBody() {
+	w.bodySuppressed = true
+}
+
+// WrapWriter replaces the http.ResponseWriter that headers and data are written to with one created by the supplied
+// function, which is passed the current writer. This allows the body of a response to be transformed (for example
+// compressed) before it is sent. BytesServed continues to count the bytes actually sent, rather than the bytes passed
+// to Write.
+func (w *HTTPResponseWriter) WrapWriter(wrap func(http.ResponseWriter) http.ResponseWriter) {
+
+	if w.original == nil {
+		w.original = w.rw
+		w.rw = wrap(&servedCounter{ResponseWriter: w.rw, w: w})
+	} else {
+		w.rw = wrap(w.rw)
+	}
+}
+
+// servedCounter counts the bytes sent to an http.ResponseWriter that has been wrapped by HTTPResponseWriter.WrapWriter
+type servedCounter struct {
+	http.ResponseWriter
+	w *HTTPResponseWriter
+}
+
+func (sc *servedCounter) Write(b []byte) (int, error) {
+
+	n, err := sc.ResponseWriter.Write(b)
+	sc.w.BytesServed += n
+
+	return n, err
+}
+
+// Hijack allows a handler to take over the underlying connection (for example to upgrade it to a WebSocket), if the
+// underlying http.ResponseWriter supports it. Hijacked connections are closed by the HTTPServer facility when it has
+// finished draining during shutdown.
+func (w *HTTPResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+
+	rw := w.rw
+
+	if w.original != nil {
+		rw = w.original
+	}
+
+	h, found := rw.(http.Hijacker)
+
+	if !found {
+		return nil, nil, errors.New("the underlying http.ResponseWriter does not support hijacking")
+	}
+
+	w.DataSent = true
+
+	return h.Hijack()
+}
+
 // NewHTTPResponseWriter creates a new HTTPResponseWriter wrapping the supplied http.ResponseWriter
 func NewHTTPResponseWriter(rw http.ResponseWriter) *HTTPResponseWriter {
 	w := new(HTTPResponseWriter)
@@ -29,6 +29,64 @@ func TestWritethrough(t *testing.T) {
 
 }
 
+func TestSuppressBody(t *testing.T) {
+	rw := new(HTTPResponseWriter)
+
+	rw.rw = new(resWriter)
+
+	rw.SuppressBody()
+	rw.WriteHeader(200)
+
+	if n, err := rw.Write([]byte{'a'}); n != 1 || err != nil {
+		t.FailNow()
+	}
+
+	if rw.BytesServed != 0 || rw.rw.(*resWriter).sw.Len() != 0 {
+		t.FailNow()
+	}
+
+}
+
+func TestWrapWriter(t *testing.T) {
+	rw := new(HTTPResponseWriter)
+
+	underlying := new(resWriter)
+	rw.rw = underlying
+
+	// Wrap with a writer that doubles every byte written
+	rw.WrapWriter(func(w http.ResponseWriter) http.ResponseWriter {
+		return &doublingWriter{w}
+	})
+
+	rw.WriteHeader(200)
+	rw.Write([]byte{'a', 'b'})
+
+	if underlying.sw.String() != "aabb" {
+		t.FailNow()
+	}
+
+	if rw.BytesServed != 4 {
+		t.FailNow()
+	}
+
+}
+
+type doublingWriter struct {
+	http.ResponseWriter
+}
+
+func (dw *doublingWriter) Write(b []byte) (int, error) {
+	doubled := make([]byte, 0, len(b)*2)
+
+	for _, c := range b {
+		doubled = append(doubled, c, c)
+	}
+
+	dw.ResponseWriter.Write(doubled)
+
+	return len(b), nil
+}
+
 type resWriter struct {
 	sw bytes.Buffer
 }
@@ -0,0 +1,195 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpendpoint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplatedProvider is implemented by a Provider that can be matched against a request's path using a path template
+// (e.g. /artist/{id:int}/album/{albumID}) rather than a regular expression.
+type TemplatedProvider interface {
+	// TemplatePattern returns the path template used to match requests to this endpoint, or an empty string if the
+	// endpoint should be matched using its RegexPattern.
+	TemplatePattern() string
+}
+
+// ParamType is the type of value a parameter in a path template will match.
+type ParamType string
+
+// Supported types of path template parameters
+const (
+	// StringParam matches any non-empty path segment. This is the default if no type is specified.
+	StringParam ParamType = "string"
+
+	// IntParam matches a path segment that can be parsed as a signed integer.
+	IntParam ParamType = "int"
+
+	// UintParam matches a path segment that can be parsed as an unsigned integer.
+	UintParam ParamType = "uint"
+
+	// FloatParam matches a path segment that can be parsed as a floating point number.
+	FloatParam ParamType = "float"
+)
+
+// Accepts returns true if the supplied path segment is a valid value for this type of parameter.
+func (pt ParamType) Accepts(segment string) bool {
+
+	var err error
+
+	switch pt {
+	case IntParam:
+		_, err = strconv.ParseInt(segment, 10, 64)
+	case UintParam:
+		_, err = strconv.ParseUint(segment, 10, 64)
+	case FloatParam:
+		_, err = strconv.ParseFloat(segment, 64)
+	}
+
+	return err == nil && segment != ""
+}
+
+var paramTypes = map[ParamType]bool{StringParam: true, IntParam: true, UintParam: true, FloatParam: true}
+
+var paramName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// TemplateSegment is one segment of a path template: either a literal that must match a request's path segment exactly,
+// or a named parameter.
+type TemplateSegment struct {
+	// The text the path segment must match, if this is not a parameter.
+	Literal string
+
+	// The name of the parameter, or an empty string if this segment is a literal.
+	Param string
+
+	// The type of value the parameter matches.
+	Type ParamType
+}
+
+// IsParam returns true if this segment is a parameter.
+func (ts TemplateSegment) IsParam() bool {
+	return ts.Param != ""
+}
+
+// PathTemplate is a parsed path template like /artist/{id:int}/album/{albumID}. Each segment of the template is either
+// literal text or a parameter in braces, consisting of a name and an optional type (string, int, uint or float). Trailing
+// slashes are ignored when matching.
+type PathTemplate struct {
+	raw      string
+	Segments []TemplateSegment
+}
+
+// String returns the template as originally supplied.
+func (pt *PathTemplate) String() string {
+	return pt.raw
+}
+
+// ParamNames returns the names of the template's parameters in the order they appear.
+func (pt *PathTemplate) ParamNames() []string {
+
+	var names []string
+
+	for _, s := range pt.Segments {
+		if s.IsParam() {
+			names = append(names, s.Param)
+		}
+	}
+
+	return names
+}
+
+// Match checks whether the supplied path matches the template. If so, it returns the values of the template's
+// parameters in the order they appear in the template.
+func (pt *PathTemplate) Match(path string) ([]string, bool) {
+
+	segments := SplitPath(path)
+
+	if len(segments) != len(pt.Segments) {
+		return nil, false
+	}
+
+	var values []string
+
+	for i, ts := range pt.Segments {
+
+		s := segments[i]
+
+		if ts.IsParam() {
+
+			if !ts.Type.Accepts(s) {
+				return nil, false
+			}
+
+			values = append(values, s)
+
+		} else if s != ts.Literal {
+			return nil, false
+		}
+	}
+
+	return values, true
+}
+
+// ParsePathTemplate parses a path template like /artist/{id:int}/album/{albumID}, returning an error if the template
+// is not valid.
+func ParsePathTemplate(template string) (*PathTemplate, error) {
+
+	if !strings.HasPrefix(template, "/") {
+		return nil, fmt.Errorf("path template %s must start with /", template)
+	}
+
+	pt := &PathTemplate{raw: template}
+	seen := make(map[string]bool)
+
+	for _, s := range SplitPath(template) {
+
+		if !strings.ContainsAny(s, "{}") {
+			pt.Segments = append(pt.Segments, TemplateSegment{Literal: s})
+			continue
+		}
+
+		if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+			return nil, fmt.Errorf("path template %s is invalid: parameters must make up a whole segment of the path (found %s)", template, s)
+		}
+
+		name, typ := s[1:len(s)-1], StringParam
+
+		if i := strings.Index(name, ":"); i >= 0 {
+			name, typ = name[:i], ParamType(name[i+1:])
+		}
+
+		if !paramName.MatchString(name) {
+			return nil, fmt.Errorf("path template %s is invalid: %q is not a valid parameter name", template, name)
+		}
+
+		if !paramTypes[typ] {
+			return nil, fmt.Errorf("path template %s is invalid: parameter %s has unsupported type %q (should be one of string, int, uint, float)", template, name, typ)
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("path template %s is invalid: parameter %s appears more than once", template, name)
+		}
+
+		seen[name] = true
+
+		pt.Segments = append(pt.Segments, TemplateSegment{Param: name, Type: typ})
+	}
+
+	return pt, nil
+}
+
+// SplitPath splits a request path into segments, ignoring leading and trailing slashes. The root path (/) has no segments.
+func SplitPath(path string) []string {
+
+	path = strings.Trim(path, "/")
+
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
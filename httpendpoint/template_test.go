@@ -0,0 +1,63 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package httpendpoint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+func TestParsePathTemplate(t *testing.T) {
+
+	pt, err := ParsePathTemplate("/artist/{id:int}/album/{albumID}/")
+	test.ExpectNil(t, err)
+
+	test.ExpectInt(t, len(pt.Segments), 4)
+	test.ExpectString(t, pt.Segments[0].Literal, "artist")
+	test.ExpectString(t, pt.Segments[1].Param, "id")
+	test.ExpectString(t, string(pt.Segments[1].Type), "int")
+	test.ExpectString(t, string(pt.Segments[3].Type), "string")
+	test.ExpectString(t, strings.Join(pt.ParamNames(), ","), "id,albumID")
+
+	values, matched := pt.Match("/artist/12/album/abc")
+	test.ExpectBool(t, matched, true)
+	test.ExpectString(t, strings.Join(values, ","), "12,abc")
+
+	_, matched = pt.Match("/artist/12/album/abc/")
+	test.ExpectBool(t, matched, true)
+
+	_, matched = pt.Match("/artist/x/album/abc")
+	test.ExpectBool(t, matched, false)
+
+	_, matched = pt.Match("/artist/12/album")
+	test.ExpectBool(t, matched, false)
+
+	root, err := ParsePathTemplate("/")
+	test.ExpectNil(t, err)
+
+	_, matched = root.Match("/")
+	test.ExpectBool(t, matched, true)
+}
+
+func TestInvalidPathTemplates(t *testing.T) {
+
+	for _, tmpl := range []string{"artist", "/artist/id{id}", "/artist/{1d}", "/artist/{id:date}", "/a/{id}/b/{id}", "/a/{id"} {
+
+		if _, err := ParsePathTemplate(tmpl); err == nil {
+			t.Errorf("Expected %s to be rejected", tmpl)
+		}
+	}
+}
+
+func TestParamTypes(t *testing.T) {
+
+	test.ExpectBool(t, IntParam.Accepts("-12"), true)
+	test.ExpectBool(t, IntParam.Accepts("1.5"), false)
+	test.ExpectBool(t, UintParam.Accepts("-12"), false)
+	test.ExpectBool(t, FloatParam.Accepts("1.5"), true)
+	test.ExpectBool(t, StringParam.Accepts("anything"), true)
+	test.ExpectBool(t, StringParam.Accepts(""), false)
+}
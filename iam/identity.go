@@ -19,9 +19,12 @@ See also
 */
 package iam
 
+import "crypto/x509/pkix"
+
 const authenticated = "Authenticated"
 const anonymous = "Anonymous"
 const loggableUserID = "LoggableUserID"
+const certificateSubject = "CertificateSubject"
 
 // NewAuthenticatedIdentity creates a new ClientIdentity with the supplied log-friendly version of a user ID. The ClientIdentity will be marked
 // as Authenticated and not anonymous
@@ -34,6 +37,16 @@ func NewAuthenticatedIdentity(loggableUserID string) ClientIdentity {
 	return i
 }
 
+// NewCertificateIdentity creates a new ClientIdentity for a caller that presented a verified client certificate with the
+// supplied subject (see ws.VerifiedClientCertificate). The ClientIdentity will be marked as Authenticated and not anonymous,
+// and the subject's common name will be used as the loggable user ID.
+func NewCertificateIdentity(subject pkix.Name) ClientIdentity {
+	i := NewAuthenticatedIdentity(subject.CommonName)
+	i.SetCertificateSubject(subject)
+
+	return i
+}
+
 // NewAnonymousIdentity creates a new ClientIdentity for an anonymous user. The ClientIdentity will be marked as non-authenticated,
 // anonymous and have a dash (-) as the loggable user ID.
 func NewAnonymousIdentity() ClientIdentity {
@@ -92,3 +105,17 @@ func (ci ClientIdentity) LoggableUserID() string {
 
 	return a.(string)
 }
+
+// SetCertificateSubject records the subject of the verified client certificate used to identify the caller.
+func (ci ClientIdentity) SetCertificateSubject(subject pkix.Name) {
+	ci[certificateSubject] = subject
+}
+
+// CertificateSubject returns the subject of the verified client certificate used to identify the caller and true, or
+// an empty subject and false if the caller was not identified by a client certificate.
+func (ci ClientIdentity) CertificateSubject() (pkix.Name, bool) {
+
+	s, found := ci[certificateSubject].(pkix.Name)
+
+	return s, found
+}
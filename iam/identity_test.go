@@ -1,6 +1,9 @@
 package iam
 
-import "testing"
+import (
+	"crypto/x509/pkix"
+	"testing"
+)
 
 func TestNewAuthenticatedIdentity(t *testing.T) {
 
@@ -11,3 +14,22 @@ func TestNewAuthenticatedIdentity(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestNewCertificateIdentity(t *testing.T) {
+
+	a := NewCertificateIdentity(pkix.Name{CommonName: "billing-service", Organization: []string{"Example"}})
+
+	if !a.Authenticated() || a.LoggableUserID() != "billing-service" {
+		t.Errorf("Expected an authenticated identity for billing-service")
+	}
+
+	s, found := a.CertificateSubject()
+
+	if !found || s.Organization[0] != "Example" {
+		t.Errorf("Expected the certificate subject to be recorded")
+	}
+
+	if _, found := NewAuthenticatedIdentity("id").CertificateSubject(); found {
+		t.Errorf("Did not expect a certificate subject")
+	}
+}
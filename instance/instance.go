@@ -58,6 +58,12 @@ type System struct {
 	//If a garbage collection should be invoked after the container has called StartComponent on all components (but before AllowAccess).
 	GCAfterStart bool
 
+	//If components that do not depend on each other should have their StartComponent methods called concurrently.
+	ParallelStart bool
+
+	//The maximum time (in milliseconds) an individual component's StartComponent method may take before startup fails. Zero means no limit.
+	StartTimeoutMS time.Duration
+
 	//The interval (in milliseconds) between checks of stoppable components to see if they are ready to be stopped.
 	StopIntervalMS time.Duration
 
@@ -66,6 +72,9 @@ type System struct {
 
 	//How many times a stoppable component can declare it is not ready to stop before a warning message is logged
 	StopTriesBeforeWarn int
+
+	//The configuration profiles selected with the -p command line argument or the GRANITIC_PROFILE environment variable, in the order they were applied.
+	Profiles []string
 }
 
 // IDComponent is the name of the component in the IoC container holding an instance ID.
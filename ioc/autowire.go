@@ -0,0 +1,138 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/graniticio/granitic/v2/instance"
+)
+
+// autowireByType finds nil, interface-typed fields on proto-components that have requested autowiring and records a
+// dependency on the single application singleton that implements the field's interface. An error listing every ambiguous
+// field is returned if more than one component implements a field's interface.
+func (cc *ComponentContainer) autowireByType() error {
+
+	problems := make([]string, 0)
+
+	names := make([]string, 0, len(cc.protoComponents))
+
+	for name := range cc.protoComponents {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		proto := cc.protoComponents[name]
+
+		if !proto.AutowireByType {
+			continue
+		}
+
+		existing := cc.mergeDependencies(name, proto.Dependencies)
+
+		fields, err := autowireCandidateFields(proto.Component.Instance)
+
+		if err != nil {
+			return fmt.Errorf("Unable to autowire %s by type: %s", name, err.Error())
+		}
+
+		for _, field := range fields {
+
+			if existing[field.Name] != "" {
+				continue
+			}
+
+			matches := cc.implementors(field.Type, name)
+
+			switch len(matches) {
+			case 0:
+				cc.FrameworkLogger.LogTracef("No component implements %s (%s.%s)", field.Type, name, field.Name)
+			case 1:
+				cc.FrameworkLogger.LogDebugf("Autowiring %s into %s.%s", matches[0], name, field.Name)
+				proto.AddDependency(field.Name, matches[0])
+			default:
+				problems = append(problems, fmt.Sprintf("%s.%s (%s) could be satisfied by any of %s", name, field.Name, field.Type, strings.Join(matches, ", ")))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		message := fmt.Sprintf("Unable to autowire by type as more than one component implements the required interface. "+
+			"Use an explicit ref: for these fields: %s", strings.Join(problems, "; "))
+		return errors.New(message)
+	}
+
+	return nil
+}
+
+// implementors returns the sorted names of all proto-components (other than the excluded component) whose instances
+// implement the supplied interface type. Only singleton application components are considered - framework components
+// (those whose names start with instance.FrameworkPrefix), decorators and prototype or request scoped components are not.
+func (cc *ComponentContainer) implementors(it reflect.Type, exclude string) []string {
+
+	var tm TypeMatcher = func(i interface{}) bool {
+		return reflect.TypeOf(i).Implements(it)
+	}
+
+	names := make([]string, 0)
+
+	for _, pc := range cc.ProtoComponentsByType(tm) {
+
+		name := pc.Component.Name
+
+		if name == exclude || strings.HasPrefix(name, instance.FrameworkPrefix) || pc.Scope != SingletonScope {
+			continue
+		}
+
+		if _, decorator := pc.Component.Instance.(ComponentDecorator); decorator {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// autowireCandidateFields returns the exported fields on the supplied pointer to a struct that are non-empty interfaces
+// and currently nil. An error is returned if the component is not a pointer to a struct.
+func autowireCandidateFields(component interface{}) ([]reflect.StructField, error) {
+
+	pv := reflect.ValueOf(component)
+
+	if pv.Kind() != reflect.Ptr || pv.IsNil() || pv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%T is not a pointer to a struct", component)
+	}
+
+	v := pv.Elem()
+	t := v.Type()
+
+	fields := make([]reflect.StructField, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+
+		f := t.Field(i)
+
+		if f.PkgPath != "" || f.Type.Kind() != reflect.Interface || f.Type.NumMethod() == 0 || f.Type == scopedProviderType {
+			continue
+		}
+
+		if !v.Field(i).IsNil() {
+			continue
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,126 @@
+package ioc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type englishGreeter struct{}
+
+func (eg *englishGreeter) Greet() string { return "hello" }
+
+type frenchGreeter struct{}
+
+func (fg *frenchGreeter) Greet() string { return "bonjour" }
+
+type greeterUser struct {
+	Greeter greeter
+	Any     interface{}
+}
+
+func TestAutowireSingleImplementation(t *testing.T) {
+
+	cc := newTestContainer()
+
+	cc.WrapAndAddProto("english", new(englishGreeter))
+
+	p := CreateProtoComponent(new(greeterUser), "wired")
+	p.AutowireByType = true
+	cc.AddProto(p)
+
+	cc.WrapAndAddProto("unwired", new(greeterUser))
+
+	test.ExpectNil(t, cc.Populate())
+
+	wired := cc.ComponentByName("wired").Instance.(*greeterUser)
+
+	if wired.Greeter == nil || wired.Greeter.Greet() != "hello" {
+		t.Errorf("Expected greeter to be autowired")
+	}
+
+	if wired.Any != nil {
+		t.Errorf("Empty interfaces should not be autowired")
+	}
+
+	if cc.ComponentByName("unwired").Instance.(*greeterUser).Greeter != nil {
+		t.Errorf("Components that have not opted in should not be autowired")
+	}
+}
+
+func TestAutowireAmbiguous(t *testing.T) {
+
+	cc := newTestContainer()
+
+	cc.WrapAndAddProto("english", new(englishGreeter))
+	cc.WrapAndAddProto("french", new(frenchGreeter))
+
+	p := CreateProtoComponent(new(greeterUser), "wired")
+	p.AutowireByType = true
+	cc.AddProto(p)
+
+	err := cc.Populate()
+
+	if err == nil {
+		t.Fatalf("Expected an ambiguity error")
+	}
+
+	if !strings.Contains(err.Error(), "wired.Greeter") || !strings.Contains(err.Error(), "english, french") {
+		t.Errorf("Unexpected error message %s", err.Error())
+	}
+}
+
+func TestExplicitRefOverridesAutowire(t *testing.T) {
+
+	cc := newTestContainer()
+
+	cc.WrapAndAddProto("english", new(englishGreeter))
+	cc.WrapAndAddProto("french", new(frenchGreeter))
+
+	p := CreateProtoComponent(new(greeterUser), "wired")
+	p.AutowireByType = true
+	p.AddDependency("Greeter", "french")
+	cc.AddProto(p)
+
+	test.ExpectNil(t, cc.Populate())
+
+	test.ExpectString(t, cc.ComponentByName("wired").Instance.(*greeterUser).Greeter.Greet(), "bonjour")
+}
+
+func TestAutowireOnlyConsidersApplicationSingletons(t *testing.T) {
+
+	cc := newTestContainer()
+
+	cc.WrapAndAddProto("english", new(englishGreeter))
+	cc.WrapAndAddProto("grncGreeter", new(frenchGreeter))
+
+	scoped := CreateScopedProtoComponent(func() interface{} { return new(frenchGreeter) }, "scopedGreeter", PrototypeScope)
+	cc.AddProto(scoped)
+
+	p := CreateProtoComponent(new(greeterUser), "wired")
+	p.AutowireByType = true
+	cc.AddProto(p)
+
+	test.ExpectNil(t, cc.Populate())
+
+	test.ExpectString(t, cc.ComponentByName("wired").Instance.(*greeterUser).Greeter.Greet(), "hello")
+}
+
+func TestAutowireCandidateFieldsRequiresPointerToStruct(t *testing.T) {
+
+	for _, c := range []interface{}{greeterUser{}, new(string), (*greeterUser)(nil)} {
+
+		if _, err := autowireCandidateFields(c); err == nil {
+			t.Errorf("Expected an error for %T", c)
+		}
+	}
+
+	fields, err := autowireCandidateFields(new(greeterUser))
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, len(fields), 1)
+}
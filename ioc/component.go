@@ -81,6 +81,51 @@ can be used as shorthand for config: and ref: See https://granitic.io/ref/compon
 
 Any error such as type mismatches or missing configuration will cause an error that will halt application startup.
 
+Component scopes
+
+By default each component is a singleton - a single instance is created and injected into every component that depends on it.
+A component definition can instead declare a scope:
+
+	{
+	  "components": {
+		"dbClient": {
+		  "type": "db.Client",
+		  "scope": "prototype"
+		}
+	  }
+	}
+
+A prototype component has a new instance created each time it is injected. A component with request scope has one instance
+created for each web service request (see WithRequestScope). Dependencies and configuration are applied to every
+new instance, as are decorators that implement ScopedComponentDecorator, but lifecycle interfaces are not. Singleton components can obtain instances of request scoped components via
+a field of type ioc.ScopedProvider.
+
+Autowiring by type
+
+A component definition can set "autowire": true to ask the container to inject dependencies by type. Any exported
+field on the component whose type is an interface, that is not otherwise set or the target of a ref:, will be injected
+with the single component that implements the interface. Only singleton components defined by your application are
+candidates - Granitic's own (grnc prefixed) components and prototype or request scoped components are never injected
+by type. If more than one component implements the interface, the container will refuse to start and will list the
+candidate components. Fields for which no component implements the interface are left untouched.
+
+Conditional components
+
+A component definition can make the existence of a component depend on configuration:
+
+	{
+	  "components": {
+		"paymentGateway": {
+		  "type": "payment.StubGateway",
+		  "if": "conf:Features.UseStubGateway"
+		}
+	  }
+	}
+
+The component is only added to the container if the config path holds the JSON bool true. Prefixing the condition with !
+(e.g. "!conf:Features.UseStubGateway") inverts the test. A missing path is treated as false. Any reference to a component
+that has been excluded will halt application startup with an error explaining why the component is not available.
+
 Component templates
 
 A template mechanism exists to allow multiple components that share a type, dependencies or configuration items to
@@ -127,6 +172,13 @@ There are several other possible lifecycle phases after the container is ready:
 		Stop        Components implementing ioc.Stoppable are allowed to stop gracefully before the application exits.
 
 
+Startup and shutdown order
+
+Components are started in an order derived from the dependencies declared in your component definition files. If a component
+depends on another component (directly, or via a chain of ref: dependencies), the component it depends on will be started
+first. Components are stopped in the reverse order. If the dependencies between Startable or Stoppable components form a cycle,
+the container will refuse to start and will log the components involved in the cycle.
+
 Decorators
 
 Decorators are special components implementing ioc.ComponentDecorator. Their main purpose is to inject dynamically
@@ -223,6 +275,17 @@ func CreateProtoComponent(componentInstance interface{}, componentName string) *
 
 }
 
+// CreateScopedProtoComponent creates a new ProtoComponent for a component that is not a singleton. The supplied factory
+// is used to create a new instance of the component whenever one is needed.
+func CreateScopedProtoComponent(factory InstanceFactory, componentName string, scope Scope) *ProtoComponent {
+
+	proto := CreateProtoComponent(factory(), componentName)
+	proto.Factory = factory
+	proto.Scope = scope
+
+	return proto
+}
+
 // A ProtoComponent is a partially configured component that will be hosted in the Granitic IoC container once
 // it is fully configured. Typically ProtoComponents are created using the grnc-bind tool.
 type ProtoComponent struct {
@@ -237,6 +300,33 @@ type ProtoComponent struct {
 
 	// A map of default values for fields if a config promise is not fulfilled
 	DefaultValues map[string]string
+
+	// How many instances of the component are created (singleton if not set)
+	Scope Scope
+
+	// Creates new instances of the component if it is not a singleton
+	Factory InstanceFactory
+
+	// If true, interface-typed fields are automatically injected with the only component that implements the interface
+	AutowireByType bool
+
+	// A config path to a bool that determines whether or not this component should be added to the container
+	ConditionPath string
+
+	// If true, the component is only added to the container if the value at ConditionPath is false
+	ConditionNegated bool
+}
+
+// SetCondition makes the inclusion of this component in the container conditional on the bool value at the supplied
+// config path being true (or false if negated is set). If the path does not exist, the value is considered false.
+func (pc *ProtoComponent) SetCondition(configPath string, negated bool) {
+	pc.ConditionPath = configPath
+	pc.ConditionNegated = negated
+}
+
+// Conditional returns true if the inclusion of this component in the container depends on configuration
+func (pc *ProtoComponent) Conditional() bool {
+	return pc.ConditionPath != ""
 }
 
 // AddDependency requests that the container injects another component into the specified field during the configure phase of
@@ -0,0 +1,99 @@
+package ioc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type gatewayUser struct {
+	Gateway *englishGreeter
+}
+
+func conditionalContainer() *ComponentContainer {
+	cc := newTestContainer()
+	cc.configAccessor.JSONData = map[string]interface{}{
+		"Features": map[string]interface{}{
+			"UseStubGateway": true,
+			"Broken":         "yes",
+		},
+	}
+
+	return cc
+}
+
+func TestConditionalComponents(t *testing.T) {
+
+	cc := conditionalContainer()
+
+	stub := CreateProtoComponent(new(englishGreeter), "stubGateway")
+	stub.SetCondition("Features.UseStubGateway", false)
+	cc.AddProto(stub)
+
+	real := CreateProtoComponent(new(englishGreeter), "realGateway")
+	real.SetCondition("Features.UseStubGateway", true)
+	cc.AddProto(real)
+
+	missing := CreateProtoComponent(new(englishGreeter), "missingPath")
+	missing.SetCondition("Features.Unset", false)
+	cc.AddProto(missing)
+
+	test.ExpectNil(t, cc.Populate())
+
+	if cc.ComponentByName("stubGateway") == nil {
+		t.Errorf("Expected stubGateway to be included")
+	}
+
+	if cc.ComponentByName("realGateway") != nil {
+		t.Errorf("Expected realGateway to be excluded")
+	}
+
+	if cc.ComponentByName("missingPath") != nil {
+		t.Errorf("Expected a condition with a missing path to be treated as false")
+	}
+}
+
+func TestReferenceToExcludedComponent(t *testing.T) {
+
+	cc := conditionalContainer()
+
+	real := CreateProtoComponent(new(englishGreeter), "realGateway")
+	real.SetCondition("Features.UseStubGateway", true)
+	cc.AddProto(real)
+
+	p := CreateProtoComponent(new(gatewayUser), "user")
+	p.AddDependency("Gateway", "realGateway")
+
+	err := cc.resolveAfterAdding(p)
+
+	if err == nil || !strings.Contains(err.Error(), "realGateway (required by user.Gateway) has been excluded") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestNonBoolCondition(t *testing.T) {
+
+	cc := conditionalContainer()
+
+	p := CreateProtoComponent(new(englishGreeter), "broken")
+	p.SetCondition("Features.Broken", false)
+	cc.AddProto(p)
+
+	if err := cc.Populate(); err == nil {
+		t.Errorf("Expected an error for a non-bool condition")
+	}
+}
+
+// resolveAfterAdding adds the supplied proto and resolves dependencies without exiting on error
+func (cc *ComponentContainer) resolveAfterAdding(p *ProtoComponent) error {
+	cc.AddProto(p)
+
+	cc.allComponents = make(map[string]*Component)
+
+	for _, pc := range cc.protoComponents {
+		cc.addComponent(pc.Component)
+	}
+
+	return cc.resolveDependenciesAndConfig()
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/graniticio/granitic/v2/types"
 	"os"
 	"sort"
+	"strings"
+	"sync"
 )
 
 const containerDecoratorComponentName = instance.FrameworkPrefix + "ContainerDecorator"
@@ -36,6 +38,9 @@ func NewComponentContainer(logm *logging.ComponentLoggerManager, ca *config.Acce
 	cc.configAccessor = ca
 	cc.modifiers = make(map[string]map[string]string)
 	cc.byLifecycleSupport = make(map[LifecycleSupport][]*Component)
+	cc.dependencyGraph = newDependencyGraph()
+	cc.scopedProtos = make(map[string]*ProtoComponent)
+	cc.excluded = make(map[string]string)
 	cc.system = sys
 
 	lcm := new(LifecycleManager)
@@ -62,6 +67,15 @@ type ComponentContainer struct {
 	modifiers          map[string]map[string]string
 	Lifecycle          *LifecycleManager
 	system             *instance.System
+	dependencyGraph    *dependencyGraph
+	scopedProtos       map[string]*ProtoComponent
+	excluded           map[string]string
+	conditionErrors    []string
+	scopedConfig       *config.Accessor
+	decorators         map[string]ComponentDecorator
+	wiring             *Wiring
+	recordWiring       bool
+	reloader           configReloader
 }
 
 // ProtoComponentsByType returns any ProtoComponents whose Component.Instance field matches the against the supplied TypeMatcher function.
@@ -166,12 +180,70 @@ func (cc *ComponentContainer) Modifiers(comp string) map[string]string {
 	return cc.modifiers[comp]
 }
 
-// AddProto registers an instantiated but un-configured proto-component.
+// AddProto registers an instantiated but un-configured proto-component. If the proto-component is conditional
+// (see ProtoComponent.SetCondition) and its condition is not met, it is discarded.
 func (cc *ComponentContainer) AddProto(proto *ProtoComponent) {
 
-	cc.FrameworkLogger.LogTracef("Adding proto %s", proto.Component.Name)
+	name := proto.Component.Name
 
-	cc.protoComponents[proto.Component.Name] = proto
+	if proto.Conditional() && !cc.conditionMet(proto) {
+		return
+	}
+
+	cc.FrameworkLogger.LogTracef("Adding proto %s", name)
+
+	cc.protoComponents[name] = proto
+}
+
+// conditionMet evaluates the condition attached to a proto-component against configuration. Components that are excluded
+// are recorded so that references to them can be explained.
+func (cc *ComponentContainer) conditionMet(proto *ProtoComponent) bool {
+
+	name := proto.Component.Name
+	path := proto.ConditionPath
+
+	var value bool
+
+	if cc.configAccessor.PathExists(path) {
+
+		var err error
+
+		if value, err = cc.configAccessor.BoolVal(path); err != nil {
+			m := fmt.Sprintf("The condition for component %s refers to config path %s which is not a bool", name, path)
+			cc.conditionErrors = append(cc.conditionErrors, m)
+
+			return false
+		}
+	}
+
+	if value != proto.ConditionNegated {
+		return true
+	}
+
+	expected := "true"
+
+	if proto.ConditionNegated {
+		expected = "false"
+	}
+
+	reason := fmt.Sprintf("its condition requires the config path %s to be %s", path, expected)
+
+	cc.FrameworkLogger.LogDebugf("Excluding component %s as %s", name, reason)
+	cc.excluded[name] = reason
+
+	return false
+}
+
+// missingComponentError explains why a component required by another component is not available
+func (cc *ComponentContainer) missingComponentError(depName, compName, fieldName string) error {
+
+	if reason := cc.excluded[depName]; reason != "" {
+		message := fmt.Sprintf("Component %s (required by %s.%s) has been excluded from the container as %s", depName, compName, fieldName, reason)
+		return errors.New(message)
+	}
+
+	message := fmt.Sprintf("No component named %s available (required by %s.%s)", depName, compName, fieldName)
+	return errors.New(message)
 }
 
 // WrapAndAddProto registers an instance and name as an un-configured proto-component.
@@ -197,6 +269,10 @@ func (cc *ComponentContainer) Populate() error {
 		}
 	}()
 
+	if len(cc.conditionErrors) > 0 {
+		return errors.New(strings.Join(cc.conditionErrors, "; "))
+	}
+
 	decorators := make(map[string]ComponentDecorator)
 
 	containerDecorator := new(ContainerDecorator)
@@ -215,10 +291,25 @@ func (cc *ComponentContainer) Populate() error {
 			return errors.New(m)
 		}
 
+		if protoComponent.Scope != SingletonScope {
+
+			if err := cc.addScoped(protoComponent); err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		cc.addComponent(component)
 		cc.captureDecorator(component, decorators)
 	}
 
+	cc.decorators = scopedDecorators(decorators)
+
+	if err := cc.autowireByType(); err != nil {
+		return err
+	}
+
 	err := cc.resolveDependenciesAndConfig()
 
 	if err != nil {
@@ -227,20 +318,78 @@ func (cc *ComponentContainer) Populate() error {
 		os.Exit(-1)
 	}
 
-	cc.runDecorators(decorators)
+	var before map[string]fieldSnapshot
+
+	if cc.recordWiring {
+
+		before = make(map[string]fieldSnapshot)
+
+		for name, c := range cc.allComponents {
+			before[name] = snapshotFields(c.Instance)
+		}
+	}
+
+	decoratedBy := cc.runDecorators(decorators)
+
+	if cc.recordWiring {
+		cc.wiring = cc.buildWiring(before, decoratedBy)
+	}
+
+	if len(cc.scopedProtos) == 0 {
+		// Decorators are only retained if they are needed to decorate new instances of non-singleton components
+		cc.decorators = nil
+	}
 
 	cc.protoComponents = nil
 
 	return nil
 }
 
+// addScoped stores a proto-component for a prototype or request scoped component. Instances of these components are
+// created on demand rather than stored in the container.
+func (cc *ComponentContainer) addScoped(proto *ProtoComponent) error {
+
+	name := proto.Component.Name
+
+	if proto.Factory == nil {
+		return fmt.Errorf("component %s has %s scope but has no InstanceFactory. Re-run grnc-bind and rebuild", name, proto.Scope.Label())
+	}
+
+	if _, decorator := proto.Component.Instance.(ComponentDecorator); decorator {
+		return fmt.Errorf("component %s is a ComponentDecorator and must have %s scope", name, SingletonScopeLabel)
+	}
+
+	cc.FrameworkLogger.LogTracef("%s has %s scope", name, proto.Scope.Label())
+
+	cc.scopedProtos[name] = proto
+
+	return nil
+}
+
 func (cc *ComponentContainer) resolveDependenciesAndConfig() error {
 
+	if err := cc.prepareScoped(); err != nil {
+		return err
+	}
+
 	fl := cc.FrameworkLogger
-	pi := new(types.ParamValueInjector)
+
+	// Injection of non-singleton instances is deferred until all singletons have their dependencies, so that
+	// decorators are fully configured before they are applied to new instances
+	type deferredInjection struct {
+		target    *ProtoComponent
+		fieldName string
+		depName   string
+	}
+
+	deferred := make([]deferredInjection, 0)
 
 	for _, targetProto := range cc.protoComponents {
 
+		if targetProto.Scope != SingletonScope {
+			continue
+		}
+
 		compName := targetProto.Component.Name
 		deps := cc.mergeDependencies(compName, targetProto.Dependencies)
 
@@ -251,8 +400,14 @@ func (cc *ComponentContainer) resolveDependenciesAndConfig() error {
 			requiredComponent := cc.allComponents[depName]
 
 			if requiredComponent == nil {
-				message := fmt.Sprintf("No component named %s available (required by %s.%s)", depName, compName, fieldName)
-				return errors.New(message)
+
+				if cc.scopedProtos[depName] != nil {
+					deferred = append(deferred, deferredInjection{targetProto, fieldName, depName})
+					cc.dependencyGraph.addDependency(compName, depName)
+					continue
+				}
+
+				return cc.missingComponentError(depName, compName, fieldName)
 			}
 
 			targetInstance := targetProto.Component.Instance
@@ -265,36 +420,130 @@ func (cc *ComponentContainer) resolveDependenciesAndConfig() error {
 				return errors.New(m)
 			}
 
+			cc.dependencyGraph.addDependency(compName, depName)
 		}
 
-		for fieldName, configPath := range targetProto.ConfigPromises {
-			fl.LogTracef("%s.%s needs %s", compName, fieldName, configPath)
+		if err := cc.injectConfig(targetProto, targetProto.Component.Instance, cc.configAccessor); err != nil {
+			return err
+		}
 
-			target := targetProto.Component.Instance
+	}
 
-			if err := cc.configAccessor.SetField(fieldName, configPath, target); err != nil {
+	for _, d := range deferred {
 
-				if _, found := err.(config.MissingPathError); found && targetProto.HasDefaultValue(fieldName) {
+		compName := d.target.Component.Name
+		targetInstance := d.target.Component.Instance
 
-					fl.LogDebugf("Default value found for %s.%s - attempting to inject", compName, fieldName)
+		dep, err := cc.dependencyInstance(d.depName, targetInstance, d.fieldName, nil, []string{compName})
 
-					df := targetProto.DefaultValue(fieldName)
-					params := types.NewSingleValueParams(fieldName, df)
+		if err == nil {
+			err = reflecttools.SetPtrToStruct(targetInstance, d.fieldName, dep)
+		}
 
-					if err = pi.BindValueToField(fieldName, fieldName, params, target, defaultValueInjectionError); err != nil {
+		if err != nil {
+			m := fmt.Sprintf("Problem injecting dependency '%s' into %s.%s: %s", d.depName, compName, d.fieldName, err.Error())
+			return errors.New(m)
+		}
+	}
 
-						err = fmt.Errorf("problem using a default value to populate component %s.%s. "+
-							"Check your component definition files and rebuild or set a valid value in configuration at %s: %s", compName, fieldName, configPath, err.Error())
+	return nil
+}
 
-					}
+// prepareScoped checks that the dependencies of prototype and request scoped components exist and takes a copy of the
+// configuration they need, so that new instances can be configured after the merged configuration has been flushed.
+func (cc *ComponentContainer) prepareScoped() error {
 
-				}
+	if len(cc.scopedProtos) == 0 {
+		return nil
+	}
+
+	cc.scopedConfig = &config.Accessor{JSONData: make(map[string]interface{}), FrameworkLogger: cc.FrameworkLogger,
+		PlaceholderPaths: cc.configAccessor.PlaceholderPaths}
+
+	for name, proto := range cc.scopedProtos {
+
+		for fieldName, depName := range cc.mergeDependencies(name, proto.Dependencies) {
+
+			if cc.allComponents[depName] == nil && cc.scopedProtos[depName] == nil {
+				return cc.missingComponentError(depName, name, fieldName)
+			}
+
+			cc.dependencyGraph.addDependency(name, depName)
+		}
+
+		// Configure the proto's own instance to check the configuration is present and valid
+		if err := cc.injectConfig(proto, proto.Component.Instance, cc.configAccessor); err != nil {
+			return err
+		}
+
+		for _, configPath := range proto.ConfigPromises {
+			cc.copyScopedConfig(configPath)
+		}
+	}
+
+	return nil
+}
+
+// copyScopedConfig copies the value at the supplied path from the merged configuration into the configuration retained
+// for non-singleton components
+func (cc *ComponentContainer) copyScopedConfig(path string) {
+
+	v := cc.configAccessor.Value(path)
+
+	if v == nil {
+		return
+	}
+
+	steps := strings.Split(path, config.JSONPathSeparator)
+	m := cc.scopedConfig.JSONData
+
+	for _, step := range steps[:len(steps)-1] {
+
+		next, found := m[step].(map[string]interface{})
+
+		if !found {
+			next = make(map[string]interface{})
+			m[step] = next
+		}
+
+		m = next
+	}
+
+	m[steps[len(steps)-1]] = v
+}
+
+// injectConfig fulfils the config promises made for the supplied proto-component's fields using the supplied accessor,
+// falling back to default values where available.
+func (cc *ComponentContainer) injectConfig(proto *ProtoComponent, target interface{}, ca *config.Accessor) error {
+
+	fl := cc.FrameworkLogger
+	pi := new(types.ParamValueInjector)
+	compName := proto.Component.Name
+
+	for fieldName, configPath := range proto.ConfigPromises {
+		fl.LogTracef("%s.%s needs %s", compName, fieldName, configPath)
+
+		if err := ca.SetField(fieldName, configPath, target); err != nil {
+
+			if _, found := err.(config.MissingPathError); found && proto.HasDefaultValue(fieldName) {
+
+				fl.LogDebugf("Default value found for %s.%s - attempting to inject", compName, fieldName)
+
+				df := proto.DefaultValue(fieldName)
+				params := types.NewSingleValueParams(fieldName, df)
+
+				if err = pi.BindValueToField(fieldName, fieldName, params, target, defaultValueInjectionError); err != nil {
+
+					err = fmt.Errorf("problem using a default value to populate component %s.%s. "+
+						"Check your component definition files and rebuild or set a valid value in configuration at %s: %s", compName, fieldName, configPath, err.Error())
 
-				if err != nil {
-					return err
 				}
+
 			}
 
+			if err != nil {
+				return err
+			}
 		}
 
 	}
@@ -320,14 +569,26 @@ func (cc *ComponentContainer) mergeDependencies(comp string, cd map[string]strin
 	return merged
 }
 
-func (cc *ComponentContainer) runDecorators(decorators map[string]ComponentDecorator) {
+// runDecorators applies each decorator to the components it is interested in and returns a map of component names to
+// the names of the decorators that were applied to them
+func (cc *ComponentContainer) runDecorators(decorators map[string]ComponentDecorator) map[string][]string {
 
 	decs := len(decorators)
 	done := make(chan string, decs)
 
+	decoratedBy := make(map[string][]string)
+	var mu sync.Mutex
+
+	record := func(component, decorator string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		decoratedBy[component] = append(decoratedBy[component], decorator)
+	}
+
 	for n, d := range decorators {
 
-		go cc.runDecorator(n, d, done)
+		go cc.runDecorator(n, d, done, record)
 	}
 
 	doneCount := 0
@@ -345,19 +606,36 @@ func (cc *ComponentContainer) runDecorators(decorators map[string]ComponentDecor
 	for n := range decorators {
 		delete(cc.allComponents, n)
 	}
+
+	return decoratedBy
 }
 
-func (cc *ComponentContainer) runDecorator(name string, cd ComponentDecorator, ch chan<- string) {
+func (cc *ComponentContainer) runDecorator(name string, cd ComponentDecorator, ch chan<- string, record func(component, decorator string)) {
 
 	for _, component := range cc.allComponents {
 		if cd.OfInterest(component) {
 			cd.DecorateComponent(component, cc)
+			record(component.Name, name)
 		}
 	}
 
 	ch <- name
 }
 
+// scopedDecorators returns the decorators that have opted in to decorating new instances of non-singleton components
+func scopedDecorators(decorators map[string]ComponentDecorator) map[string]ComponentDecorator {
+
+	scoped := make(map[string]ComponentDecorator)
+
+	for n, d := range decorators {
+		if sd, found := d.(ScopedComponentDecorator); found && sd.DecoratesScopedInstances() {
+			scoped[n] = d
+		}
+	}
+
+	return scoped
+}
+
 func (cc *ComponentContainer) captureDecorator(component *Component, decorators map[string]ComponentDecorator) {
 
 	decorator, isDecorator := component.Instance.(ComponentDecorator)
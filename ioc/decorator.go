@@ -17,6 +17,19 @@ type ComponentDecorator interface {
 	DecorateComponent(subject *Component, container *ComponentContainer)
 }
 
+// ScopedComponentDecorator is implemented by a ComponentDecorator that should also be applied to each new instance of a
+// prototype or request scoped component. Decorators that do not implement this interface are only applied to singleton
+// components.
+//
+// New instances may be created concurrently while requests are being processed, so a decorator should only
+// return true from DecoratesScopedInstances if its DecorateComponent method modifies nothing but the subject component.
+type ScopedComponentDecorator interface {
+	ComponentDecorator
+
+	// DecoratesScopedInstances returns true if the decorator should be applied to new instances of non-singleton components.
+	DecoratesScopedInstances() bool
+}
+
 // ContainerAccessor is implemented by any component that wants direct access to the IoC container.
 type ContainerAccessor interface {
 	// Container accepts a reference to the Granitic IoC container.
@@ -47,3 +60,8 @@ func (cd *ContainerDecorator) DecorateComponent(subject *Component, cc *Componen
 	accessor.Container(cc)
 
 }
+
+// DecoratesScopedInstances returns true, as injecting the container only modifies the subject component.
+func (cd *ContainerDecorator) DecoratesScopedInstances() bool {
+	return true
+}
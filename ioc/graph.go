@@ -0,0 +1,181 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package ioc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// newDependencyGraph creates an empty dependencyGraph
+func newDependencyGraph() *dependencyGraph {
+	dg := new(dependencyGraph)
+	dg.dependsOn = make(map[string][]string)
+
+	return dg
+}
+
+// dependencyGraph records which components depend on which other components (via ref: dependencies declared
+// in component definition files or framework modifiers). It is used to determine the order in which lifecycle
+// methods are invoked on components.
+type dependencyGraph struct {
+	dependsOn map[string][]string
+}
+
+// addDependency records that the component named 'from' depends on the component named 'to'
+func (dg *dependencyGraph) addDependency(from, to string) {
+
+	for _, existing := range dg.dependsOn[from] {
+		if existing == to {
+			return
+		}
+	}
+
+	dg.dependsOn[from] = append(dg.dependsOn[from], to)
+}
+
+// dependencies returns the names of the components the named component directly depends on.
+func (dg *dependencyGraph) dependencies(name string) []string {
+	return dg.dependsOn[name]
+}
+
+// reduce builds a graph that only contains the supplied components. An edge from A to B exists in the reduced graph if A
+// depends on B directly or via a chain of components that are not members of the supplied set.
+func (dg *dependencyGraph) reduce(comps []*Component) map[string][]string {
+
+	members := make(map[string]bool)
+
+	for _, c := range comps {
+		members[c.Name] = true
+	}
+
+	reduced := make(map[string][]string)
+
+	for _, c := range comps {
+
+		found := make([]string, 0)
+		visited := map[string]bool{c.Name: true}
+
+		var walk func(name string)
+
+		walk = func(name string) {
+			for _, dep := range dg.dependsOn[name] {
+
+				if visited[dep] {
+					continue
+				}
+
+				visited[dep] = true
+
+				if members[dep] {
+					found = append(found, dep)
+				} else {
+					walk(dep)
+				}
+			}
+		}
+
+		walk(c.Name)
+
+		sort.Strings(found)
+		reduced[c.Name] = found
+	}
+
+	return reduced
+}
+
+// order sorts the supplied components so that every component appears after all of the components it depends on.
+// Components with no dependency relationship are ordered by name. An error describing the cycle is returned if
+// the components cannot be ordered.
+func (dg *dependencyGraph) order(comps []*Component) ([]*Component, error) {
+
+	byName := make(map[string]*Component)
+	names := make([]string, len(comps))
+
+	for i, c := range comps {
+		byName[c.Name] = c
+		names[i] = c.Name
+	}
+
+	sort.Strings(names)
+
+	reduced := dg.reduce(comps)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int)
+	ordered := make([]*Component, 0, len(comps))
+	chain := make([]string, 0)
+
+	var visit func(name string) error
+
+	visit = func(name string) error {
+
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return newCycleError(chain, name)
+		}
+
+		state[name] = visiting
+		chain = append(chain, name)
+
+		for _, dep := range reduced[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		chain = chain[:len(chain)-1]
+		state[name] = visited
+
+		ordered = append(ordered, byName[name])
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// newCycleError builds an error showing the chain of components that lead back to the supplied component
+func newCycleError(chain []string, repeated string) error {
+
+	var start int
+
+	for i, name := range chain {
+		if name == repeated {
+			start = i
+			break
+		}
+	}
+
+	cycle := append(append([]string{}, chain[start:]...), repeated)
+
+	return fmt.Errorf("unable to determine the order in which to start and stop components as they have a cyclic dependency: %s", strings.Join(cycle, " -> "))
+}
+
+// reverse returns a copy of the supplied components in the opposite order
+func reverse(comps []*Component) []*Component {
+
+	l := len(comps)
+	r := make([]*Component, l)
+
+	for i, c := range comps {
+		r[l-i-1] = c
+	}
+
+	return r
+}
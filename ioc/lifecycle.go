@@ -128,6 +128,10 @@ func (lm *LifecycleManager) StartAll() error {
 /*
 Start starts the supplied components, waits for any access-blocking components to be ready, then makes all
 components accessible. See GoDoc for Startable, AccessibilityBlocker and Accessible for more details.
+
+Components are started in an order determined by the dependencies declared in component definition files - a component
+is only started after all of the components it depends on (directly or indirectly) have been started. An error is
+returned if the components have a cyclic dependency.
 */
 func (lm *LifecycleManager) Start(startable []*Component) error {
 
@@ -147,15 +151,14 @@ func (lm *LifecycleManager) Start(startable []*Component) error {
 
 func (lm *LifecycleManager) start(start []*Component, access []*Component) error {
 
-	for _, component := range start {
-
-		startable := component.Instance.(Startable)
+	access, err := lm.inDependencyOrder(access)
 
-		if err := startable.StartComponent(); err != nil {
-			message := fmt.Sprintf("Unable to start %s: %s", component.Name, err)
-			return errors.New(message)
-		}
+	if err != nil {
+		return err
+	}
 
+	if err := lm.startComponents(start); err != nil {
+		return err
 	}
 
 	if lm.system.GCAfterStart {
@@ -243,15 +246,30 @@ func (lm *LifecycleManager) ResumeComponents(comps []*Component) error {
 	return nil
 }
 
+// inDependencyOrder sorts the supplied components so that each component appears after any components it depends on.
+func (lm *LifecycleManager) inDependencyOrder(comps []*Component) ([]*Component, error) {
+
+	return lm.container.dependencyGraph.order(comps)
+}
+
 /*
 StopComponents invokes PrepareToStop on all components then waits for them to be ready to stop by
 calling ReadyToStop on each component. If one or more components are not ready, they are given x chances to become
 ready with y milliseconds between each check. See https://granitic.io/ref/system-configuration
 
 If all components are ready, or if x has been exceeded, Stop is called on all components.
+
+Components are stopped in the reverse of the order in which they would be started, so a component is stopped before
+any of the components it depends on.
 */
 func (lm *LifecycleManager) StopComponents(comps []*Component) error {
 
+	if ordered, err := lm.inDependencyOrder(comps); err == nil {
+		comps = reverse(ordered)
+	} else {
+		lm.FrameworkLogger.LogErrorf("%s. Components will be stopped in no particular order", err.Error())
+	}
+
 	for _, s := range comps {
 
 		s.Instance.(Stoppable).PrepareToStop()
@@ -0,0 +1,178 @@
+package ioc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/instance"
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type orderRecorder struct {
+	started []string
+	stopped []string
+}
+
+type orderedComp struct {
+	name     string
+	recorder *orderRecorder
+	Dep      interface{}
+	Other    interface{}
+}
+
+func (oc *orderedComp) StartComponent() error {
+	oc.recorder.started = append(oc.recorder.started, oc.name)
+	return nil
+}
+
+func (oc *orderedComp) PrepareToStop() {}
+
+func (oc *orderedComp) ReadyToStop() (bool, error) {
+	return true, nil
+}
+
+func (oc *orderedComp) Stop() error {
+	oc.recorder.stopped = append(oc.recorder.stopped, oc.name)
+	return nil
+}
+
+type passiveComp struct {
+	Dep interface{}
+}
+
+func newTestContainer() *ComponentContainer {
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+
+	sys := new(instance.System)
+	sys.StopRetries = 1
+
+	return NewComponentContainer(lm, new(config.Accessor), sys)
+}
+
+func addOrdered(cc *ComponentContainer, r *orderRecorder, name string, deps ...string) {
+	p := CreateProtoComponent(&orderedComp{name: name, recorder: r}, name)
+
+	fields := []string{"Dep", "Other"}
+
+	for i, d := range deps {
+		p.AddDependency(fields[i], d)
+	}
+
+	cc.AddProto(p)
+}
+
+func TestStartAndStopInDependencyOrder(t *testing.T) {
+
+	cc := newTestContainer()
+	r := new(orderRecorder)
+
+	addOrdered(cc, r, "cacheWarmer", "dbManager")
+	addOrdered(cc, r, "dbManager", "pool")
+	addOrdered(cc, r, "api", "cacheWarmer", "logic")
+	addOrdered(cc, r, "connection")
+
+	// logic is not startable, but carries the dependency on connection
+	lp := CreateProtoComponent(new(passiveComp), "logic")
+	lp.AddDependency("Dep", "connection")
+	cc.AddProto(lp)
+
+	addOrdered(cc, r, "pool")
+
+	test.ExpectNil(t, cc.Populate())
+	test.ExpectNil(t, cc.Lifecycle.StartAll())
+
+	test.ExpectString(t, strings.Join(r.started, ","), "pool,dbManager,cacheWarmer,connection,api")
+
+	cc.Lifecycle.StopAll()
+
+	test.ExpectString(t, strings.Join(r.stopped, ","), "api,connection,cacheWarmer,dbManager,pool")
+}
+
+func TestCyclicDependencyPreventsStart(t *testing.T) {
+
+	cc := newTestContainer()
+	r := new(orderRecorder)
+
+	addOrdered(cc, r, "a", "b")
+	addOrdered(cc, r, "b", "c")
+	addOrdered(cc, r, "c", "a")
+
+	test.ExpectNil(t, cc.Populate())
+
+	err := cc.Lifecycle.StartAll()
+
+	if err == nil {
+		t.Fatalf("Expected an error for cyclic dependencies")
+	}
+
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("Unexpected error message %s", err.Error())
+	}
+
+	test.ExpectInt(t, len(r.started), 0)
+}
+
+type blockingComp struct {
+	wait    chan bool
+	signal  chan bool
+	started bool
+}
+
+func (bc *blockingComp) StartComponent() error {
+
+	if bc.signal != nil {
+		close(bc.signal)
+	}
+
+	if bc.wait != nil {
+		<-bc.wait
+	}
+
+	bc.started = true
+
+	return nil
+}
+
+func TestIndependentComponentsStartInParallel(t *testing.T) {
+
+	cc := newTestContainer()
+	cc.system.ParallelStart = true
+	cc.system.StartTimeoutMS = 1000
+
+	ch := make(chan bool)
+
+	// Each component can only finish starting if the other has been started at the same time
+	a := &blockingComp{wait: ch}
+	b := &blockingComp{signal: ch}
+
+	cc.WrapAndAddProto("a", a)
+	cc.WrapAndAddProto("b", b)
+
+	test.ExpectNil(t, cc.Populate())
+	test.ExpectNil(t, cc.Lifecycle.StartAll())
+
+	test.ExpectBool(t, a.started, true)
+	test.ExpectBool(t, b.started, true)
+}
+
+func TestSlowComponentTimesOut(t *testing.T) {
+
+	cc := newTestContainer()
+	cc.system.StartTimeoutMS = 20
+
+	cc.WrapAndAddProto("slow", &blockingComp{wait: make(chan bool)})
+
+	test.ExpectNil(t, cc.Populate())
+
+	err := cc.Lifecycle.StartAll()
+
+	if _, timedOut := err.(StartTimeoutError); !timedOut {
+		t.Fatalf("Expected slow component to time out, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "slow") || !strings.Contains(err.Error(), "did not start within") {
+		t.Errorf("Unexpected error message %s", err.Error())
+	}
+}
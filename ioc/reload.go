@@ -0,0 +1,120 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/graniticio/granitic/v2/config"
+)
+
+/*
+ConfigReloadable is implemented by components that are able to apply changes to configuration without the application
+being restarted. When configuration is reloaded (by sending SIGHUP to the application's process or via the reload
+runtime control command), the configuration files are re-read and re-merged and the resulting Accessor is passed to every
+ConfigReloadable component.
+*/
+type ConfigReloadable interface {
+	// ReloadConfig is called with an Accessor over the newly merged configuration. If the new configuration is unacceptable,
+	// the component should retain its existing configuration and return an error explaining why the change was rejected.
+	ReloadConfig(ca *config.Accessor) error
+}
+
+// ConfigLoader re-reads and merges an application's configuration.
+type ConfigLoader func() (*config.Accessor, error)
+
+// ReloadOutcome records whether or not a ConfigReloadable component accepted reloaded configuration.
+type ReloadOutcome struct {
+	// The name of the component
+	Component string
+
+	// The reason the component rejected the configuration (nil if it was accepted)
+	Err error
+}
+
+// Accepted returns true if the component applied the reloaded configuration.
+func (ro *ReloadOutcome) Accepted() bool {
+	return ro.Err == nil
+}
+
+// configReloader holds the function used to re-read configuration and ensures only one reload runs at a time
+type configReloader struct {
+	sync.Mutex
+	loader ConfigLoader
+}
+
+// SetConfigLoader registers the function used to re-read and merge configuration when ReloadConfig is called.
+func (cc *ComponentContainer) SetConfigLoader(loader ConfigLoader) {
+	cc.reloader.loader = loader
+}
+
+// ReloadConfig re-reads configuration using the registered ConfigLoader and passes it to every component that implements
+// ConfigReloadable (in name order). An error is returned (and no components are called) if the configuration could not
+// be loaded.
+func (cc *ComponentContainer) ReloadConfig() ([]*ReloadOutcome, error) {
+
+	r := &cc.reloader
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.loader == nil {
+		return nil, errors.New("Configuration cannot be reloaded as no ConfigLoader has been registered with the container")
+	}
+
+	fl := cc.FrameworkLogger
+
+	fl.LogInfof("Reloading configuration")
+
+	ca, err := r.loader()
+
+	if err != nil {
+		message := fmt.Sprintf("Unable to reload configuration: %s", err.Error())
+		fl.LogErrorf(message)
+
+		return nil, errors.New(message)
+	}
+
+	outcomes := make([]*ReloadOutcome, 0)
+	rejected := 0
+
+	for _, c := range cc.AllComponents() {
+
+		cr, reloadable := c.Instance.(ConfigReloadable)
+
+		if !reloadable {
+			continue
+		}
+
+		o := &ReloadOutcome{Component: c.Name, Err: cc.reloadComponent(c.Name, cr, ca)}
+
+		if o.Accepted() {
+			fl.LogDebugf("%s accepted reloaded configuration", c.Name)
+		} else {
+			rejected++
+			fl.LogWarnf("%s rejected reloaded configuration: %s", c.Name, o.Err.Error())
+		}
+
+		outcomes = append(outcomes, o)
+	}
+
+	fl.LogInfof("Configuration reloaded (%d component(s) accepted, %d rejected)", len(outcomes)-rejected, rejected)
+
+	return outcomes, nil
+}
+
+// reloadComponent passes reloaded configuration to a component, converting any panic into an error.
+func (cc *ComponentContainer) reloadComponent(name string, cr ConfigReloadable, ca *config.Accessor) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			cc.FrameworkLogger.LogErrorfWithTrace("Panic recovered while reloading configuration for %s %s", name, r)
+			err = fmt.Errorf("panic during reload: %v", r)
+		}
+	}()
+
+	return cr.ReloadConfig(ca)
+}
@@ -0,0 +1,79 @@
+package ioc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/config"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type reloadableComp struct {
+	Toggle bool
+	reject bool
+	panic  bool
+}
+
+func (rc *reloadableComp) ReloadConfig(ca *config.Accessor) error {
+
+	if rc.panic {
+		panic("reload")
+	}
+
+	if rc.reject {
+		return errors.New("rejected")
+	}
+
+	v, err := ca.BoolVal("Features.Toggle")
+	rc.Toggle = v
+
+	return err
+}
+
+func TestReloadConfig(t *testing.T) {
+
+	cc := newTestContainer()
+
+	if _, err := cc.ReloadConfig(); err == nil {
+		t.Errorf("Expected an error when no loader is registered")
+	}
+
+	accepting := new(reloadableComp)
+
+	cc.WrapAndAddProto("accepting", accepting)
+	cc.WrapAndAddProto("rejecting", &reloadableComp{reject: true})
+	cc.WrapAndAddProto("panicking", &reloadableComp{panic: true})
+	cc.WrapAndAddProto("other", new(passiveComp))
+
+	test.ExpectNil(t, cc.Populate())
+
+	loadErr := errors.New("unreadable")
+
+	cc.SetConfigLoader(func() (*config.Accessor, error) {
+		return nil, loadErr
+	})
+
+	if _, err := cc.ReloadConfig(); err == nil {
+		t.Errorf("Expected an error when configuration cannot be loaded")
+	}
+
+	cc.SetConfigLoader(func() (*config.Accessor, error) {
+		data := map[string]interface{}{"Features": map[string]interface{}{"Toggle": true}}
+		return &config.Accessor{JSONData: data, FrameworkLogger: cc.FrameworkLogger}, nil
+	})
+
+	outcomes, err := cc.ReloadConfig()
+
+	test.ExpectNil(t, err)
+	test.ExpectInt(t, len(outcomes), 3)
+
+	test.ExpectString(t, outcomes[0].Component, "accepting")
+	test.ExpectBool(t, outcomes[0].Accepted(), true)
+	test.ExpectBool(t, accepting.Toggle, true)
+
+	test.ExpectString(t, outcomes[1].Component, "panicking")
+	test.ExpectBool(t, outcomes[1].Accepted(), false)
+
+	test.ExpectString(t, outcomes[2].Component, "rejecting")
+	test.ExpectString(t, outcomes[2].Err.Error(), "rejected")
+}
@@ -0,0 +1,232 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/graniticio/granitic/v2/reflecttools"
+)
+
+// Scope determines how many instances of a component are created and how long they live.
+type Scope int
+
+const (
+	// SingletonScope indicates that a single instance of the component is created and shared by every component that depends on it
+	SingletonScope Scope = iota
+	// PrototypeScope indicates that a new instance of the component is created every time it is injected or requested
+	PrototypeScope
+	// RequestScope indicates that one instance of the component is created for each request context (see WithRequestScope)
+	RequestScope
+)
+
+// Labels for each scope as used in component definition files
+const (
+	SingletonScopeLabel = "singleton"
+	PrototypeScopeLabel = "prototype"
+	RequestScopeLabel   = "request"
+)
+
+// ScopeFromLabel converts a scope label (as used in component definition files) into a Scope. An error is returned if
+// the label is not recognised.
+func ScopeFromLabel(label string) (Scope, error) {
+
+	switch strings.ToLower(strings.TrimSpace(label)) {
+	case SingletonScopeLabel:
+		return SingletonScope, nil
+	case PrototypeScopeLabel:
+		return PrototypeScope, nil
+	case RequestScopeLabel:
+		return RequestScope, nil
+	}
+
+	return SingletonScope, fmt.Errorf("%s is not a valid scope. Must be one of %s, %s or %s", label, SingletonScopeLabel, PrototypeScopeLabel, RequestScopeLabel)
+}
+
+// Label returns the component definition file label for this scope
+func (s Scope) Label() string {
+	switch s {
+	case PrototypeScope:
+		return PrototypeScopeLabel
+	case RequestScope:
+		return RequestScopeLabel
+	default:
+		return SingletonScopeLabel
+	}
+}
+
+// InstanceFactory creates a new, unconfigured instance of a component. Factories are generated by the grnc-bind tool for
+// components that are not singletons.
+type InstanceFactory func() interface{}
+
+/*
+ScopedProvider gives access to instances of prototype or request scoped components. If a component field is of this
+type and the field is the target of a ref: to a non-singleton component, the container injects a ScopedProvider
+rather than an instance of the component.
+
+This is the only way that singleton components can make use of request scoped components.
+*/
+type ScopedProvider interface {
+	// Instance returns a fully configured instance of the component. Prototype components return a new instance on every
+	// call. Request scoped components return the same instance for every call with the same request context.
+	Instance(ctx context.Context) (interface{}, error)
+}
+
+type scopeKey string
+
+const requestScopeKey scopeKey = "GRNCREQSCOPE"
+
+// WithRequestScope returns a child of the supplied context that is able to hold request scoped component instances. The
+// HTTPServer facility calls this method on behalf of your application for every request it handles.
+func WithRequestScope(ctx context.Context) context.Context {
+
+	rs := new(requestScope)
+	rs.instances = make(map[string]interface{})
+
+	return context.WithValue(ctx, requestScopeKey, rs)
+}
+
+func requestScopeFromContext(ctx context.Context) *requestScope {
+
+	if ctx == nil {
+		return nil
+	}
+
+	rs, _ := ctx.Value(requestScopeKey).(*requestScope)
+
+	return rs
+}
+
+// requestScope holds the request scoped instances that have been created for a single request
+type requestScope struct {
+	sync.Mutex
+	instances map[string]interface{}
+}
+
+// scopedProvider is the container's implementation of ScopedProvider
+type scopedProvider struct {
+	container *ComponentContainer
+	name      string
+}
+
+// Instance implements ScopedProvider.Instance
+func (sp *scopedProvider) Instance(ctx context.Context) (interface{}, error) {
+	return sp.container.ScopedInstance(ctx, sp.name)
+}
+
+var scopedProviderType = reflect.TypeOf((*ScopedProvider)(nil)).Elem()
+
+// wantsProvider returns true if the named field on the target is of type ScopedProvider
+func wantsProvider(target interface{}, field string) bool {
+
+	if !reflecttools.HasFieldOfName(target, field) {
+		return false
+	}
+
+	return reflecttools.TypeOfField(target, field) == scopedProviderType
+}
+
+// ScopedInstance returns an instance of the named prototype or request scoped component. Request scoped components
+// are only available if the supplied context was created with WithRequestScope.
+func (cc *ComponentContainer) ScopedInstance(ctx context.Context, name string) (interface{}, error) {
+
+	if cc.scopedProtos[name] == nil {
+		return nil, fmt.Errorf("no prototype or request scoped component named %s", name)
+	}
+
+	rs := requestScopeFromContext(ctx)
+
+	if rs != nil {
+		rs.Lock()
+		defer rs.Unlock()
+	}
+
+	return cc.newScopedInstance(name, rs, nil)
+}
+
+// newScopedInstance creates and configures (or, for request scoped components, finds) an instance of the named component.
+// The chain of components currently being created is used to detect cycles.
+func (cc *ComponentContainer) newScopedInstance(name string, rs *requestScope, chain []string) (interface{}, error) {
+
+	proto := cc.scopedProtos[name]
+
+	for _, c := range chain {
+		if c == name {
+			return nil, fmt.Errorf("cyclic dependency between non-singleton components: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+
+	if proto.Scope == RequestScope {
+
+		if rs == nil {
+			return nil, fmt.Errorf("component %s has %s scope but no request is in progress. Singleton components must access it via an ioc.ScopedProvider field", name, RequestScopeLabel)
+		}
+
+		if i := rs.instances[name]; i != nil {
+			return i, nil
+		}
+	}
+
+	instance := proto.Factory()
+	chain = append(chain, name)
+
+	if n, nameable := instance.(ComponentNamer); nameable {
+		n.SetComponentName(name)
+	}
+
+	for fieldName, depName := range cc.mergeDependencies(name, proto.Dependencies) {
+
+		dep, err := cc.dependencyInstance(depName, instance, fieldName, rs, chain)
+
+		if err != nil {
+			return nil, fmt.Errorf("problem creating an instance of %s: %s", name, err.Error())
+		}
+
+		if err := reflecttools.SetPtrToStruct(instance, fieldName, dep); err != nil {
+			return nil, fmt.Errorf("problem injecting dependency '%s' into %s.%s: %s", depName, name, fieldName, err.Error())
+		}
+
+	}
+
+	if err := cc.injectConfig(proto, instance, cc.scopedConfig); err != nil {
+		return nil, err
+	}
+
+	component := NewComponent(name, instance)
+
+	for _, d := range cc.decorators {
+		if d.OfInterest(component) {
+			d.DecorateComponent(component, cc)
+		}
+	}
+
+	if proto.Scope == RequestScope {
+		rs.instances[name] = instance
+	}
+
+	return instance, nil
+}
+
+// dependencyInstance finds the instance that should be injected into the named field on the target to satisfy a dependency
+// on the named component.
+func (cc *ComponentContainer) dependencyInstance(depName string, target interface{}, fieldName string, rs *requestScope, chain []string) (interface{}, error) {
+
+	if c := cc.allComponents[depName]; c != nil {
+		return c.Instance, nil
+	}
+
+	if cc.scopedProtos[depName] == nil {
+		return nil, fmt.Errorf("no component named %s available", depName)
+	}
+
+	if wantsProvider(target, fieldName) {
+		return &scopedProvider{container: cc, name: depName}, nil
+	}
+
+	return cc.newScopedInstance(depName, rs, chain)
+}
@@ -0,0 +1,256 @@
+package ioc
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/logging"
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type scopedClient struct {
+	Label     string
+	Shared    *singletonDep
+	Decorated bool
+	Log       logging.Logger
+}
+
+type singletonDep struct {
+}
+
+type clientUser struct {
+	Client   *scopedClient
+	Provider ScopedProvider
+}
+
+type markingDecorator struct{}
+
+func (md *markingDecorator) OfInterest(subject *Component) bool {
+	_, found := subject.Instance.(*scopedClient)
+	return found
+}
+
+func (md *markingDecorator) DecorateComponent(subject *Component, container *ComponentContainer) {
+	subject.Instance.(*scopedClient).Decorated = true
+}
+
+func (md *markingDecorator) DecoratesScopedInstances() bool {
+	return true
+}
+
+// registeringDecorator behaves like a framework decorator that records components in a shared registry
+type registeringDecorator struct {
+	registered []string
+}
+
+func (rd *registeringDecorator) OfInterest(subject *Component) bool {
+	_, found := subject.Instance.(*scopedClient)
+	return found
+}
+
+func (rd *registeringDecorator) DecorateComponent(subject *Component, container *ComponentContainer) {
+	rd.registered = append(rd.registered, subject.Name)
+}
+
+// loggingDecorator behaves like the application logging facility's decorator, creating a Logger for each instance
+type loggingDecorator struct {
+	manager *logging.ComponentLoggerManager
+}
+
+func (ld *loggingDecorator) OfInterest(subject *Component) bool {
+	_, found := subject.Instance.(*scopedClient)
+	return found
+}
+
+func (ld *loggingDecorator) DecorateComponent(subject *Component, container *ComponentContainer) {
+	subject.Instance.(*scopedClient).Log = ld.manager.CreateLogger(subject.Name)
+}
+
+func (ld *loggingDecorator) DecoratesScopedInstances() bool {
+	return true
+}
+
+func scopedTestContainer(scope Scope) *ComponentContainer {
+	cc := newTestContainer()
+	cc.configAccessor.JSONData = map[string]interface{}{"client": map[string]interface{}{"label": "configured"}}
+
+	factory := func() interface{} { return new(scopedClient) }
+
+	cp := CreateScopedProtoComponent(factory, "client", scope)
+	cp.AddConfigPromise("Label", "client.label")
+	cp.AddDependency("Shared", "shared")
+	cc.AddProto(cp)
+
+	cc.WrapAndAddProto("shared", new(singletonDep))
+	cc.WrapAndAddProto("decorator", new(markingDecorator))
+
+	return cc
+}
+
+func TestScopeLabels(t *testing.T) {
+
+	for _, s := range []Scope{SingletonScope, PrototypeScope, RequestScope} {
+
+		p, err := ScopeFromLabel(s.Label())
+
+		test.ExpectNil(t, err)
+		test.ExpectInt(t, int(p), int(s))
+	}
+
+	if _, err := ScopeFromLabel("session"); err == nil {
+		t.Errorf("Expected error for unsupported scope")
+	}
+}
+
+func TestPrototypeInjectedAsNewInstances(t *testing.T) {
+
+	cc := scopedTestContainer(PrototypeScope)
+
+	up := CreateProtoComponent(new(clientUser), "userA")
+	up.AddDependency("Client", "client")
+	cc.AddProto(up)
+
+	up = CreateProtoComponent(new(clientUser), "userB")
+	up.AddDependency("Client", "client")
+	up.AddDependency("Provider", "client")
+	cc.AddProto(up)
+
+	test.ExpectNil(t, cc.Populate())
+	cc.configAccessor.Flush()
+
+	a := cc.ComponentByName("userA").Instance.(*clientUser).Client
+	b := cc.ComponentByName("userB").Instance.(*clientUser).Client
+
+	if a == nil || b == nil || a == b {
+		t.Fatalf("Expected distinct prototype instances")
+	}
+
+	if cc.ComponentByName("client") != nil {
+		t.Errorf("Prototype components should not be stored in the container")
+	}
+
+	shared := cc.ComponentByName("shared").Instance
+
+	for _, c := range []*scopedClient{a, b} {
+		test.ExpectString(t, c.Label, "configured")
+		test.ExpectBool(t, c.Decorated, true)
+
+		if c.Shared != shared {
+			t.Errorf("Expected singleton dependency to be injected")
+		}
+	}
+
+	p := cc.ComponentByName("userB").Instance.(*clientUser).Provider
+
+	i, err := p.Instance(context.Background())
+	test.ExpectNil(t, err)
+
+	c := i.(*scopedClient)
+
+	if c == a || c == b {
+		t.Errorf("Expected a new instance from provider")
+	}
+
+	test.ExpectString(t, c.Label, "configured")
+	test.ExpectBool(t, c.Decorated, true)
+}
+
+func TestOnlyScopedDecoratorsAppliedToNewInstances(t *testing.T) {
+
+	cc := scopedTestContainer(PrototypeScope)
+
+	rd := new(registeringDecorator)
+	cc.WrapAndAddProto("registering", rd)
+
+	up := CreateProtoComponent(new(clientUser), "user")
+	up.AddDependency("Client", "client")
+	up.AddDependency("Provider", "client")
+	cc.AddProto(up)
+
+	test.ExpectNil(t, cc.Populate())
+
+	c, err := cc.ComponentByName("user").Instance.(*clientUser).Provider.Instance(context.Background())
+	test.ExpectNil(t, err)
+
+	test.ExpectBool(t, c.(*scopedClient).Decorated, true)
+	test.ExpectInt(t, len(rd.registered), 0)
+}
+
+func TestRequestScopedInstances(t *testing.T) {
+
+	cc := scopedTestContainer(RequestScope)
+
+	up := CreateProtoComponent(new(clientUser), "user")
+	up.AddDependency("Provider", "client")
+	cc.AddProto(up)
+
+	test.ExpectNil(t, cc.Populate())
+
+	p := cc.ComponentByName("user").Instance.(*clientUser).Provider
+
+	if _, err := p.Instance(context.Background()); err == nil {
+		t.Errorf("Expected an error when no request scope is available")
+	}
+
+	ctxA := WithRequestScope(context.Background())
+	ctxB := WithRequestScope(context.Background())
+
+	a1, err := p.Instance(ctxA)
+	test.ExpectNil(t, err)
+
+	a2, _ := p.Instance(ctxA)
+	b, _ := p.Instance(ctxB)
+
+	if a1 != a2 {
+		t.Errorf("Expected the same instance for the same request")
+	}
+
+	if a1 == b {
+		t.Errorf("Expected different instances for different requests")
+	}
+
+	test.ExpectString(t, b.(*scopedClient).Label, "configured")
+}
+
+func TestParallelScopedInstances(t *testing.T) {
+
+	cc := scopedTestContainer(PrototypeScope)
+
+	lm := logging.CreateComponentLoggerManager(logging.Fatal, make(map[string]interface{}), []logging.LogWriter{}, logging.NewFrameworkLogMessageFormatter(), false)
+	cc.WrapAndAddProto("loggingDecorator", &loggingDecorator{manager: lm})
+
+	up := CreateProtoComponent(new(clientUser), "user")
+	up.AddDependency("Provider", "client")
+	cc.AddProto(up)
+
+	test.ExpectNil(t, cc.Populate())
+
+	p := cc.ComponentByName("user").Instance.(*clientUser).Provider
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			c, err := p.Instance(WithRequestScope(context.Background()))
+
+			if err != nil {
+				t.Errorf("Unexpected error creating an instance: %s", err.Error())
+				return
+			}
+
+			if c.(*scopedClient).Log == nil {
+				t.Errorf("Expected instance to be decorated with a Logger")
+			}
+
+			lm.CurrentLevels()
+		}()
+	}
+
+	wg.Wait()
+}
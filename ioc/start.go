@@ -0,0 +1,165 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package ioc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StartTimeoutError is returned when a component's StartComponent method did not return within System.StartTimeoutMS.
+// StartComponent may still be running, so it is not safe to stop the other components in the container.
+type StartTimeoutError struct {
+	// The name of the component that did not start in time
+	Component string
+
+	// The time the component was allowed to start in
+	Timeout time.Duration
+}
+
+func (ste StartTimeoutError) Error() string {
+	return fmt.Sprintf("Unable to start %s: did not start within %s (System.StartTimeoutMS)", ste.Component, ste.Timeout)
+}
+
+// startResult records the outcome of an attempt to start a single component
+type startResult struct {
+	component *Component
+	err       error
+	elapsed   time.Duration
+}
+
+// startComponents invokes StartComponent on each of the supplied components. A component is not started until all
+// of the components it depends on have started. If System.ParallelStart is set, components that have no dependency
+// relationship with each other are started concurrently; otherwise components are started one at a time in dependency order.
+//
+// If System.StartTimeoutMS is greater than zero, any component that takes longer than that to start will cause startup to
+// fail with a StartTimeoutError.
+func (lm *LifecycleManager) startComponents(comps []*Component) error {
+
+	ordered, err := lm.inDependencyOrder(comps)
+
+	if err != nil {
+		return err
+	}
+
+	deps := lm.container.dependencyGraph.reduce(ordered)
+
+	waitingOn := make(map[string]int)
+	dependents := make(map[string][]string)
+
+	for _, c := range ordered {
+		for _, dep := range deps[c.Name] {
+			waitingOn[c.Name]++
+			dependents[dep] = append(dependents[dep], c.Name)
+		}
+	}
+
+	maxInFlight := 1
+
+	if lm.system.ParallelStart {
+		maxInFlight = len(ordered)
+	}
+
+	timeout := lm.system.StartTimeoutMS * time.Millisecond
+
+	results := make(chan *startResult, len(ordered))
+	pending := ordered
+	inFlight := 0
+
+	for len(pending) > 0 || inFlight > 0 {
+
+		remaining := make([]*Component, 0, len(pending))
+
+		for _, c := range pending {
+
+			if inFlight < maxInFlight && waitingOn[c.Name] == 0 {
+				lm.FrameworkLogger.LogTracef("Starting %s", c.Name)
+
+				inFlight++
+
+				if maxInFlight == 1 {
+					results <- lm.startComponent(c, timeout)
+				} else {
+					go func(c *Component) {
+						results <- lm.startComponent(c, timeout)
+					}(c)
+				}
+			} else {
+				remaining = append(remaining, c)
+			}
+		}
+
+		pending = remaining
+
+		r := <-results
+		inFlight--
+
+		name := r.component.Name
+
+		if ste, timedOut := r.err.(StartTimeoutError); timedOut {
+			lm.FrameworkLogger.LogErrorf("%s did not start within %s and may still be starting", name, ste.Timeout)
+			return ste
+		}
+
+		if r.err != nil {
+			lm.FrameworkLogger.LogErrorf("%s failed to start after %s: %s", name, r.elapsed, r.err.Error())
+
+			message := fmt.Sprintf("Unable to start %s: %s", name, r.err.Error())
+			return errors.New(message)
+		}
+
+		lm.FrameworkLogger.LogDebugf("Started %s (%s)", name, r.elapsed)
+
+		for _, d := range dependents[name] {
+			waitingOn[d]--
+		}
+	}
+
+	return nil
+}
+
+// startComponent calls StartComponent on the supplied component and returns the outcome. If timeout is zero, StartComponent
+// is called on the current goroutine. Otherwise it is called on a new goroutine and a StartTimeoutError is returned if it
+// has not returned within that duration (the goroutine is left running).
+func (lm *LifecycleManager) startComponent(c *Component, timeout time.Duration) *startResult {
+
+	began := time.Now()
+
+	if timeout <= 0 {
+		return &startResult{component: c, err: lm.invokeStart(c), elapsed: time.Since(began)}
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- lm.invokeStart(c)
+	}()
+
+	var err error
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case err = <-done:
+	case <-t.C:
+		err = StartTimeoutError{Component: c.Name, Timeout: timeout}
+	}
+
+	return &startResult{component: c, err: err, elapsed: time.Since(began)}
+}
+
+// invokeStart calls StartComponent on the supplied component, converting a panic into an error
+func (lm *LifecycleManager) invokeStart(c *Component) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			lm.FrameworkLogger.LogErrorfWithTrace("Panic recovered while starting %s %s", c.Name, r)
+			err = fmt.Errorf("panic during start: %v", r)
+		}
+	}()
+
+	return c.Instance.(Startable).StartComponent()
+}
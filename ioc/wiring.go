@@ -0,0 +1,344 @@
+// Copyright 2016-2020 Granitic. All rights reserved.
+// Use of this source code is governed by an Apache 2.0 license that can be found in the LICENSE file at the root of this project.
+
+package ioc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Formats in which a Wiring can be exported
+const (
+	WiringFormatJSON = "json"
+	WiringFormatDOT  = "dot"
+)
+
+/*
+Wiring describes how the components in the container were connected together when the container was populated. If
+ComponentContainer.RecordWiring was called before Populate, it is available via ComponentContainer.Wiring once Populate
+has completed and can be exported as JSON or as a Graphviz DOT digraph.
+*/
+type Wiring struct {
+	// All of the components that were populated (including decorators and non-singleton components), sorted by name.
+	Components []*ComponentWiring
+
+	// The names of components that were excluded by their conditions and the reason they were excluded.
+	Excluded map[string]string
+}
+
+// ComponentWiring describes the type, dependencies and configuration of a single component.
+type ComponentWiring struct {
+	// The name of the component.
+	Name string
+
+	// The Go type of the component's instance.
+	Type string
+
+	// The label of the component's Scope.
+	Scope string
+
+	// True if the component is a ComponentDecorator.
+	Decorator bool
+
+	// A map of field names to the names of the components injected into them.
+	Dependencies map[string]string
+
+	// A map of field names to the configuration paths used to populate them.
+	ConfigPromises map[string]string
+
+	// The names of the decorators that decorated the component (singleton components only).
+	DecoratedBy []string
+
+	// The fields (including unexported fields) whose values were changed by decorators (singleton components only).
+	DecoratedFields []string
+}
+
+// RecordWiring causes Populate to record how the container's components were connected together. Recording requires
+// the fields of every component to be examined before and after decoration, so is only performed when a graph of the
+// components has been requested. Has no effect if called after Populate.
+func (cc *ComponentContainer) RecordWiring() {
+	cc.recordWiring = true
+}
+
+// Wiring returns a description of how the container's components were connected together or nil if Populate has not
+// yet been called or RecordWiring was not called before Populate.
+func (cc *ComponentContainer) Wiring() *Wiring {
+	return cc.wiring
+}
+
+// buildWiring captures the dependencies and config promises of every proto-component, along with the changes decorators
+// made to singleton components (by comparing the fields of each component before and after decoration).
+func (cc *ComponentContainer) buildWiring(before map[string]fieldSnapshot, decoratedBy map[string][]string) *Wiring {
+
+	w := new(Wiring)
+	w.Excluded = make(map[string]string)
+
+	for name, reason := range cc.excluded {
+		w.Excluded[name] = reason
+	}
+
+	for name, proto := range cc.protoComponents {
+
+		instance := proto.Component.Instance
+
+		cw := new(ComponentWiring)
+		cw.Name = name
+		cw.Type = reflect.TypeOf(instance).Elem().String()
+		cw.Scope = proto.Scope.Label()
+		cw.Dependencies = cc.mergeDependencies(name, proto.Dependencies)
+		cw.ConfigPromises = make(map[string]string)
+
+		for field, path := range proto.ConfigPromises {
+			cw.ConfigPromises[field] = path
+		}
+
+		if _, decorator := instance.(ComponentDecorator); decorator {
+			cw.Decorator = true
+		}
+
+		if snap := before[name]; snap != nil {
+			cw.DecoratedBy = decoratedBy[name]
+			cw.DecoratedFields = snap.changed(snapshotFields(instance))
+
+			sort.Strings(cw.DecoratedBy)
+		}
+
+		w.Components = append(w.Components, cw)
+	}
+
+	sort.Slice(w.Components, func(i, j int) bool { return w.Components[i].Name < w.Components[j].Name })
+
+	return w
+}
+
+// fieldSnapshot records a fingerprint of the value of each field of a component
+type fieldSnapshot map[string]string
+
+// changed returns the sorted names of fields whose fingerprints differ in the supplied snapshot.
+func (fs fieldSnapshot) changed(after fieldSnapshot) []string {
+
+	changed := make([]string, 0)
+
+	for name, fp := range after {
+		if fs[name] != fp {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// snapshotFields fingerprints every field of the supplied pointer to a struct.
+func snapshotFields(instance interface{}) fieldSnapshot {
+
+	v := reflect.ValueOf(instance).Elem()
+	t := v.Type()
+
+	fs := make(fieldSnapshot)
+
+	for i := 0; i < t.NumField(); i++ {
+		fs[t.Field(i).Name] = fingerprint(v.Field(i))
+	}
+
+	return fs
+}
+
+// fingerprint summarises a value so that changes to it can be detected. Reference types are summarised by their address,
+// so a change to the contents of a referenced value is not detected. Only accessors that are permitted on unexported fields
+// are used.
+func fingerprint(v reflect.Value) string {
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return fmt.Sprintf("%x", v.Pointer())
+	case reflect.Slice:
+		return fmt.Sprintf("%x/%d", v.Pointer(), v.Len())
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+
+		return v.Elem().Type().String() + ":" + fingerprint(v.Elem())
+	case reflect.Bool:
+		return fmt.Sprint(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fmt.Sprint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Float())
+	case reflect.String:
+		return v.String()
+	case reflect.Struct:
+		parts := make([]string, v.NumField())
+
+		for i := range parts {
+			parts[i] = fingerprint(v.Field(i))
+		}
+
+		return "{" + strings.Join(parts, ",") + "}"
+	case reflect.Array:
+		parts := make([]string, v.Len())
+
+		for i := range parts {
+			parts[i] = fingerprint(v.Index(i))
+		}
+
+		return "[" + strings.Join(parts, ",") + "]"
+	}
+
+	return ""
+}
+
+// WiringFormatFromPath returns WiringFormatDOT if the supplied file path has a .dot or .gv extension, otherwise WiringFormatJSON.
+func WiringFormatFromPath(path string) string {
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return WiringFormatDOT
+	}
+
+	return WiringFormatJSON
+}
+
+// WriteFile writes the Wiring to the supplied file in the format implied by the file's extension (see WiringFormatFromPath).
+func (w *Wiring) WriteFile(path string) error {
+
+	f, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return w.Write(f, WiringFormatFromPath(path))
+}
+
+// Write exports the Wiring in the requested format (WiringFormatJSON or WiringFormatDOT).
+func (w *Wiring) Write(out io.Writer, format string) error {
+
+	switch format {
+	case WiringFormatJSON:
+		return w.WriteJSON(out)
+	case WiringFormatDOT:
+		return w.WriteDOT(out)
+	}
+
+	return fmt.Errorf("%s is not a supported format. Must be %s or %s", format, WiringFormatJSON, WiringFormatDOT)
+}
+
+// WriteJSON exports the Wiring as indented JSON.
+func (w *Wiring) WriteJSON(out io.Writer) error {
+
+	content, err := json.MarshalIndent(w, "", " ")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(append(content, '\n'))
+
+	return err
+}
+
+/*
+WriteDOT exports the Wiring as a Graphviz digraph. Each component is a node labelled with its name, type, config promises and
+decorated fields. Solid edges point from a component to the components injected into it and are labelled with the
+field name. Dashed edges point from decorators to the components they decorated. Components excluded by their conditions
+are shown as dotted nodes.
+*/
+func (w *Wiring) WriteDOT(out io.Writer) error {
+
+	b := bufio.NewWriter(out)
+
+	b.WriteString("digraph components {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, c := range w.Components {
+
+		lines := []string{c.Name, c.Type}
+
+		if c.Scope != SingletonScopeLabel {
+			lines = append(lines, "scope: "+c.Scope)
+		}
+
+		for _, f := range sortedKeys(c.ConfigPromises) {
+			lines = append(lines, fmt.Sprintf("%s <- conf:%s", f, c.ConfigPromises[f]))
+		}
+
+		for _, f := range c.DecoratedFields {
+			lines = append(lines, f+" (decorated)")
+		}
+
+		attrs := ""
+
+		if c.Decorator {
+			attrs = ", style=dashed"
+		}
+
+		fmt.Fprintf(b, "  %s [label=%s%s];\n", dotQuote(c.Name), dotLabel(lines), attrs)
+	}
+
+	for _, name := range sortedKeys(w.Excluded) {
+		fmt.Fprintf(b, "  %s [label=%s, style=dotted];\n", dotQuote(name), dotLabel([]string{name, "excluded: " + w.Excluded[name]}))
+	}
+
+	for _, c := range w.Components {
+
+		for _, f := range sortedKeys(c.Dependencies) {
+			fmt.Fprintf(b, "  %s -> %s [label=%s];\n", dotQuote(c.Name), dotQuote(c.Dependencies[f]), dotQuote(f))
+		}
+
+		for _, d := range c.DecoratedBy {
+			fmt.Fprintf(b, "  %s -> %s [style=dashed];\n", dotQuote(d), dotQuote(c.Name))
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.Flush()
+}
+
+func dotLabel(lines []string) string {
+
+	escaped := make([]string, len(lines))
+
+	for i, l := range lines {
+		escaped[i] = dotEscape(l)
+	}
+
+	return "\"" + strings.Join(escaped, "\\n") + "\""
+}
+
+func dotQuote(s string) string {
+	return "\"" + dotEscape(s) + "\""
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s)
+}
+
+func sortedKeys(m map[string]string) []string {
+
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
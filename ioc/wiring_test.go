@@ -0,0 +1,140 @@
+package ioc
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/graniticio/granitic/v2/test"
+)
+
+type wiredComp struct {
+	Client    *scopedClient
+	Label     string
+	Decorated bool
+}
+
+type wiredDecorator struct{}
+
+func (wd *wiredDecorator) OfInterest(subject *Component) bool {
+	_, found := subject.Instance.(*wiredComp)
+	return found
+}
+
+func (wd *wiredDecorator) DecorateComponent(subject *Component, container *ComponentContainer) {
+	subject.Instance.(*wiredComp).Decorated = true
+}
+
+func wiredContainer() *ComponentContainer {
+	cc := newTestContainer()
+	cc.RecordWiring()
+	cc.configAccessor.JSONData = map[string]interface{}{"wired": map[string]interface{}{"label": "set"}}
+
+	wp := CreateProtoComponent(new(wiredComp), "wired")
+	wp.AddDependency("Client", "client")
+	wp.AddConfigPromise("Label", "wired.label")
+	cc.AddProto(wp)
+
+	cp := CreateProtoComponent(new(scopedClient), "client")
+	cc.AddProto(cp)
+
+	dp := CreateProtoComponent(new(wiredDecorator), "decorator")
+	cc.AddProto(dp)
+
+	ep := CreateProtoComponent(new(scopedClient), "excluded")
+	ep.SetCondition("wired.enabled", false)
+	cc.AddProto(ep)
+
+	return cc
+}
+
+func TestWiringCapturedAfterPopulate(t *testing.T) {
+
+	cc := wiredContainer()
+
+	if cc.Wiring() != nil {
+		t.Fatalf("Expected no wiring before Populate")
+	}
+
+	test.ExpectNil(t, cc.Populate())
+
+	w := cc.Wiring()
+
+	if w == nil || len(w.Components) != 3 {
+		t.Fatalf("Expected wiring for three components")
+	}
+
+	test.ExpectString(t, w.Components[0].Name, "client")
+	test.ExpectString(t, w.Components[1].Name, "decorator")
+	test.ExpectBool(t, w.Components[1].Decorator, true)
+
+	wired := w.Components[2]
+
+	test.ExpectString(t, wired.Name, "wired")
+	test.ExpectString(t, wired.Type, "ioc.wiredComp")
+	test.ExpectString(t, wired.Scope, SingletonScopeLabel)
+	test.ExpectString(t, wired.Dependencies["Client"], "client")
+	test.ExpectString(t, wired.ConfigPromises["Label"], "wired.label")
+	test.ExpectString(t, strings.Join(wired.DecoratedBy, ","), "decorator")
+	test.ExpectString(t, strings.Join(wired.DecoratedFields, ","), "Decorated")
+
+	if w.Excluded["excluded"] == "" {
+		t.Errorf("Expected excluded component to be recorded")
+	}
+}
+
+func TestWiringNotRecordedUnlessRequested(t *testing.T) {
+
+	cc := wiredContainer()
+	cc.recordWiring = false
+
+	test.ExpectNil(t, cc.Populate())
+
+	if cc.Wiring() != nil {
+		t.Errorf("Expected no wiring to be recorded")
+	}
+
+	test.ExpectBool(t, cc.ComponentByName("wired").Instance.(*wiredComp).Decorated, true)
+}
+
+func TestWiringExport(t *testing.T) {
+
+	cc := wiredContainer()
+	test.ExpectNil(t, cc.Populate())
+
+	w := cc.Wiring()
+
+	var b bytes.Buffer
+
+	test.ExpectNil(t, w.Write(&b, WiringFormatJSON))
+
+	parsed := new(Wiring)
+	test.ExpectNil(t, json.Unmarshal(b.Bytes(), parsed))
+	test.ExpectInt(t, len(parsed.Components), 3)
+
+	b.Reset()
+	test.ExpectNil(t, w.Write(&b, WiringFormatDOT))
+
+	dot := b.String()
+
+	for _, expected := range []string{
+		"digraph components {",
+		`"wired" -> "client" [label="Client"];`,
+		`"decorator" -> "wired" [style=dashed];`,
+		`Label <- conf:wired.label`,
+		`Decorated (decorated)`,
+		`"excluded" [label="excluded\nexcluded: `,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("Expected DOT output to contain %s", expected)
+		}
+	}
+
+	if w.Write(&b, "svg") == nil {
+		t.Errorf("Expected error for unsupported format")
+	}
+
+	test.ExpectString(t, WiringFormatFromPath("graph.GV"), WiringFormatDOT)
+	test.ExpectString(t, WiringFormatFromPath("graph.json"), WiringFormatJSON)
+}
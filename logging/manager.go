@@ -6,6 +6,7 @@ package logging
 import (
 	"context"
 	"github.com/graniticio/granitic/v2/instance"
+	"sync"
 	"time"
 )
 
@@ -39,6 +40,7 @@ func CreateComponentLoggerManager(globalThreshold LogLevel, initalComponentLogLe
 // ComponentLoggerManager creates new Logger instances for a particular scope (e.g. framework or application).
 type ComponentLoggerManager struct {
 	created         map[string]*GraniticLogger
+	createdMutex    sync.RWMutex
 	deferLogging    bool
 	deferBuffer     chan deferredLogEntry
 	deferred        []deferredLogEntry
@@ -55,6 +57,9 @@ type ComponentLoggerManager struct {
 // LoggerByName finds a previously created Logger by the name it was given when it was created. Returns nil if no Logger
 // by that name exists.
 func (clm *ComponentLoggerManager) LoggerByName(name string) *GraniticLogger {
+	clm.createdMutex.RLock()
+	defer clm.createdMutex.RUnlock()
+
 	return clm.created[name]
 }
 
@@ -63,6 +68,9 @@ func (clm *ComponentLoggerManager) CurrentLevels() []*ComponentLevel {
 
 	cls := make([]*ComponentLevel, 0)
 
+	clm.createdMutex.RLock()
+	defer clm.createdMutex.RUnlock()
+
 	for n, c := range clm.created {
 
 		lev := new(ComponentLevel)
@@ -125,12 +133,16 @@ func (clm *ComponentLoggerManager) UpdateWritersAndFormatter(writers []LogWriter
 		formatter.SetInstanceID(clm.instanceID)
 	}
 
+	clm.createdMutex.RLock()
+
 	for _, v := range clm.created {
 
 		v.UpdateWritersAndFormatter(writers, formatter)
 		v.deferring = false
 	}
 
+	clm.createdMutex.RUnlock()
+
 	if clm.deferLogging {
 
 		clm.deferLogging = false
@@ -175,6 +187,9 @@ func (clm *ComponentLoggerManager) SetGlobalThreshold(globalThreshold LogLevel)
 // Previously created loggers will be updated
 func (clm *ComponentLoggerManager) SetInitialLogLevels(ll map[string]interface{}) {
 
+	clm.createdMutex.Lock()
+	defer clm.createdMutex.Unlock()
+
 	clm.initialLevels = ll
 
 	if len(clm.created) > 0 {
@@ -199,6 +214,10 @@ func (clm *ComponentLoggerManager) CreateLogger(componentID string) Logger {
 		return clm.nullLogger
 	}
 
+	// Loggers may be created for non-singleton components while requests are being processed
+	clm.createdMutex.Lock()
+	defer clm.createdMutex.Unlock()
+
 	if clm.created[componentID] != nil {
 		return clm.created[componentID]
 	}
@@ -217,7 +236,7 @@ func (clm *ComponentLoggerManager) CreateLogger(componentID string) Logger {
 
 	}
 
-	return clm.CreateLoggerAtLevel(componentID, threshold)
+	return clm.createLoggerAtLevel(componentID, threshold)
 }
 
 // CreateLoggerAtLevel creates a new Logger for the supplied component name with the local log threshold set to the supplied level.
@@ -227,6 +246,15 @@ func (clm *ComponentLoggerManager) CreateLoggerAtLevel(componentID string, thres
 		return clm.nullLogger
 	}
 
+	clm.createdMutex.Lock()
+	defer clm.createdMutex.Unlock()
+
+	return clm.createLoggerAtLevel(componentID, threshold)
+}
+
+// createLoggerAtLevel creates and records a new Logger. Callers must hold a write lock on createdMutex.
+func (clm *ComponentLoggerManager) createLoggerAtLevel(componentID string, threshold LogLevel) *GraniticLogger {
+
 	l := new(GraniticLogger)
 	l.global = clm
 	l.localLogThreshhold = threshold
@@ -27,7 +27,8 @@ A handler is declared in your component definition file like:
 
 Each handler must have the following before it is considered a valid web service endpoint.
 
-1. A regular expression that will be matched against the path component of incoming HTTP requests.
+1. A regular expression (PathPattern) or path template (PathTemplate) that will be matched against the path component
+of incoming HTTP requests.
 
 2. A single HTTP method that it will be responsible for handling. This is generally GET, POST, PUT or DELETE but any
 standard or custom HTTP method can be used.
@@ -35,6 +36,17 @@ standard or custom HTTP method can be used.
 3. A 'logic' component that implements at least WsRequestProcessor (additional WsXXX interfaces can be implemented
 to support advanced behaviour) OR has a method with the signature ProcessPayload(ctx context.Context, request *ws.Request, response *ws.Response, payload *YourStruct)
 
+Path templates
+
+Instead of a PathPattern, a handler can declare a PathTemplate like:
+
+	"PathTemplate": "/artist/{id:int}/album/{albumID}"
+
+Parameters in braces match a single segment of the path and may have a type (string, int, uint or float). The value of
+each parameter is bound to the field on the request body with the same name (ignoring case) unless BindPathParams is set.
+Templates are matched more efficiently than regular expressions and conflicting templates are detected when your
+application starts.
+
 */
 package handler
 
@@ -52,6 +64,7 @@ import (
 	"net/http"
 	"reflect"
 	"regexp"
+	"strings"
 )
 
 const processPayloadFunc = "ProcessPayload"
@@ -136,6 +149,10 @@ type WsHandler struct {
 	// A list of field names on the target object into which path parameters (groups in the request regex) should be bound to.
 	BindPathParams []string
 
+	// A Cross-Origin Resource Sharing (CORS) policy for this endpoint, overriding the policy configured on the HTTP
+	// server. Normally a reference to a component of type httpendpoint.CORSPolicy.
+	CORS *httpendpoint.CORSPolicy
+
 	// Check caller's permissions after request has been parsed (true) or before parsing (false).
 	CheckAccessAfterParse bool
 
@@ -180,6 +197,10 @@ type WsHandler struct {
 	// A regex that will be matched against inbound request paths to check if this handler should be used to service the request.
 	PathPattern string
 
+	// A template (e.g. /artist/{id:int}) that will be matched against inbound request paths to check if this handler
+	// should be used to service the request. Can be set instead of PathPattern.
+	PathTemplate string
+
 	// A component that might want to modify a response after it has been processed by the supplied Logic component.
 	PostProcessor WsPostProcessor
 
@@ -208,6 +229,7 @@ type WsHandler struct {
 	httpMethods       []string
 	componentName     string
 	pathRegex         *regexp.Regexp
+	pathTemplate      *httpendpoint.PathTemplate
 	state             ioc.ComponentState
 	validationEnabled bool
 	validator         WsRequestValidator
@@ -408,15 +430,52 @@ func (wh *WsHandler) processPathParams(req *http.Request, wsReq *ws.Request) {
 		return
 	}
 
-	re := wh.pathRegex
-	params := re.FindStringSubmatch(req.URL.Path)
-	wsReq.PathParams = params[1:]
+	if wh.pathTemplate != nil {
+		wsReq.PathParams, _ = wh.pathTemplate.Match(req.URL.Path)
+	} else {
+		re := wh.pathRegex
+		params := re.FindStringSubmatch(req.URL.Path)
+		wsReq.PathParams = params[1:]
+	}
+
+	if len(wsReq.PathParams) == 0 {
+		return
+	}
 
-	if wh.bindPathParams && len(wsReq.PathParams) > 0 {
+	if wh.bindPathParams {
 		pp := ws.NewParamsForPath(wh.BindPathParams, wsReq.PathParams)
 		wh.ParamBinder.BindPathParameters(wsReq, pp)
+	} else if wh.pathTemplate != nil && wsReq.RequestBody != nil {
+		wh.bindNamedPathParams(wsReq)
+	}
+
+}
+
+// bindNamedPathParams binds the value of each path template parameter to the field on the request body with the same
+// name (ignoring case). Parameters without a matching field are not bound.
+func (wh *WsHandler) bindNamedPathParams(wsReq *ws.Request) {
+
+	t := reflect.TypeOf(wsReq.RequestBody)
+
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	t = t.Elem()
+
+	var fields, values []string
+
+	for i, name := range wh.pathTemplate.ParamNames() {
+
+		if f, found := t.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) }); found {
+			fields = append(fields, f.Name)
+			values = append(values, wsReq.PathParams[i])
+		}
 	}
 
+	if len(fields) > 0 {
+		wh.ParamBinder.BindPathParameters(wsReq, ws.NewParamsForPath(fields, values))
+	}
 }
 
 func (wh *WsHandler) processQueryParams(ctx context.Context, req *http.Request, wsReq *ws.Request) {
@@ -512,6 +571,12 @@ func (wh *WsHandler) RegexPattern() string {
 	return wh.PathPattern
 }
 
+// TemplatePattern returns the template that should be applied to the path of incoming requests to determine whether or
+// not this handler should handle the request. Returns an empty string if the handler uses a PathPattern instead.
+func (wh *WsHandler) TemplatePattern() string {
+	return wh.PathTemplate
+}
+
 // VersionAware returns true if this handler can be considered when a user requests a specific version of functionality.
 func (wh *WsHandler) VersionAware() bool {
 	return wh.VersionAssessor != nil
@@ -529,6 +594,11 @@ func (wh *WsHandler) AutoWireable() bool {
 	return !wh.PreventAutoWiring
 }
 
+// CORSPolicy returns the CORS policy for this handler, or nil if the HTTP server's policy should be used.
+func (wh *WsHandler) CORSPolicy() *httpendpoint.CORSPolicy {
+	return wh.CORS
+}
+
 func (wh *WsHandler) handleFrameworkErrors(ctx context.Context, w *httpendpoint.HTTPResponseWriter, wsReq *ws.Request) {
 
 	var se ws.ServiceErrors
@@ -655,8 +725,12 @@ func (wh *WsHandler) StartComponent() error {
 
 	wh.state = ioc.StartingState
 
-	if wh.PathPattern == "" || wh.HTTPMethod == "" || wh.Logic == nil {
-		return errors.New("handlers must have at least a PathPattern string, HTTPMethod string and Logic component set")
+	if (wh.PathPattern == "" && wh.PathTemplate == "") || wh.HTTPMethod == "" || wh.Logic == nil {
+		return errors.New("handlers must have at least a PathPattern or PathTemplate string, HTTPMethod string and Logic component set")
+	}
+
+	if wh.PathPattern != "" && wh.PathTemplate != "" {
+		return errors.New("handlers must not have both a PathPattern and a PathTemplate set")
 	}
 
 	if wh.AutoValidator != nil && wh.ErrorFinder == nil {
@@ -677,17 +751,31 @@ func (wh *WsHandler) StartComponent() error {
 
 	wh.bindQuery = wh.AutoBindQuery || (wh.FieldQueryParam != nil && len(wh.FieldQueryParam) > 0)
 
-	if !wh.DisablePathParsing {
-
-		wh.bindPathParams = len(wh.BindPathParams) > 0
+	if wh.PathTemplate != "" {
 
-		r, err := regexp.Compile(wh.PathPattern)
+		t, err := httpendpoint.ParsePathTemplate(wh.PathTemplate)
 
 		if err != nil {
 			return err
 		}
 
-		wh.pathRegex = r
+		wh.pathTemplate = t
+	}
+
+	if !wh.DisablePathParsing {
+
+		wh.bindPathParams = len(wh.BindPathParams) > 0
+
+		if wh.pathTemplate == nil {
+
+			r, err := regexp.Compile(wh.PathPattern)
+
+			if err != nil {
+				return err
+			}
+
+			wh.pathRegex = r
+		}
 	}
 
 	if wh.DeferAutoErrors && wh.validator == nil {
@@ -8,9 +8,11 @@ import (
 	"bytes"
 	"context"
 	"github.com/graniticio/granitic/v2/httpendpoint"
+	"github.com/graniticio/granitic/v2/logging"
 	"github.com/graniticio/granitic/v2/test"
 	"github.com/graniticio/granitic/v2/ws"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -286,3 +288,64 @@ type mockLogicInvalid struct {
 func (ml *mockLogicInvalid) ProcessPayload(ctx context.Context, request *ws.Request, response *ws.Response, target mockTarget) {
 
 }
+
+type albumQuery struct {
+	ArtistID  int
+	AlbumName string
+}
+
+type albumLogic struct {
+	query *albumQuery
+	req   *ws.Request
+}
+
+func (l *albumLogic) ProcessPayload(ctx context.Context, req *ws.Request, res *ws.Response, q *albumQuery) {
+	l.query = q
+	l.req = req
+}
+
+func TestPathTemplateBinding(t *testing.T) {
+
+	fl := new(logging.ConsoleErrorLogger)
+
+	l := new(albumLogic)
+
+	h := new(WsHandler)
+	h.PathTemplate = "/artist/{artistId:int}/album/{albumName}/{format}"
+	h.HTTPMethod = "GET"
+	h.Logic = l
+	h.ResponseWriter = new(NilResponseWriter)
+	h.ParamBinder = &ws.ParamBinder{FrameworkLogger: fl, FrameworkErrors: &ws.FrameworkErrorGenerator{FrameworkLogger: fl}}
+	h.Log = fl
+
+	test.ExpectNil(t, h.StartComponent())
+	test.ExpectString(t, h.TemplatePattern(), "/artist/{artistId:int}/album/{albumName}/{format}")
+	test.ExpectString(t, h.RegexPattern(), "")
+
+	req := httptest.NewRequest(http.MethodGet, "/artist/12/album/Sticky%20Fingers/cd/", nil)
+
+	h.ServeHTTP(context.Background(), httpendpoint.NewHTTPResponseWriter(httptest.NewRecorder()), req)
+
+	test.ExpectInt(t, l.query.ArtistID, 12)
+	test.ExpectString(t, l.query.AlbumName, "Sticky Fingers")
+	test.ExpectInt(t, len(l.req.PathParams), 3)
+	test.ExpectString(t, l.req.PathParams[2], "cd")
+}
+
+func TestPathTemplateValidation(t *testing.T) {
+
+	h := new(WsHandler)
+	h.PathTemplate = "/artist/{id:date}"
+	h.HTTPMethod = "GET"
+	h.Logic = new(ProcessOnlyLogic)
+
+	test.ExpectNotNil(t, h.StartComponent())
+
+	h = new(WsHandler)
+	h.PathTemplate = "/artist/{id}"
+	h.PathPattern = "^/artist"
+	h.HTTPMethod = "GET"
+	h.Logic = new(ProcessOnlyLogic)
+
+	test.ExpectNotNil(t, h.StartComponent())
+}
@@ -5,6 +5,7 @@ package ws
 
 import (
 	"context"
+	"crypto/x509"
 	"github.com/graniticio/granitic/v2/iam"
 	"net/http"
 )
@@ -21,3 +22,15 @@ type AccessChecker interface {
 	// Allowed returns true if the caller is allowed to have this request processed, false otherwise.
 	Allowed(ctx context.Context, r *Request) bool
 }
+
+// VerifiedClientCertificate returns the certificate presented by the caller if it was verified during the TLS handshake
+// (see the TLS settings of the HTTPServer facility), or nil if the caller did not present a verified certificate.
+// Identifier implementations can pass the certificate's Subject to iam.NewCertificateIdentity.
+func VerifiedClientCertificate(req *http.Request) *x509.Certificate {
+
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return req.TLS.VerifiedChains[0][0]
+}